@@ -0,0 +1,48 @@
+// Command dgogen generates Go type declarations from a dgo type file.
+//
+// Usage:
+//
+//	dgogen [-package name] [-register] [-o output] file.dgo
+//
+// The generated source is written to stdout unless an output file is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/lyraproj/dgo/gogen"
+)
+
+func main() {
+	pkg := flag.String(`package`, `main`, `name of the package of the generated source`)
+	register := flag.Bool(`register`, false, `generate dgo named types for the generated structs`)
+	out := flag.String(`o`, ``, `name of the output file`)
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, `usage: dgogen [-package name] [-register] [-o output] file.dgo`)
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *out, gogen.Options{Package: *pkg, Register: *register}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string, options gogen.Options) error {
+	content, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	src, err := gogen.Generate(in, string(content), options)
+	if err != nil {
+		return err
+	}
+	if out == `` {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
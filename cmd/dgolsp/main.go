@@ -0,0 +1,21 @@
+// Command dgolsp is a language server for dgo type files. It communicates using the Language Server Protocol over
+// stdin and stdout.
+//
+// Usage:
+//
+//	dgolsp
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lyraproj/dgo/lsp"
+)
+
+func main() {
+	if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
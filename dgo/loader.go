@@ -20,6 +20,17 @@ type (
 	// relative to the loader that the Finder is configured for.
 	NsCreator func(l Loader, name string) Loader
 
+	// Lister is called when the contents of a loader are enumerated and entries that haven't been loaded yet
+	// should be discovered. It returns the names of the entries that the finder of the given loader can find and
+	// the names of the namespaces that its NsCreator can create. The names are relative to the given loader.
+	Lister func(l Loader) (entries, namespaces []string)
+
+	// Importer is called by the parser when a type file imports the namespace with the given path. The path is a
+	// multi part name such as "common/net". The importer must return the Loader that represents the namespace, or
+	// nil when no such namespace exists. The names in the returned namespace are then available to the type file
+	// as qualified references.
+	Importer func(path string) Loader
+
 	// A Loader loads named values on demand. Loaders for nested namespaces can be obtained using the Namespace
 	// method.
 	//
@@ -65,4 +76,28 @@ type (
 		// root namespace.
 		ParentNamespace() Loader
 	}
+
+	// An Invalidator is a Loader that can be told to forget the entries and namespaces that it has cached so that
+	// they are found again when they are next loaded. Functions that depend on loaded entries can register to be
+	// notified when an entry or a namespace is invalidated.
+	//
+	// Implementors of Invalidator must ensure that all methods are safe to use from concurrent go routines.
+	Invalidator interface {
+		Loader
+
+		// Invalidate removes the entry with the given name from the cache. Other entries that were found
+		// together with the entry are also removed. The name may be a multi part name. The method returns
+		// true if an entry was removed.
+		Invalidate(name string) bool
+
+		// InvalidateNamespace removes the namespace with the given name, and thereby all its entries and nested
+		// namespaces, from the cache. The name may be a multi part name. The method returns true if a namespace
+		// was removed.
+		InvalidateNamespace(name string) bool
+
+		// OnChange registers a function that is called with the absolute name of each entry or namespace that
+		// is removed from the cache of this loader or from the cache of one of its nested namespaces. The
+		// function is called after the removal, and never while a lock is held by the loader.
+		OnChange(func(absoluteName string))
+	}
 )
@@ -16,6 +16,14 @@ type (
 		MapEntry
 
 		Required() bool
+
+		// Default returns the value that ApplyDefaults will use when the key of an optional entry is missing,
+		// or nil when the entry has no default value.
+		Default() Value
+
+		// Annotations returns the annotations of the entry, or nil when the entry has no annotations. The
+		// annotations are not considered when comparing entries or their struct map types.
+		Annotations() Map
 	}
 
 	// EntryActor performs some task on behalf of a caller
@@ -157,6 +165,13 @@ type (
 		// have additional entries.
 		Additional() bool
 
+		// ApplyDefaults returns a frozen copy of the given map where the default value of each missing optional
+		// entry has been added. Defaults are applied recursively to values that are described by struct map types,
+		// and to the elements of arrays and the values of maps that contain such values. Values described by a
+		// union of types get the defaults of the first type that they are an instance of, and sensitive values get
+		// the defaults of the type of their wrapped value.
+		ApplyDefaults(value Map) Map
+
 		// Each iterates over each entry of the StructMapType
 		Each(actor func(StructMapEntry))
 
@@ -1,6 +1,7 @@
 package dgo
 
 import (
+	"io"
 	"reflect"
 	"regexp"
 	"time"
@@ -152,6 +153,16 @@ type (
 
 		// Replace replaces aliases with their concrete value.
 		Replace(Value) Value
+
+		// Annotate associates the given annotations with the alias that has the given name. The alias must
+		// have been added. The annotations are merged with annotations that have been added earlier for the
+		// same alias.
+		Annotate(n String, annotations Map)
+
+		// AnnotateType associates the given annotations with the given type. It is used for annotations of types
+		// that are neither declared as an alias nor the value of a map entry. Annotations are merged with
+		// annotations that have been added earlier for an equal type.
+		AnnotateType(t Type, annotations Map)
 	}
 
 	// An AliasMap maps names to types and vice versa.
@@ -166,6 +177,27 @@ type (
 
 		// GetType returns the type with the given name or nil if the type isn't found
 		GetType(n String) Type
+
+		// Annotations returns the annotations of the alias with the given name or nil if the alias has no
+		// annotations. Annotations belong to the alias and not to its type, so aliases for equal types don't
+		// share annotations.
+		Annotations(n String) Map
+
+		// TypeAnnotations returns the annotations of the given anonymous type or nil if the type has no
+		// annotations. The annotations are keyed by the type, so all equal types in the map share them.
+		TypeAnnotations(t Type) Map
+	}
+
+	// A TypeFormatter produces the string form of types using a specific syntax. The string must be readable
+	// by the parser for that syntax.
+	TypeFormatter interface {
+		// FormatType writes the given type to the given writer. Types that have a name in the given alias map
+		// are written using that name. The width is the preferred maximum line width. A width of zero or less means
+		// that the type is written on one line. A formatter may ignore the width if the syntax has no way of
+		// breaking a type into several lines.
+		//
+		// The method panics when the type cannot be expressed using the syntax of the formatter.
+		FormatType(w io.Writer, t Type, aliasMap AliasMap, width int)
 	}
 
 	// GenericType is implemented by types that represent themselves stripped from
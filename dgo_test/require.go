@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/generator"
 	"github.com/lyraproj/dgo/internal"
 )
 
@@ -202,3 +203,33 @@ func Panic(t *testing.T, f func(), v string) {
 	}
 	t.Errorf(`recovered "%s" does not match "%s"`, err.Error(), v)
 }
+
+// DefaultSeed is the seed that ForAll uses to generate instances
+const DefaultSeed = 1
+
+// ForAll will fail unless the property holds for 100 random instances of typ. The instances are generated using
+// DefaultSeed so that a test always checks the same instances. A failing instance is shrunk and reported together
+// with the seed. Use ForAllSeeded to check instances generated using other seeds.
+func ForAll(t *testing.T, typ dgo.Type, property func(v dgo.Value) bool) {
+	t.Helper()
+	ForAllSeeded(t, DefaultSeed, 100, typ, property)
+}
+
+// ForAllSeeded will fail unless the property holds for count random instances of typ generated using the given
+// seed. A failing instance is shrunk before it is reported.
+func ForAllSeeded(t *testing.T, seed int64, count int, typ dgo.Type, property func(v dgo.Value) bool) {
+	t.Helper()
+	g := generator.New(seed, nil)
+	for i := 0; i < count; i++ {
+		v, err := g.Generate(typ)
+		if err != nil {
+			t.Errorf(`seed %d: %s`, seed, err.Error())
+			return
+		}
+		if !property(v) {
+			s := generator.Shrink(typ, v, func(v dgo.Value) bool { return !property(v) })
+			t.Errorf(`seed %d: property does not hold for %v (shrunk from %v)`, seed, s, v)
+			return
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// maxExampleDepth is the nesting depth at which an example is considered impossible to produce
+const maxExampleDepth = 32
+
+type example struct {
+	depth int
+}
+
+// Example returns a canonical, minimal instance of the given type. The result is deterministic. Struct maps only
+// get their required entries, strings, binaries, and collections get their minimum size, ranges yield their lower
+// bound, unions yield an instance of their least complex operand, and patterns yield a short matching string.
+//
+// An error is returned when the type has no instances, e.g. for !any.
+func Example(t dgo.Type) (v dgo.Value, err error) {
+	err = util.Catch(func() { v = (&example{}).example(t) })
+	return
+}
+
+func (e *example) example(t dgo.Type) dgo.Value {
+	if et, ok := t.(dgo.ExactType); ok && dgo.IsExact(t) {
+		return et.ExactValue()
+	}
+	switch t.TypeIdentifier() {
+	case dgo.TiNil, dgo.TiAny:
+		return vf.Nil
+	case dgo.TiBoolean:
+		return vf.False
+	case dgo.TiInteger, dgo.TiIntegerRange:
+		it := t.(dgo.IntegerType)
+		return vf.Integer(lowerInt(it.Min(), it.Max(), it.Inclusive()))
+	case dgo.TiFloat, dgo.TiFloatRange:
+		ft := t.(dgo.FloatType)
+		return e.verify(t, vf.Float(lowerFloat(ft.Min(), ft.Max(), ft.Inclusive())))
+	case dgo.TiString, dgo.TiStringSized:
+		return vf.String(strings.Repeat(`a`, t.(dgo.SizedType).Min()))
+	case dgo.TiBinary:
+		return vf.Binary(make([]byte, t.(dgo.SizedType).Min()), true)
+	case dgo.TiTime:
+		return vf.Time(time.Unix(0, 0).UTC())
+	case dgo.TiRegexp:
+		return vf.Regexp(regexp.MustCompile(``))
+	case dgo.TiError:
+		return vf.Value(errors.New(`error`))
+	case dgo.TiSensitive:
+		return vf.Sensitive(e.example(t.(dgo.UnaryType).Operand()))
+	case dgo.TiMeta:
+		if dt := t.(dgo.Meta).Describes(); dt != nil {
+			return dt
+		}
+		return typ.Any
+	}
+	return e.exampleComplex(t)
+}
+
+func (e *example) exampleComplex(t dgo.Type) dgo.Value {
+	switch t.TypeIdentifier() {
+	case dgo.TiStringPattern:
+		return e.verify(t, vf.String(minimalMatch(t.(dgo.ExactType).ExactValue().(dgo.Regexp))))
+	case dgo.TiCiString:
+		return t.(dgo.ExactType).ExactValue()
+	case dgo.TiDgoString:
+		return vf.String(`any`)
+	case dgo.TiArray:
+		return e.array(t.(dgo.ArrayType))
+	case dgo.TiTuple:
+		return e.tuple(t.(dgo.TupleType))
+	case dgo.TiStruct:
+		return e.structMap(t.(dgo.StructMapType))
+	case dgo.TiMap:
+		if t.(dgo.SizedType).Min() == 0 {
+			return vf.Map()
+		}
+	case dgo.TiAnyOf, dgo.TiOneOf, dgo.TiAllOf, dgo.TiAllOfValue:
+		return e.ternary(t, t.(dgo.TernaryType).Operands())
+	case dgo.TiNot:
+		for _, c := range notCandidates {
+			if t.Instance(c) {
+				return c
+			}
+		}
+	default:
+		panic(unable(t))
+	}
+	return e.search(t)
+}
+
+// search uses a generator with a fixed seed to find an instance of types where a minimal instance cannot be
+// determined by examining the type, e.g. maps that must have a number of distinct keys.
+func (e *example) search(t dgo.Type) dgo.Value {
+	v, err := New(0, nil).Generate(t)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// verify returns the given value if it is an instance of the given type and panics otherwise
+func (e *example) verify(t dgo.Type, v dgo.Value) dgo.Value {
+	if !t.Instance(v) {
+		panic(unable(t))
+	}
+	return v
+}
+
+var notCandidates = []dgo.Value{vf.Nil, vf.False, vf.Integer(0), vf.Float(0), vf.String(``), vf.Values(), vf.Map()}
+
+func (e *example) enter(t dgo.Type) {
+	e.depth++
+	if e.depth > maxExampleDepth {
+		panic(unable(t))
+	}
+}
+
+func (e *example) array(t dgo.ArrayType) dgo.Value {
+	e.enter(t)
+	n := t.Min()
+	a := vf.ArrayWithCapacity(n)
+	if n > 0 {
+		ev := e.example(t.ElementType())
+		for i := 0; i < n; i++ {
+			a.Add(ev)
+		}
+	}
+	e.depth--
+	return a.FrozenCopy()
+}
+
+func (e *example) tuple(t dgo.TupleType) dgo.Value {
+	e.enter(t)
+	es := t.ElementTypes()
+	n := es.Len()
+	if t.Variadic() {
+		n--
+	}
+	a := vf.ArrayWithCapacity(n)
+	for i := 0; i < n; i++ {
+		a.Add(e.example(es.Get(i).(dgo.Type)))
+	}
+	e.depth--
+	return a.FrozenCopy()
+}
+
+func (e *example) structMap(t dgo.StructMapType) dgo.Value {
+	e.enter(t)
+	m := vf.MapWithCapacity(t.Len())
+	t.Each(func(se dgo.StructMapEntry) {
+		if se.Required() {
+			m.Put(se.Key().(dgo.ExactType).ExactValue(), e.example(se.Value().(dgo.Type)))
+		}
+	})
+	e.depth--
+	return m.FrozenCopy()
+}
+
+// ternary returns the example of the first operand, in order of complexity, that has an example that is an
+// instance of the given type.
+func (e *example) ternary(t dgo.Type, operands dgo.Array) dgo.Value {
+	ts := operandTypes(operands)
+	if len(ts) == 0 && t.TypeIdentifier() == dgo.TiAllOf {
+		return vf.Nil
+	}
+	sort.SliceStable(ts, func(i, j int) bool { return complexity(ts[i]) < complexity(ts[j]) })
+	for _, ot := range ts {
+		var v dgo.Value
+		depth := e.depth
+		if util.Catch(func() { v = e.example(ot) }) == nil && t.Instance(v) {
+			return v
+		}
+		e.depth = depth
+	}
+	if len(ts) > 0 && t.TypeIdentifier() != dgo.TiAnyOf {
+		return e.search(t)
+	}
+	panic(unable(t))
+}
+
+func lowerInt(min, max int64, inclusive bool) int64 {
+	if min != math.MinInt64 {
+		return min
+	}
+	if !inclusive {
+		max--
+	}
+	return clampInt(0, min, max)
+}
+
+func lowerFloat(min, max float64, inclusive bool) float64 {
+	if min != -math.MaxFloat64 {
+		return min
+	}
+	if !inclusive {
+		max = math.Nextafter(max, math.Inf(-1))
+	}
+	if max <= 0 {
+		return max
+	}
+	return 0
+}
+
+// minimalMatch returns a short string that is likely to match the given regexp. The caller must verify the result
+// since anchors and word boundaries in the middle of a pattern are ignored.
+func minimalMatch(rx dgo.Regexp) string {
+	re, err := syntax.Parse(rx.GoRegexp().String(), syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+	sb := &strings.Builder{}
+	minimalNode(sb, re.Simplify())
+	return sb.String()
+}
+
+func minimalNode(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		panic(`regexp cannot match`)
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sb.WriteRune(minimalClassRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		sb.WriteByte('a')
+	case syntax.OpCapture:
+		minimalNode(sb, re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, _ := repeatBounds(re)
+		for ; min > 0; min-- {
+			minimalNode(sb, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, s := range re.Sub {
+			minimalNode(sb, s)
+		}
+	case syntax.OpAlternate:
+		minimalNode(sb, re.Sub[0])
+	}
+}
+
+// minimalClassRune returns the first readable rune in the given character class ranges, or the lowest rune
+// in the class if it contains no readable runes.
+func minimalClassRune(ranges []rune) rune {
+	for _, r := range `a0A_-. ` {
+		if inRanges(ranges, r) {
+			return r
+		}
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		if hi := ranges[i+1]; hi > ' ' {
+			if lo := ranges[i]; lo > ' ' {
+				return lo
+			}
+			return '!'
+		}
+	}
+	if len(ranges) == 0 {
+		panic(`regexp character class cannot match`)
+	}
+	return ranges[0]
+}
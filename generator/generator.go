@@ -0,0 +1,397 @@
+// Package generator produces random instances of dgo types. The generated values are intended to be used as
+// input for property based tests.
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+type (
+	// Options controls the size of the generated values.
+	Options struct {
+		// MaxDepth is the maximum nesting depth of generated collections. Collections at that depth or deeper are
+		// generated with their minimum size and without optional entries so that recursive types terminate.
+		MaxDepth int
+
+		// MaxSize is the maximum size of generated strings, binaries, arrays, and maps, unless their type mandates
+		// a larger size.
+		MaxSize int
+	}
+
+	// A Generator produces random instances of dgo types. The sequence of generated values is determined by the
+	// seed that the generator was created with. A Generator is not safe for concurrent use.
+	Generator interface {
+		// Generate returns a random instance of the given type or an error if no instance could be produced.
+		Generate(t dgo.Type) (dgo.Value, error)
+	}
+
+	generator struct {
+		Options
+		rnd   *rand.Rand
+		depth int
+	}
+)
+
+// maxAttempts is the number of candidates that are tried before giving up on types that can only be satisfied by
+// generating candidates and checking them, such as patterns, negations, and intersections.
+const maxAttempts = 100
+
+// printable characters used when generating unconstrained strings
+const printable = `abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.,:;!?/+*=#@$%&()[]{}<>'"`
+
+// DefaultOptions returns the default options for the generator. The returned value is a private copy that can be
+// modified by the caller before it is passed on to a generator.
+func DefaultOptions() *Options {
+	return &Options{MaxDepth: 4, MaxSize: 8}
+}
+
+// New returns a new Generator that uses a random source seeded with the given seed.
+func New(seed int64, options *Options) Generator {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	return &generator{Options: *options, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (g *generator) Generate(t dgo.Type) (v dgo.Value, err error) {
+	g.depth = 0
+	err = util.Catch(func() { v = g.generate(t) })
+	return
+}
+
+func unable(t dgo.Type) error {
+	return fmt.Errorf(`unable to generate an instance of %s`, t)
+}
+
+func (g *generator) generate(t dgo.Type) dgo.Value {
+	if et, ok := t.(dgo.ExactType); ok && dgo.IsExact(t) {
+		return et.ExactValue()
+	}
+	switch t.TypeIdentifier() {
+	case dgo.TiNil:
+		return vf.Nil
+	case dgo.TiAny:
+		return g.anyValue()
+	case dgo.TiBoolean:
+		return vf.Boolean(g.rnd.Intn(2) == 1)
+	case dgo.TiInteger, dgo.TiIntegerRange:
+		it := t.(dgo.IntegerType)
+		return vf.Integer(g.integer(it.Min(), it.Max(), it.Inclusive()))
+	case dgo.TiFloat, dgo.TiFloatRange:
+		ft := t.(dgo.FloatType)
+		return vf.Float(g.float(ft.Min(), ft.Max(), ft.Inclusive()))
+	case dgo.TiString, dgo.TiStringSized:
+		return vf.String(g.string(t.(dgo.SizedType)))
+	case dgo.TiBinary:
+		return vf.Binary(g.bytes(t.(dgo.SizedType)), true)
+	case dgo.TiTime:
+		return vf.Time(time.Unix(g.rnd.Int63n(4102444800), 0).UTC())
+	case dgo.TiRegexp:
+		return vf.Regexp(regexp.MustCompile(regexp.QuoteMeta(g.string(typ.String))))
+	case dgo.TiError:
+		return vf.Value(errors.New(g.string(typ.String)))
+	case dgo.TiSensitive:
+		return vf.Sensitive(g.generate(t.(dgo.UnaryType).Operand()))
+	case dgo.TiMeta:
+		return g.meta(t.(dgo.Meta))
+	}
+	return g.generateComplex(t)
+}
+
+func (g *generator) generateComplex(t dgo.Type) dgo.Value {
+	switch t.TypeIdentifier() {
+	case dgo.TiStringPattern:
+		return g.satisfy(t, func() dgo.Value { return vf.String(g.pattern(t.(dgo.ExactType).ExactValue().(dgo.Regexp))) })
+	case dgo.TiCiString:
+		return g.satisfy(t, func() dgo.Value { return vf.String(g.mixedCase(t.(dgo.ExactType).ExactValue().String())) })
+	case dgo.TiDgoString:
+		return g.satisfy(t, func() dgo.Value { return vf.String(g.generate(typ.Type).String()) })
+	case dgo.TiArray:
+		return g.array(t.(dgo.ArrayType))
+	case dgo.TiTuple:
+		return g.tuple(t.(dgo.TupleType))
+	case dgo.TiMap:
+		return g.mapValue(t.(dgo.MapType))
+	case dgo.TiStruct:
+		return g.structMap(t.(dgo.StructMapType))
+	case dgo.TiAnyOf:
+		return g.anyOf(t, t.(dgo.TernaryType).Operands())
+	case dgo.TiOneOf, dgo.TiAllOf, dgo.TiAllOfValue:
+		return g.someOf(t, t.(dgo.TernaryType).Operands())
+	case dgo.TiNot:
+		return g.satisfy(t, g.anyValue)
+	}
+	panic(unable(t))
+}
+
+// satisfy calls the given producer until it produces an instance of the given type. It panics if no instance
+// was produced after maxAttempts calls.
+func (g *generator) satisfy(t dgo.Type, producer func() dgo.Value) dgo.Value {
+	for i := 0; i < maxAttempts; i++ {
+		if v, ok := g.try(producer); ok && t.Instance(v) {
+			return v
+		}
+	}
+	panic(unable(t))
+}
+
+// try calls the given producer and returns its value and true, or nil and false if the producer panics with an error.
+func (g *generator) try(producer func() dgo.Value) (v dgo.Value, ok bool) {
+	depth := g.depth
+	err := util.Catch(func() { v = producer() })
+	g.depth = depth
+	return v, err == nil
+}
+
+func (g *generator) atMaxDepth() bool {
+	return g.depth >= g.MaxDepth
+}
+
+// enter increases the depth for a nested collection. It panics when the depth is so far beyond MaxDepth that
+// the type must be considered impossible to terminate.
+func (g *generator) enter(t dgo.Type) {
+	g.depth++
+	if g.depth > 2*g.MaxDepth+8 {
+		panic(fmt.Errorf(`unable to generate an instance of %s within the maximum depth`, t))
+	}
+}
+
+var scalarTypes = []dgo.Type{typ.Nil, typ.Boolean, typ.Integer, typ.Float, typ.String, typ.Binary, typ.Time}
+
+var anyTypes = []dgo.Type{typ.Nil, typ.Boolean, typ.Integer, typ.Float, typ.String, typ.Binary, typ.Time, typ.Array, typ.Map}
+
+func (g *generator) anyValue() dgo.Value {
+	ts := anyTypes
+	if g.atMaxDepth() {
+		ts = scalarTypes
+	}
+	return g.generate(ts[g.rnd.Intn(len(ts))])
+}
+
+func (g *generator) meta(t dgo.Meta) dgo.Value {
+	if dt := t.Describes(); dt != nil && dt != typ.Any {
+		return dt
+	}
+	return anyTypes[g.rnd.Intn(len(anyTypes))]
+}
+
+func (g *generator) integer(min, max int64, inclusive bool) int64 {
+	if !inclusive {
+		max--
+	}
+	switch g.rnd.Intn(4) {
+	case 0:
+		// edge cases
+		switch g.rnd.Intn(3) {
+		case 0:
+			return min
+		case 1:
+			return max
+		}
+		return clampInt(0, min, max)
+	case 1:
+		// small values
+		return clampInt(int64(g.rnd.Intn(21)-10), min, max)
+	}
+	span := uint64(max - min)
+	if span == math.MaxUint64 {
+		return int64(g.rnd.Uint64())
+	}
+	return min + int64(g.rnd.Uint64()%(span+1))
+}
+
+func clampInt(i, min, max int64) int64 {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+func (g *generator) float(min, max float64, inclusive bool) float64 {
+	var f float64
+	switch g.rnd.Intn(4) {
+	case 0:
+		f = min
+	case 1:
+		f = math.Max(min, math.Min(max, g.rnd.NormFloat64()*10))
+	default:
+		r := g.rnd.Float64()
+		f = min*(1-r) + max*r
+	}
+	if !inclusive && f >= max {
+		f = math.Nextafter(max, min)
+	}
+	return f
+}
+
+// size returns a random size within the bounds of the given type. Sizes that have no upper bound are limited
+// by MaxSize. The minimum size is always returned when the maximum depth has been reached.
+func (g *generator) size(t dgo.SizedType) int {
+	min := t.Min()
+	if g.atMaxDepth() {
+		return min
+	}
+	max := t.Max()
+	if max-min > g.MaxSize {
+		max = min + g.MaxSize
+	}
+	return min + g.rnd.Intn(max-min+1)
+}
+
+func (g *generator) string(t dgo.SizedType) string {
+	n := g.size(t)
+	bs := make([]byte, n)
+	for i := range bs {
+		bs[i] = printable[g.rnd.Intn(len(printable))]
+	}
+	return string(bs)
+}
+
+func (g *generator) mixedCase(s string) string {
+	rs := []rune(s)
+	for i, r := range rs {
+		if g.rnd.Intn(2) == 1 {
+			rs[i] = toOtherCase(r)
+		}
+	}
+	return string(rs)
+}
+
+func (g *generator) bytes(t dgo.SizedType) []byte {
+	bs := make([]byte, g.size(t))
+	g.rnd.Read(bs)
+	return bs
+}
+
+func (g *generator) array(t dgo.ArrayType) dgo.Value {
+	n := g.size(t)
+	g.enter(t)
+	et := t.ElementType()
+	a := vf.ArrayWithCapacity(n)
+	for i := 0; i < n; i++ {
+		a.Add(g.generate(et))
+	}
+	g.depth--
+	return a.FrozenCopy()
+}
+
+func (g *generator) tuple(t dgo.TupleType) dgo.Value {
+	es := t.ElementTypes()
+	n := es.Len()
+	g.enter(t)
+	a := vf.ArrayWithCapacity(n)
+	if t.Variadic() {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		a.Add(g.generate(es.Get(i).(dgo.Type)))
+	}
+	if t.Variadic() {
+		vt := es.Get(n).(dgo.Type)
+		for i := g.size(typ.Array); i > 0; i-- {
+			a.Add(g.generate(vt))
+		}
+	}
+	g.depth--
+	return a.FrozenCopy()
+}
+
+func (g *generator) mapValue(t dgo.MapType) dgo.Value {
+	n := g.size(t)
+	g.enter(t)
+	kt := t.KeyType()
+	vt := t.ValueType()
+	m := vf.MapWithCapacity(n)
+	for i := 0; m.Len() < n && i < maxAttempts; i++ {
+		m.Put(g.generate(kt), g.generate(vt))
+	}
+	if m.Len() < t.Min() {
+		panic(unable(t))
+	}
+	g.depth--
+	return m.FrozenCopy()
+}
+
+func (g *generator) structMap(t dgo.StructMapType) dgo.Value {
+	optional := !g.atMaxDepth()
+	g.enter(t)
+	m := vf.MapWithCapacity(t.Len())
+	t.Each(func(e dgo.StructMapEntry) {
+		if e.Required() || optional && g.rnd.Intn(2) == 1 {
+			m.Put(e.Key().(dgo.ExactType).ExactValue(), g.generate(e.Value().(dgo.Type)))
+		}
+	})
+	if t.Additional() && optional {
+		for n := g.rnd.Intn(3); n > 0; n-- {
+			if k := vf.String(g.string(typ.String)); t.Get(k) == nil {
+				m.Put(k, g.anyValue())
+			}
+		}
+	}
+	g.depth--
+	return m.FrozenCopy()
+}
+
+// anyOf generates an instance of one of the given operands. The operands are tried in random order unless the
+// maximum depth has been reached, in which case the least complex operands are tried first.
+func (g *generator) anyOf(t dgo.Type, operands dgo.Array) dgo.Value {
+	ts := operandTypes(operands)
+	if g.atMaxDepth() {
+		sort.SliceStable(ts, func(i, j int) bool { return complexity(ts[i]) < complexity(ts[j]) })
+	} else {
+		g.rnd.Shuffle(len(ts), func(i, j int) { ts[i], ts[j] = ts[j], ts[i] })
+	}
+	for _, ot := range ts {
+		ot := ot
+		if v, ok := g.try(func() dgo.Value { return g.generate(ot) }); ok {
+			return v
+		}
+	}
+	panic(unable(t))
+}
+
+// someOf generates instances of randomly selected operands until one of them is an instance of the given type.
+func (g *generator) someOf(t dgo.Type, operands dgo.Array) dgo.Value {
+	ts := operandTypes(operands)
+	if len(ts) == 0 {
+		if t.TypeIdentifier() == dgo.TiOneOf {
+			panic(unable(t))
+		}
+		return g.anyValue()
+	}
+	return g.satisfy(t, func() dgo.Value { return g.generate(ts[g.rnd.Intn(len(ts))]) })
+}
+
+func operandTypes(operands dgo.Array) []dgo.Type {
+	ts := make([]dgo.Type, operands.Len())
+	operands.EachWithIndex(func(v dgo.Value, i int) { ts[i] = typ.AsType(v) })
+	return ts
+}
+
+// complexity returns a rough estimate of how complex an instance of the given type is
+func complexity(t dgo.Type) int {
+	switch t.TypeIdentifier() {
+	case dgo.TiArray, dgo.TiMap:
+		if t.(dgo.SizedType).Min() == 0 {
+			return 1
+		}
+		return 2
+	case dgo.TiTuple, dgo.TiStruct, dgo.TiAnyOf, dgo.TiOneOf, dgo.TiAllOf, dgo.TiNot:
+		return 2
+	}
+	return 0
+}
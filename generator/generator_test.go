@@ -0,0 +1,223 @@
+package generator_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/generator"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func requireInstances(t *testing.T, tp dgo.Type) {
+	t.Helper()
+	g := generator.New(42, nil)
+	for i := 0; i < 200; i++ {
+		v, err := g.Generate(tp)
+		if err != nil || !tp.Instance(v) {
+			require.Ok(t, err)
+			require.Instance(t, tp, v)
+			return
+		}
+	}
+}
+
+func TestGenerate_scalars(t *testing.T) {
+	requireInstances(t, typ.Any)
+	requireInstances(t, typ.Boolean)
+	requireInstances(t, typ.Integer)
+	requireInstances(t, tf.Integer(-3, 3, false))
+	requireInstances(t, tf.Integer(1, 65535, true))
+	requireInstances(t, typ.Float)
+	requireInstances(t, tf.Float(0, 1, false))
+	requireInstances(t, tf.Float(-1e300, 1e300, true))
+	requireInstances(t, typ.String)
+	requireInstances(t, tf.String(3, 5))
+	requireInstances(t, tf.String(100))
+	requireInstances(t, typ.DgoString)
+	requireInstances(t, typ.Binary)
+	requireInstances(t, tf.Binary(2, 4))
+	requireInstances(t, typ.Time)
+	requireInstances(t, typ.Regexp)
+	requireInstances(t, typ.Error)
+	requireInstances(t, typ.Nil)
+	requireInstances(t, typ.Type)
+	requireInstances(t, tf.ParseType(`type[int]`))
+	requireInstances(t, tf.ParseType(`sensitive[1..5]`))
+}
+
+func TestGenerate_strings(t *testing.T) {
+	requireInstances(t, tf.Enum(`red`, `green`, `blue`))
+	requireInstances(t, tf.CiEnum(`red`, `green`))
+	requireInstances(t, tf.ParseType(`/^[a-z]{2,4}-\d+$/`))
+	requireInstances(t, tf.ParseType(`/(?i)^(foo|bar)+\.x?$/`))
+	requireInstances(t, tf.ParseType(`/[^a-z]/`))
+	requireInstances(t, tf.ParseType(`/abc/`))
+}
+
+func TestGenerate_collections(t *testing.T) {
+	requireInstances(t, typ.Array)
+	requireInstances(t, tf.ParseType(`[2,3]1..9`))
+	requireInstances(t, tf.ParseType(`{string,int,...bool}`))
+	requireInstances(t, tf.ParseType(`{string,1..2}`))
+	requireInstances(t, typ.Map)
+	requireInstances(t, tf.ParseType(`map[0..9,2,5]string`))
+	requireInstances(t, tf.ParseType(`{host:string[1],port?:1..65535,tags?:[]string}`))
+	requireInstances(t, tf.ParseType(`{a:int,...}`))
+}
+
+func TestGenerate_logical(t *testing.T) {
+	requireInstances(t, tf.ParseType(`int|string|[]bool`))
+	requireInstances(t, tf.ParseType(`1..10^5..15`))
+	requireInstances(t, tf.ParseType(`1..10&5..15`))
+	requireInstances(t, tf.ParseType(`!string`))
+	requireInstances(t, tf.ParseType(`!(int|float)`))
+	requireInstances(t, tf.AllOf())
+}
+
+func TestGenerate_recursive(t *testing.T) {
+	requireInstances(t, tf.ParseType(`tree={value:int,children?:[]tree}`))
+	requireInstances(t, tf.ParseType(`list={head:int,tail:list|nil}`))
+	requireInstances(t, tf.ParseType(`nested=int|[1]nested`))
+}
+
+func TestGenerate_depth(t *testing.T) {
+	tp := tf.ParseType(`nest={a:[1,3]nest}|nil`)
+	g := generator.New(1, &generator.Options{MaxDepth: 1, MaxSize: 1})
+	v, err := g.Generate(tp)
+	require.Ok(t, err)
+	require.Instance(t, tp, v)
+}
+
+func TestGenerate_seeded(t *testing.T) {
+	tp := tf.ParseType(`{a:string,b:[]int,c?:float}`)
+	a, _ := generator.New(7, nil).Generate(tp)
+	b, _ := generator.New(7, nil).Generate(tp)
+	require.Equal(t, a, b)
+}
+
+func TestGenerate_fail(t *testing.T) {
+	g := generator.New(1, nil)
+	_, err := g.Generate(typ.Not)
+	require.NotOk(t, `unable to generate an instance of !any`, err)
+
+	_, err = g.Generate(typ.AnyOf)
+	require.NotOk(t, `unable to generate an instance`, err)
+
+	_, err = g.Generate(tf.ParseType(`1..5&6..7`))
+	require.NotOk(t, `unable to generate an instance`, err)
+
+	_, err = g.Generate(tf.ParseType(`endless={a:endless}`))
+	require.NotOk(t, `maximum depth`, err)
+
+	_, err = g.Generate(typ.Function)
+	require.NotOk(t, `unable to generate an instance of func`, err)
+}
+
+func TestShrink(t *testing.T) {
+	tp := tf.ParseType(`[]0..1000`)
+	v := generator.Shrink(tp, vf.Values(3, 678, 12, 999), func(v dgo.Value) bool {
+		return v.(dgo.Array).Any(func(e dgo.Value) bool { return e.(dgo.Integer).GoInt() > 100 })
+	})
+	require.Equal(t, vf.Values(101), v)
+
+	tp = tf.ParseType(`{name:string[2],tags?:[]string}`)
+	v = generator.Shrink(tp, vf.Map(`name`, `abcdef`, `tags`, vf.Strings(`x`, `y`)), func(dgo.Value) bool { return true })
+	require.Equal(t, 1, v.(dgo.Map).Len())
+	require.Equal(t, 2, len(v.(dgo.Map).Get(`name`).String()))
+
+	v = generator.Shrink(typ.Float, vf.Float(-12.75), func(v dgo.Value) bool { return v.(dgo.Float).GoFloat() < -1 })
+	require.Equal(t, vf.Float(-1.5), v)
+}
+
+func TestForAll(t *testing.T) {
+	tp := tf.ParseType(`{a:1..100,b:string[0,5]}`)
+	require.ForAll(t, tp, func(v dgo.Value) bool {
+		m := v.(dgo.Map)
+		return m.Get(`a`).(dgo.Integer).GoInt() <= 100 && len(m.Get(`b`).String()) <= 5
+	})
+}
+
+func TestForAll_deterministic(t *testing.T) {
+	collect := func() dgo.Array {
+		a := vf.MutableValues()
+		require.ForAll(t, typ.Integer, func(v dgo.Value) bool {
+			a.Add(v)
+			return true
+		})
+		return a
+	}
+	require.Equal(t, collect(), collect())
+}
+
+func ExampleExample() {
+	v, _ := generator.Example(tf.ParseType(`{host:string[1],port:1024..65535,mode:"tcp"|"udp",tags?:[]string}`))
+	fmt.Println(v)
+
+	// Output:
+	// {"host":"a","port":1024,"mode":"tcp"}
+}
+
+func TestExample(t *testing.T) {
+	tests := map[string]dgo.Value{
+		`any`:                         vf.Nil,
+		`bool`:                        vf.False,
+		`int`:                         vf.Integer(0),
+		`-5..5`:                       vf.Integer(-5),
+		`..-3`:                        vf.Integer(-3),
+		`float`:                       vf.Float(0),
+		`2.5..`:                       vf.Float(2.5),
+		`0.0...1.0`:                   vf.Float(0),
+		`..0.0`:                       vf.Float(0),
+		`...0.0`:                      vf.Float(math.Nextafter(0, math.Inf(-1))),
+		`...-2.5`:                     vf.Float(math.Nextafter(-2.5, math.Inf(-1))),
+		`string[3]`:                   vf.String(`aaa`),
+		`"x"`:                         vf.String(`x`),
+		`/^[a-z]{2,4}-\d+$/`:          vf.String(`aa-0`),
+		`/(foo|bar)+/`:                vf.String(`foo`),
+		`[2]1..9`:                     vf.Values(1, 1),
+		`{string,int,...bool}`:        vf.Values(``, 0),
+		`map[string]int`:              vf.Map(),
+		`{a:int,b?:string}`:           vf.Map(`a`, 0),
+		`[]int|string`:                vf.String(``),
+		`1..10&5..15`:                 vf.Integer(5),
+		`!int`:                        vf.Nil,
+		`sensitive[1..2]`:             vf.Sensitive(1),
+		`exlist={v:int,n:exlist|nil}`: vf.Map(`v`, 0, `n`, nil),
+	}
+	for s, ev := range tests {
+		tp := tf.ParseType(s)
+		v, err := generator.Example(tp)
+		require.Ok(t, err)
+		require.Equal(t, ev, v)
+		require.Instance(t, tp, v)
+	}
+}
+
+func TestExample_search(t *testing.T) {
+	tp := tf.ParseType(`map[0..9,2,5]string`)
+	v, err := generator.Example(tp)
+	require.Ok(t, err)
+	require.Instance(t, tp, v)
+
+	w, _ := generator.Example(tp)
+	require.Equal(t, v, w)
+}
+
+func TestExample_fail(t *testing.T) {
+	_, err := generator.Example(typ.Not)
+	require.NotOk(t, `unable to generate an instance of !any`, err)
+
+	_, err = generator.Example(typ.AnyOf)
+	require.NotOk(t, `unable to generate an instance`, err)
+
+	_, err = generator.Example(tf.ParseType(`exendless={a:exendless}`))
+	require.NotOk(t, `unable to generate an instance`, err)
+
+	_, err = generator.Example(typ.Function)
+	require.NotOk(t, `unable to generate an instance of func`, err)
+}
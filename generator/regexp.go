@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lyraproj/dgo/dgo"
+)
+
+// pattern generates a string that is likely to match the given regexp. The caller must verify the result since
+// anchors and word boundaries in the middle of a pattern are ignored.
+func (g *generator) pattern(rx dgo.Regexp) string {
+	re, err := syntax.Parse(rx.GoRegexp().String(), syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+	sb := &strings.Builder{}
+	g.regexpNode(sb, re.Simplify())
+	return sb.String()
+}
+
+func (g *generator) regexpNode(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		panic(`regexp cannot match`)
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rnd.Intn(2) == 1 {
+				r = toOtherCase(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		sb.WriteByte(printable[g.rnd.Intn(len(printable))])
+	case syntax.OpCapture:
+		g.regexpNode(sb, re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		if max < 0 || max-min > g.MaxSize {
+			max = min + g.MaxSize
+		}
+		for n := min + g.rnd.Intn(max-min+1); n > 0; n-- {
+			g.regexpNode(sb, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, s := range re.Sub {
+			g.regexpNode(sb, s)
+		}
+	case syntax.OpAlternate:
+		g.regexpNode(sb, re.Sub[g.rnd.Intn(len(re.Sub))])
+	}
+	// All other ops, i.e. empty match, anchors, and word boundaries, don't produce any characters
+}
+
+// repeatBounds returns the minimum and maximum number of repetitions for the given repeating regexp. The maximum
+// is -1 when there is no upper bound.
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	default:
+		return re.Min, re.Max
+	}
+}
+
+// classRune returns a random rune from the given character class ranges. Printable ASCII is preferred since
+// negated classes span almost all of Unicode.
+func (g *generator) classRune(ranges []rune) rune {
+	if len(ranges) > 0 {
+		for i := 0; i < maxAttempts; i++ {
+			r := rune(printable[g.rnd.Intn(len(printable))])
+			if inRanges(ranges, r) {
+				return r
+			}
+		}
+		for i := 0; i < maxAttempts; i++ {
+			n := g.rnd.Intn(len(ranges)/2) * 2
+			lo, hi := ranges[n], ranges[n+1]
+			r := lo + rune(g.rnd.Intn(int(hi-lo)+1))
+			if utf8.ValidRune(r) {
+				return r
+			}
+		}
+	}
+	panic(`regexp character class cannot match`)
+}
+
+func inRanges(ranges []rune, r rune) bool {
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] <= r && r <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+func toOtherCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"math"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// maxShrinkSteps limits the number of successful shrink steps that Shrink will perform
+const maxShrinkSteps = 1000
+
+// Shrink returns the simplest value that it can find that is an instance of the given type and for which
+// the fails function returns true. The search starts with the given value, which must be such a value, and
+// proceeds by repeatedly replacing it with a simpler candidate for as long as one can be found. Numbers are shrunk
+// towards zero, strings and binaries are shortened, and collections lose elements and have their elements shrunk.
+func Shrink(t dgo.Type, v dgo.Value, fails func(dgo.Value) bool) dgo.Value {
+	for i := 0; i < maxShrinkSteps; i++ {
+		found := false
+		for _, c := range candidates(v) {
+			if t.Instance(c) && fails(c) {
+				v = c
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return v
+}
+
+// candidates returns values that are simpler than the given value, the simplest ones first.
+func candidates(v dgo.Value) []dgo.Value {
+	switch v := v.(type) {
+	case dgo.Boolean:
+		if v.GoBool() {
+			return []dgo.Value{vf.False}
+		}
+	case dgo.Integer:
+		return intCandidates(v.GoInt())
+	case dgo.Float:
+		return floatCandidates(v.GoFloat())
+	case dgo.String:
+		s := v.GoString()
+		cs := make([]dgo.Value, 0, 4)
+		for _, c := range sliceCandidates(len(s)) {
+			cs = append(cs, vf.String(s[c[0]:c[1]]))
+		}
+		return cs
+	case dgo.Binary:
+		bs := v.GoBytes()
+		cs := make([]dgo.Value, 0, 4)
+		for _, c := range sliceCandidates(len(bs)) {
+			cs = append(cs, vf.Binary(bs[c[0]:c[1]], true))
+		}
+		return cs
+	case dgo.Array:
+		return arrayCandidates(v)
+	case dgo.Map:
+		return mapCandidates(v)
+	case dgo.Sensitive:
+		cs := candidates(v.Unwrap())
+		for i := range cs {
+			cs[i] = vf.Sensitive(cs[i])
+		}
+		return cs
+	}
+	return nil
+}
+
+func intCandidates(i int64) []dgo.Value {
+	if i == 0 {
+		return nil
+	}
+	cs := []dgo.Value{vf.Integer(0)}
+	if h := i / 2; h != 0 {
+		cs = append(cs, vf.Integer(h))
+	}
+	if i < 0 {
+		if i != math.MinInt64 {
+			cs = append(cs, vf.Integer(-i))
+		}
+		cs = append(cs, vf.Integer(i+1))
+	} else {
+		cs = append(cs, vf.Integer(i-1))
+	}
+	return cs
+}
+
+func floatCandidates(f float64) []dgo.Value {
+	if f == 0 {
+		return nil
+	}
+	cs := []dgo.Value{vf.Float(0)}
+	if t := math.Trunc(f); t != f {
+		cs = append(cs, vf.Float(t))
+	}
+	if f < 0 {
+		cs = append(cs, vf.Float(-f))
+	}
+	if h := f / 2; h != 0 && h != f {
+		cs = append(cs, vf.Float(h))
+	}
+	return cs
+}
+
+// sliceCandidates returns start and end positions of slices that are shorter than the given length
+func sliceCandidates(l int) [][2]int {
+	if l == 0 {
+		return nil
+	}
+	cs := [][2]int{{0, 0}}
+	if h := l / 2; h > 0 {
+		cs = append(cs, [2]int{0, h}, [2]int{h, l})
+	}
+	if l > 1 {
+		cs = append(cs, [2]int{1, l}, [2]int{0, l - 1})
+	}
+	return cs
+}
+
+func arrayCandidates(a dgo.Array) []dgo.Value {
+	l := a.Len()
+	var cs []dgo.Value
+	for _, c := range sliceCandidates(l) {
+		cs = append(cs, a.Slice(c[0], c[1]))
+	}
+	for i := 0; i < l; i++ {
+		r := a.Copy(false)
+		r.Remove(i)
+		cs = append(cs, r.FrozenCopy())
+	}
+	for i := 0; i < l; i++ {
+		for _, ec := range candidates(a.Get(i)) {
+			r := a.Copy(false)
+			r.Set(i, ec)
+			cs = append(cs, r.FrozenCopy())
+		}
+	}
+	return cs
+}
+
+func mapCandidates(m dgo.Map) []dgo.Value {
+	var cs []dgo.Value
+	if m.Len() > 0 {
+		cs = append(cs, vf.Map())
+	}
+	m.EachKey(func(k dgo.Value) {
+		cs = append(cs, m.Without(k).FrozenCopy())
+	})
+	m.EachEntry(func(e dgo.MapEntry) {
+		for _, vc := range candidates(e.Value()) {
+			cs = append(cs, m.With(e.Key(), vc).FrozenCopy())
+		}
+	})
+	return cs
+}
@@ -0,0 +1,4 @@
+// Package example contains Go types that are generated from the dgo type file types.dgo
+package example
+
+//go:generate go run ../../cmd/dgogen -package example -register -o types.go types.dgo
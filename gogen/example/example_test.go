@@ -0,0 +1,51 @@
+package example_test
+
+import (
+	"testing"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/gogen/example"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func TestHost_roundTrip(t *testing.T) {
+	m := vf.Map(
+		`name`, `example.com`,
+		`port`, 8080,
+		`tags`, vf.Strings(`a`, `b`),
+		`colors`, vf.Strings(`red`, `blue`),
+		`weight`, 0.5,
+		`main`, `green`,
+		`alt`, `red`,
+		`labels`, vf.Map(`x`, `blue`),
+		`endpoints`, vf.Values(vf.Map(`path`, `/a`), vf.Map(`path`, `/b`, `secure`, true)),
+		`peers`, vf.Map(`other`, vf.Map(`name`, `other.com`, `main`, `red`, `endpoints`, vf.Values())),
+		`next`, vf.Map(`name`, `next.com`, `main`, `blue`, `endpoints`, vf.Values()),
+		`x-extra`, vf.Values(1, 2))
+
+	var h example.Host
+	vf.FromValue(example.HostType.New(m), &h)
+	require.Equal(t, `example.com`, h.Name)
+	require.Equal(t, example.Port(8080), *h.Port)
+	require.Equal(t, 2, len(h.Colors))
+	require.True(t, h.Colors[0] == example.ColorRed && h.Colors[1] == example.ColorBlue)
+	require.Equal(t, example.ColorGreen, h.Main)
+	require.Equal(t, example.ColorRed, *h.Alt)
+	require.Equal(t, example.ColorBlue, h.Labels[`x`])
+	require.Equal(t, `/b`, h.Endpoints[1].Path)
+	require.True(t, *h.Endpoints[1].Secure)
+	require.Equal(t, `other.com`, h.Peers[`other`].Name)
+	require.Equal(t, `next.com`, h.Next.Name)
+
+	require.Equal(t, m, example.HostType.ExtractInitArg(vf.Map(&h)))
+}
+
+func TestHost_optional(t *testing.T) {
+	m := vf.Map(`name`, `example.com`, `main`, `red`, `endpoints`, vf.Values())
+	var h example.Host
+	vf.FromValue(example.HostType.New(m), &h)
+	require.True(t, h.Port == nil)
+	require.True(t, h.Tags == nil)
+	require.True(t, h.Next == nil)
+	require.Equal(t, m, example.HostType.ExtractInitArg(vf.Map(&h)))
+}
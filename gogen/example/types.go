@@ -0,0 +1,205 @@
+// Code generated by dgogen from types.dgo. DO NOT EDIT.
+
+package example
+
+import (
+	"reflect"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// Color is generated from the dgo type color.
+//
+// a primary color
+type Color string
+
+// Values of Color
+const (
+	ColorRed   = Color("red")
+	ColorGreen = Color("green")
+	ColorBlue  = Color("blue")
+)
+
+// Port is generated from the dgo type port
+type Port int64
+
+// Host is generated from the dgo type host
+type Host struct {
+	// the host name
+	Name      string              `json:"name" dgo:"name"`
+	Port      *Port               `json:"port,omitempty" dgo:"port"`
+	Tags      []string            `json:"tags,omitempty" dgo:"tags"`
+	Colors    []Color             `json:"colors,omitempty" dgo:"colors"`
+	Weight    *float64            `json:"weight,omitempty" dgo:"weight"`
+	Main      Color               `json:"main" dgo:"main"`
+	Alt       *Color              `json:"alt,omitempty" dgo:"alt"`
+	Labels    map[string]Color    `json:"labels,omitempty" dgo:"labels"`
+	Endpoints []HostEndpointsItem `json:"endpoints" dgo:"endpoints"`
+	Peers     map[string]Host     `json:"peers,omitempty" dgo:"peers"`
+	Next      *Host               `json:"next,omitempty" dgo:"next"`
+	XExtra    interface{}         `json:"x-extra,omitempty" dgo:"x-extra"`
+}
+
+// HostEndpointsItem is generated from a dgo struct map type
+type HostEndpointsItem struct {
+	Path   string `json:"path" dgo:"path"`
+	Secure *bool  `json:"secure,omitempty" dgo:"secure"`
+}
+
+// HostType is the dgo named type for Host
+var HostType = tf.NewNamed("example.Host", newHost, extractHost, reflect.TypeOf(&Host{}), nil, nil)
+
+func newHost(arg dgo.Value) dgo.Value {
+	return vf.Map(toHost(arg))
+}
+
+func extractHost(value dgo.Value) dgo.Value {
+	return fromHost(value.(dgo.Struct).GoStruct().(*Host))
+}
+
+// toHost creates a Host from the given map
+func toHost(arg dgo.Value) *Host {
+	m := arg.(dgo.Map)
+	v := &Host{}
+	if x := m.Get("name"); x != nil {
+		vf.FromValue(x, &v.Name)
+	}
+	if x := m.Get("port"); x != nil {
+		var f Port
+		vf.FromValue(x, &f)
+		v.Port = &f
+	}
+	if x := m.Get("tags"); x != nil {
+		vf.FromValue(x, &v.Tags)
+	}
+	if x := m.Get("colors"); x != nil {
+		vf.FromValue(x, &v.Colors)
+	}
+	if x := m.Get("weight"); x != nil {
+		var f float64
+		vf.FromValue(x, &f)
+		v.Weight = &f
+	}
+	if x := m.Get("main"); x != nil {
+		vf.FromValue(x, &v.Main)
+	}
+	if x := m.Get("alt"); x != nil {
+		var f Color
+		vf.FromValue(x, &f)
+		v.Alt = &f
+	}
+	if x := m.Get("labels"); x != nil {
+		vf.FromValue(x, &v.Labels)
+	}
+	if x := m.Get("endpoints"); x != nil {
+		a := x.(dgo.Array)
+		v.Endpoints = make([]HostEndpointsItem, a.Len())
+		a.EachWithIndex(func(e dgo.Value, i int) { v.Endpoints[i] = *toHostEndpointsItem(e) })
+	}
+	if x := m.Get("peers"); x != nil {
+		xm := x.(dgo.Map)
+		v.Peers = make(map[string]Host, xm.Len())
+		xm.EachEntry(func(e dgo.MapEntry) {
+			var k string
+			vf.FromValue(e.Key(), &k)
+			v.Peers[k] = *toHost(e.Value())
+		})
+	}
+	if x := m.Get("next"); x != nil {
+		v.Next = toHost(x)
+	}
+	if x := m.Get("x-extra"); x != nil {
+		vf.FromValue(x, &v.XExtra)
+	}
+	return v
+}
+
+// fromHost creates a map from the given Host
+func fromHost(v *Host) dgo.Map {
+	m := vf.MapWithCapacity(12)
+	m.Put("name", v.Name)
+	if v.Port != nil {
+		m.Put("port", int64(*v.Port))
+	}
+	if v.Tags != nil {
+		m.Put("tags", v.Tags)
+	}
+	if v.Colors != nil {
+		a := vf.ArrayWithCapacity(len(v.Colors))
+		for i := range v.Colors {
+			a.Add(string(v.Colors[i]))
+		}
+		m.Put("colors", a)
+	}
+	if v.Weight != nil {
+		m.Put("weight", *v.Weight)
+	}
+	m.Put("main", string(v.Main))
+	if v.Alt != nil {
+		m.Put("alt", string(*v.Alt))
+	}
+	if v.Labels != nil {
+		xm := vf.MapWithCapacity(len(v.Labels))
+		for k, e := range v.Labels {
+			xm.Put(k, string(e))
+		}
+		m.Put("labels", xm)
+	}
+	a := vf.ArrayWithCapacity(len(v.Endpoints))
+	for i := range v.Endpoints {
+		a.Add(fromHostEndpointsItem(&v.Endpoints[i]))
+	}
+	m.Put("endpoints", a)
+	if v.Peers != nil {
+		xm := vf.MapWithCapacity(len(v.Peers))
+		for k, e := range v.Peers {
+			xm.Put(k, fromHost(&e))
+		}
+		m.Put("peers", xm)
+	}
+	if v.Next != nil {
+		m.Put("next", fromHost(v.Next))
+	}
+	if v.XExtra != nil {
+		m.Put("x-extra", v.XExtra)
+	}
+	return m
+}
+
+// HostEndpointsItemType is the dgo named type for HostEndpointsItem
+var HostEndpointsItemType = tf.NewNamed("example.HostEndpointsItem", newHostEndpointsItem, extractHostEndpointsItem, reflect.TypeOf(&HostEndpointsItem{}), nil, nil)
+
+func newHostEndpointsItem(arg dgo.Value) dgo.Value {
+	return vf.Map(toHostEndpointsItem(arg))
+}
+
+func extractHostEndpointsItem(value dgo.Value) dgo.Value {
+	return fromHostEndpointsItem(value.(dgo.Struct).GoStruct().(*HostEndpointsItem))
+}
+
+// toHostEndpointsItem creates a HostEndpointsItem from the given map
+func toHostEndpointsItem(arg dgo.Value) *HostEndpointsItem {
+	m := arg.(dgo.Map)
+	v := &HostEndpointsItem{}
+	if x := m.Get("path"); x != nil {
+		vf.FromValue(x, &v.Path)
+	}
+	if x := m.Get("secure"); x != nil {
+		var f bool
+		vf.FromValue(x, &f)
+		v.Secure = &f
+	}
+	return v
+}
+
+// fromHostEndpointsItem creates a map from the given HostEndpointsItem
+func fromHostEndpointsItem(v *HostEndpointsItem) dgo.Map {
+	m := vf.MapWithCapacity(2)
+	m.Put("path", v.Path)
+	if v.Secure != nil {
+		m.Put("secure", *v.Secure)
+	}
+	return m
+}
@@ -0,0 +1,453 @@
+// Package gogen generates Go type declarations from dgo type files
+package gogen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// Options control the generated Go source
+type Options struct {
+	// Package is the name of the package that the generated source belongs to
+	Package string
+
+	// Register, when true, adds a dgo named type for each generated struct. The named type creates instances
+	// of the struct from maps using New and extracts such maps using ExtractInitArg so that values round-trip
+	// through vf.FromValue.
+	Register bool
+}
+
+// Generate parses the given type file and returns formatted Go source that declares a Go type for each alias that
+// the file declares. Struct map types become structs with json and dgo tags, enums become typed strings with
+// constants, and other aliases become named types. Optional entries become pointers or use omitempty.
+//
+// Struct map types that are not named by an alias become structs that are named after the entry that uses them.
+func Generate(fileName, content string, options Options) (src []byte, err error) {
+	err = util.Catch(func() {
+		am, names := tf.CollectDeclarations(tf.BuiltInAliases(), func(aa dgo.AliasAdder) {
+			tf.ParseFile(aa, fileName, content)
+		})
+		g := newGenerator(am, names, options)
+		src = g.generate(fileName)
+	})
+	return
+}
+
+type field struct {
+	key      string
+	name     string
+	goType   string
+	ptr      bool
+	optional bool
+
+	// elem is the name of the struct of a field that holds a struct, a slice of structs, or a map of structs
+	elem string
+
+	// coll is dgo.TiArray or dgo.TiMap when the field holds a collection of structs
+	coll dgo.TypeIdentifier
+
+	// conv is the builtin type that values of a named scalar type, or the elements of a collection of such values,
+	// are converted to when extracted
+	conv string
+}
+
+type goStruct struct {
+	name   string
+	fields []*field
+}
+
+type generator struct {
+	options  Options
+	aliasMap dgo.AliasMap
+	names    []string
+	declared map[string]bool
+	goNames  map[string]string
+	imports  map[string]bool
+	scalars  map[string]string
+	structs  []*goStruct
+	nested   dgo.Map
+	queue    []dgo.Value
+	body     strings.Builder
+}
+
+func newGenerator(am dgo.AliasMap, names []string, options Options) *generator {
+	g := &generator{
+		options:  options,
+		aliasMap: am,
+		names:    names,
+		declared: make(map[string]bool, len(names)),
+		goNames:  make(map[string]string, len(names)),
+		imports:  make(map[string]bool),
+		scalars:  make(map[string]string),
+		nested:   vf.MapWithCapacity(0)}
+	for _, n := range names {
+		g.declared[n] = true
+		g.claim(exportedName(n), fmt.Sprintf(`alias %q`, n))
+		if st := scalarOf(am.GetType(vf.String(n))); st != `` {
+			g.scalars[exportedName(n)] = st
+		}
+	}
+	return g
+}
+
+func (g *generator) generate(fileName string) []byte {
+	for _, n := range g.names {
+		g.declare(n)
+		for len(g.queue) > 0 {
+			t := g.queue[0].(dgo.Type)
+			g.queue = g.queue[1:]
+			g.structDecl(g.nested.Get(t).String(), ``, t.(dgo.StructMapType))
+		}
+	}
+	if g.options.Register {
+		g.registrations()
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "// Code generated by dgogen from %s. DO NOT EDIT.\n\npackage %s\n", fileName, g.options.Package)
+	if len(g.imports) > 0 {
+		ips := make([]string, 0, len(g.imports))
+		for ip := range g.imports {
+			ips = append(ips, ip)
+		}
+		sort.Slice(ips, func(i, j int) bool {
+			si, sj := strings.Contains(ips[i], `.`), strings.Contains(ips[j], `.`)
+			if si != sj {
+				return sj
+			}
+			return ips[i] < ips[j]
+		})
+		sb.WriteString("\nimport (\n")
+		std := true
+		for _, ip := range ips {
+			if std && strings.Contains(ip, `.`) {
+				// third party imports are separated from the standard library
+				std = false
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(sb, "\t%q\n", ip)
+		}
+		sb.WriteString(")\n")
+	}
+	sb.WriteString(g.body.String())
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		panic(err)
+	}
+	return src
+}
+
+func (g *generator) use(ip string) {
+	g.imports[ip] = true
+}
+
+// claim registers the given Go name of a package level declaration for the given source and panics when another
+// source has already claimed it
+func (g *generator) claim(goName, source string) {
+	if prev, ok := g.goNames[goName]; ok {
+		panic(fmt.Errorf(`%s and %s both map to the Go name %s`, prev, source, goName))
+	}
+	g.goNames[goName] = source
+}
+
+// declare writes the declaration of the Go type that corresponds to the alias with the given name
+func (g *generator) declare(name string) {
+	t := g.aliasMap.GetType(vf.String(name))
+	goName := exportedName(name)
+	doc := fmt.Sprintf(`%s is generated from the dgo type %s`, goName, name)
+	if a := g.aliasMap.Annotations(vf.String(name)); a != nil {
+		if d := typ.Description(a); d != `` {
+			doc += ".\n//\n// " + strings.ReplaceAll(d, "\n", "\n// ")
+		}
+	}
+	switch {
+	case t.TypeIdentifier() == dgo.TiStruct:
+		g.structDecl(goName, doc, t.(dgo.StructMapType))
+	case enumValues(t) != nil:
+		g.enumDecl(goName, doc, enumValues(t))
+	default:
+		fmt.Fprintf(&g.body, "\n// %s\ntype %s %s\n", doc, goName, g.underlying(t, goName))
+	}
+}
+
+// scalarOf returns the builtin Go type that values of the given type can be converted to when the type is a
+// boolean, integer, float, or string type, and an empty string otherwise
+func scalarOf(t dgo.Type) string {
+	switch t.TypeIdentifier() {
+	case dgo.TiBoolean, dgo.TiBooleanExact:
+		return `bool`
+	case dgo.TiInteger, dgo.TiIntegerRange, dgo.TiIntegerExact:
+		return `int64`
+	case dgo.TiFloat, dgo.TiFloatRange, dgo.TiFloatExact:
+		return `float64`
+	case dgo.TiString, dgo.TiStringSized, dgo.TiStringExact, dgo.TiStringPattern, dgo.TiCiString, dgo.TiDgoString:
+		return `string`
+	}
+	if enumValues(t) != nil {
+		return `string`
+	}
+	return ``
+}
+
+// enumValues returns the strings of a type that is a union of exact strings, or nil if the type is something else
+func enumValues(t dgo.Type) []string {
+	var ts []dgo.Value
+	switch t.TypeIdentifier() {
+	case dgo.TiStringExact:
+		ts = []dgo.Value{t}
+	case dgo.TiAnyOf:
+		ts = t.(dgo.TernaryType).Operands().AppendToSlice(nil)
+	default:
+		return nil
+	}
+	vs := make([]string, len(ts))
+	for i, ot := range ts {
+		if ot.(dgo.Type).TypeIdentifier() != dgo.TiStringExact {
+			return nil
+		}
+		vs[i] = ot.(dgo.ExactType).ExactValue().String()
+	}
+	return vs
+}
+
+func (g *generator) enumDecl(goName, doc string, values []string) {
+	fmt.Fprintf(&g.body, "\n// %s\ntype %s string\n\n// Values of %s\nconst (\n", doc, goName, goName)
+	for _, v := range values {
+		g.claim(goName+exportedName(v), fmt.Sprintf(`value %q of %s`, v, goName))
+		fmt.Fprintf(&g.body, "\t%s = %s(%q)\n", goName+exportedName(v), goName, v)
+	}
+	g.body.WriteString(")\n")
+}
+
+func (g *generator) structDecl(goName, doc string, t dgo.StructMapType) {
+	s := &goStruct{name: goName}
+	g.structs = append(g.structs, s)
+	var fb strings.Builder
+	keys := make(map[string]string, t.Len())
+	t.Each(func(se dgo.StructMapEntry) {
+		k, ok := se.Key().(dgo.ExactType).ExactValue().(dgo.String)
+		if !ok {
+			panic(fmt.Errorf(`the struct map key %s cannot be used as a Go field name`, se.Key()))
+		}
+		if prev, ok := keys[exportedName(k.GoString())]; ok {
+			panic(fmt.Errorf(`the keys %q and %q of %s both map to the Go field name %s`,
+				prev, k, goName, exportedName(k.GoString())))
+		}
+		keys[exportedName(k.GoString())] = k.GoString()
+		f := g.field(goName, k.GoString(), se.Value().(dgo.Type), se.Required())
+		s.fields = append(s.fields, f)
+		writeFieldDoc(&fb, se.Annotations())
+		omit := ``
+		if f.optional {
+			omit = `,omitempty`
+		}
+		gt := f.goType
+		if f.ptr {
+			gt = `*` + gt
+		}
+		fmt.Fprintf(&fb, "\t%s %s `json:\"%s%s\" dgo:\"%s\"`\n", f.name, gt, f.key, omit, f.key)
+	})
+	if doc == `` {
+		doc = goName + ` is generated from a dgo struct map type`
+	}
+	fmt.Fprintf(&g.body, "\n// %s\ntype %s struct {\n%s}\n", doc, goName, fb.String())
+}
+
+func writeFieldDoc(sb *strings.Builder, annotations dgo.Map) {
+	if annotations == nil {
+		return
+	}
+	if d := typ.Description(annotations); d != `` {
+		sb.WriteString("\t// " + strings.ReplaceAll(d, "\n", "\n\t// ") + "\n")
+	}
+	if d, msg := typ.Deprecated(annotations); d {
+		if msg == `` {
+			msg = `this field should not be used`
+		}
+		sb.WriteString("\t//\n\t// Deprecated: " + msg + "\n")
+	}
+}
+
+func (g *generator) field(structName, key string, t dgo.Type, required bool) *field {
+	f := &field{key: key, name: exportedName(key), optional: !required}
+	if nt := nonNil(t); nt != nil {
+		t = nt
+		f.optional = true
+	}
+	f.goType = g.goType(t, structName+f.name)
+	switch t.TypeIdentifier() {
+	case dgo.TiStruct:
+		f.elem = f.goType
+		// required fields that hold the struct itself must be pointers
+		f.ptr = f.optional || f.goType == structName
+	case dgo.TiArray:
+		f.coll = dgo.TiArray
+		f.elem, f.conv = g.elem(t.(dgo.ArrayType).ElementType(), structName+f.name+`Item`)
+	case dgo.TiMap:
+		f.coll = dgo.TiMap
+		f.elem, f.conv = g.elem(t.(dgo.MapType).ValueType(), structName+f.name+`Value`)
+	default:
+		f.conv = g.scalars[f.goType]
+		f.ptr = f.optional && (isScalar(f.goType) || f.conv != `` || f.goType == `time.Time`)
+	}
+	return f
+}
+
+// elem returns the name of the struct or the builtin type of the named scalar that corresponds to the given element
+// type of a collection
+func (g *generator) elem(t dgo.Type, hint string) (string, string) {
+	gt := g.goType(t, hint)
+	if t.TypeIdentifier() == dgo.TiStruct {
+		return gt, ``
+	}
+	return ``, g.scalars[gt]
+}
+
+// nonNil returns the operand that isn't nil when the given type is a union of that operand and nil, and nil otherwise
+func nonNil(t dgo.Type) dgo.Type {
+	if t.TypeIdentifier() != dgo.TiAnyOf {
+		return nil
+	}
+	ops := t.(dgo.TernaryType).Operands()
+	if ops.Len() != 2 {
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		if ops.Get(i).(dgo.Type).TypeIdentifier() == dgo.TiNil {
+			return ops.Get(1 - i).(dgo.Type)
+		}
+	}
+	return nil
+}
+
+func isScalar(goType string) bool {
+	switch goType {
+	case `bool`, `int64`, `float64`, `string`:
+		return true
+	}
+	return false
+}
+
+// goType returns the name of the Go type that corresponds to the given type. The hint is used as the name of a
+// struct that is generated for a struct map type that isn't named by an alias.
+func (g *generator) goType(t dgo.Type, hint string) string {
+	if n := g.aliasMap.GetName(t); n != nil && g.declared[n.GoString()] {
+		return exportedName(n.GoString())
+	}
+	if t.TypeIdentifier() == dgo.TiStruct {
+		if n := g.nested.Get(t); n != nil {
+			return n.String()
+		}
+		g.claim(hint, `a nested struct map type`)
+		g.nested.Put(t, hint)
+		g.queue = append(g.queue, t)
+		return hint
+	}
+	return g.underlying(t, hint)
+}
+
+func (g *generator) underlying(t dgo.Type, hint string) string {
+	if st := scalarOf(t); st != `` {
+		return st
+	}
+	switch t.TypeIdentifier() {
+	case dgo.TiBinary, dgo.TiBinaryExact:
+		return `[]byte`
+	case dgo.TiTime, dgo.TiTimeExact:
+		g.use(`time`)
+		return `time.Time`
+	case dgo.TiRegexp, dgo.TiRegexpExact:
+		g.use(`regexp`)
+		return `*regexp.Regexp`
+	case dgo.TiMeta:
+		g.use(`github.com/lyraproj/dgo/dgo`)
+		return `dgo.Type`
+	case dgo.TiError:
+		return `error`
+	}
+	return g.underlyingComplex(t, hint)
+}
+
+func (g *generator) underlyingComplex(t dgo.Type, hint string) string {
+	switch t.TypeIdentifier() {
+	case dgo.TiArray:
+		return `[]` + g.goType(t.(dgo.ArrayType).ElementType(), hint+`Item`)
+	case dgo.TiTuple, dgo.TiArrayExact:
+		return `[]` + g.common(t.(dgo.TupleType).ElementTypes(), hint+`Item`)
+	case dgo.TiMap:
+		mt := t.(dgo.MapType)
+		kt := g.goType(mt.KeyType(), hint+`Key`)
+		if !isScalar(kt) && g.scalars[kt] == `` {
+			kt = `interface{}`
+		}
+		return `map[` + kt + `]` + g.goType(mt.ValueType(), hint+`Value`)
+	case dgo.TiAnyOf, dgo.TiOneOf:
+		if nt := nonNil(t); nt != nil {
+			return g.goType(nt, hint)
+		}
+		return g.common(t.(dgo.TernaryType).Operands(), hint)
+	case dgo.TiAllOf:
+		ops := t.(dgo.TernaryType).Operands()
+		if ops.Len() > 0 {
+			return g.goType(ops.Get(0).(dgo.Type), hint)
+		}
+	case dgo.TiSensitive:
+		return g.goType(t.(dgo.UnaryType).Operand(), hint)
+	}
+	return `interface{}`
+}
+
+// common returns the Go type of the given types when they all correspond to the same Go type, and interface{}
+// otherwise
+func (g *generator) common(ts dgo.Array, hint string) string {
+	c := ``
+	for i, n := 0, ts.Len(); i < n; i++ {
+		t := ts.Get(i).(dgo.Type)
+		if t.TypeIdentifier() == dgo.TiStruct {
+			return `interface{}`
+		}
+		gt := g.goType(t, hint)
+		if c != `` && c != gt {
+			return `interface{}`
+		}
+		c = gt
+	}
+	if c == `` {
+		c = `interface{}`
+	}
+	return c
+}
+
+// exportedName converts the given string into an exported Go identifier by removing characters that aren't
+// letters or digits and making the first letter of each word upper case.
+func exportedName(s string) string {
+	sb := &strings.Builder{}
+	up := true
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if sb.Len() == 0 && unicode.IsDigit(r) {
+				sb.WriteByte('X')
+			}
+			if up {
+				r = unicode.ToUpper(r)
+				up = false
+			}
+			sb.WriteRune(r)
+		default:
+			up = true
+		}
+	}
+	if sb.Len() == 0 {
+		return `X`
+	}
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+package gogen_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/gogen"
+)
+
+func ExampleGenerate() {
+	src, err := gogen.Generate(`point.dgo`, `point={x:int,y:int,label?:string}`, gogen.Options{Package: `geo`})
+	if err == nil {
+		fmt.Print(string(src))
+	}
+	// Output:
+	// // Code generated by dgogen from point.dgo. DO NOT EDIT.
+	//
+	// package geo
+	//
+	// // Point is generated from the dgo type point
+	// type Point struct {
+	// 	X     int64   `json:"x" dgo:"x"`
+	// 	Y     int64   `json:"y" dgo:"y"`
+	// 	Label *string `json:"label,omitempty" dgo:"label"`
+	// }
+}
+
+// declarations returns the generated source that follows the package clause and the imports
+func declarations(t *testing.T, content string) string {
+	t.Helper()
+	src, err := gogen.Generate(`test.dgo`, content, gogen.Options{Package: `test`})
+	require.Ok(t, err)
+	s := strings.TrimSpace(strings.SplitN(string(src), "package test\n", 2)[1])
+	if strings.HasPrefix(s, `import`) {
+		s = strings.SplitN(s, "\n)\n", 2)[1]
+	}
+	return strings.TrimSpace(s)
+}
+
+func TestGenerate_types(t *testing.T) {
+	tests := []struct {
+		content string
+		decl    string
+	}{
+		{`gA=bool`, `type GA bool`},
+		{`gA=0..9`, `type GA int64`},
+		{`gA=float`, `type GA float64`},
+		{`gA=/^a/`, `type GA string`},
+		{`gA=binary`, `type GA []byte`},
+		{`gA=type`, `type GA dgo.Type`},
+		{`gA=[]string`, `type GA []string`},
+		{`gA={string,string}`, `type GA []string`},
+		{`gA={string,int}`, `type GA []interface{}`},
+		{`gA=map[string]int`, `type GA map[string]int64`},
+		{`gA=map[[]int]int`, `type GA map[interface{}]int64`},
+		{`gA=int|float`, `type GA interface{}`},
+		{`gA=1|2`, `type GA int64`},
+		{`gA=string|nil`, `type GA string`},
+		{`gA=sensitive[int]`, `type GA int64`},
+		{`gA=any`, `type GA interface{}`},
+	}
+	for _, tt := range tests {
+		decl := declarations(t, tt.content)
+		require.Equal(t, tt.decl, decl[strings.LastIndex(decl, "\n")+1:])
+	}
+}
+
+func TestGenerate_enum(t *testing.T) {
+	require.Equal(t, `// Mode is generated from the dgo type mode.
+//
+// the access mode
+type Mode string
+
+// Values of Mode
+const (
+	ModeReadOnly  = Mode("read-only")
+	ModeReadWrite = Mode("read-write")
+)`, declarations(t, `mode=@description("the access mode") "read-only"|"read-write"`))
+}
+
+func TestGenerate_struct(t *testing.T) {
+	require.Equal(t, `// Tree is generated from the dgo type tree
+type Tree struct {
+	// the name of the tree
+	Name string `+"`"+`json:"name" dgo:"name"`+"`"+`
+	//
+	// Deprecated: use name
+	Label    *string         `+"`"+`json:"label,omitempty" dgo:"label"`+"`"+`
+	Children []Tree          `+"`"+`json:"children,omitempty" dgo:"children"`+"`"+`
+	Parent   *Tree           `+"`"+`json:"parent" dgo:"parent"`+"`"+`
+	Meta     TreeMeta        `+"`"+`json:"meta" dgo:"meta"`+"`"+`
+	Extra    *TreeMeta       `+"`"+`json:"extra,omitempty" dgo:"extra"`+"`"+`
+	X2       map[string]bool `+"`"+`json:"2,omitempty" dgo:"2"`+"`"+`
+}
+
+// TreeMeta is generated from a dgo struct map type
+type TreeMeta struct {
+	Created *int64 `+"`"+`json:"created,omitempty" dgo:"created"`+"`"+`
+}`, declarations(t, `tree={
+  @description("the name of the tree") name:string,
+  @deprecated("use name") label?:string,
+  children?:[]tree,
+  parent:tree,
+  meta:{created?:int},
+  extra:{created?:int}|nil,
+  "2"?:map[string]bool
+}`))
+}
+
+func TestGenerate_register(t *testing.T) {
+	src, err := gogen.Generate(`test.dgo`, `item={id:int}`, gogen.Options{Package: `test`, Register: true})
+	require.Ok(t, err)
+	s := string(src)
+	require.True(t, strings.Contains(s, `var ItemType = tf.NewNamed("test.Item", newItem, extractItem, reflect.TypeOf(&Item{}), nil, nil)`))
+	require.True(t, strings.Contains(s, "import (\n\t\"reflect\"\n\n\t\"github.com/lyraproj/dgo/dgo\"\n"))
+}
+
+func TestGenerate_example(t *testing.T) {
+	content, err := ioutil.ReadFile(`example/types.dgo`)
+	require.Ok(t, err)
+	expected, err := ioutil.ReadFile(`example/types.go`)
+	require.Ok(t, err)
+	src, err := gogen.Generate(`types.dgo`, string(content), gogen.Options{Package: `example`, Register: true})
+	require.Ok(t, err)
+	require.Equal(t, string(expected), string(src))
+}
+
+func TestGenerate_fail(t *testing.T) {
+	_, err := gogen.Generate(`test.dgo`, `gA={`, gogen.Options{Package: `test`})
+	require.NotOk(t, `expected`, err)
+
+	_, err = gogen.Generate(`test.dgo`, `gA={1:string}`, gogen.Options{Package: `test`})
+	require.NotOk(t, `cannot be used as a Go field name`, err)
+
+	_, err = gogen.Generate(`test.dgo`, `gA={"a-b":string,aB:int}`, gogen.Options{Package: `test`})
+	require.NotOk(t, `the keys "a-b" and "aB" of GA both map to the Go field name AB`, err)
+
+	_, err = gogen.Generate(`test.dgo`, `gMode="a-b"|"aB"`, gogen.Options{Package: `test`})
+	require.NotOk(t, `value "a-b" of GMode and value "aB" of GMode both map to the Go name GModeAB`, err)
+
+	_, err = gogen.Generate(`test.dgo`, `{g_a=int,gA=string}`, gogen.Options{Package: `test`})
+	require.NotOk(t, `alias "g_a" and alias "gA" both map to the Go name GA`, err)
+
+	_, err = gogen.Generate(`test.dgo`, `{gA={b:{c:int}},gAB=string}`, gogen.Options{Package: `test`})
+	require.NotOk(t, `alias "gAB" and a nested struct map type both map to the Go name GAB`, err)
+}
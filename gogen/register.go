@@ -0,0 +1,150 @@
+package gogen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+)
+
+// registrations writes a named type for each generated struct together with the constructor and extractor
+// functions that the named type uses
+func (g *generator) registrations() {
+	g.use(`reflect`)
+	g.use(`github.com/lyraproj/dgo/dgo`)
+	g.use(`github.com/lyraproj/dgo/tf`)
+	g.use(`github.com/lyraproj/dgo/vf`)
+	for _, s := range g.structs {
+		g.register(s)
+	}
+}
+
+func (g *generator) register(s *goStruct) {
+	n := s.name
+	fmt.Fprintf(&g.body, `
+// %[1]sType is the dgo named type for %[1]s
+var %[1]sType = tf.NewNamed(%[2]q, new%[1]s, extract%[1]s, reflect.TypeOf(&%[1]s{}), nil, nil)
+
+func new%[1]s(arg dgo.Value) dgo.Value {
+	return vf.Map(to%[1]s(arg))
+}
+
+func extract%[1]s(value dgo.Value) dgo.Value {
+	return from%[1]s(value.(dgo.Struct).GoStruct().(*%[1]s))
+}
+
+// to%[1]s creates a %[1]s from the given map
+func to%[1]s(arg dgo.Value) *%[1]s {
+	m := arg.(dgo.Map)
+	v := &%[1]s{}
+`, n, g.options.Package+`.`+n)
+	for _, f := range s.fields {
+		fmt.Fprintf(&g.body, "if x := m.Get(%q); x != nil {\n", f.key)
+		g.toField(f)
+		g.body.WriteString("}\n")
+	}
+	fmt.Fprintf(&g.body, `return v
+}
+
+// from%[1]s creates a map from the given %[1]s
+func from%[1]s(v *%[1]s) dgo.Map {
+	m := vf.MapWithCapacity(%[2]d)
+`, n, len(s.fields))
+	for _, f := range s.fields {
+		g.fromField(f)
+	}
+	g.body.WriteString("return m\n}\n")
+}
+
+// toField writes the code that assigns the dgo.Value x to the field f of the struct v
+func (g *generator) toField(f *field) {
+	b := &g.body
+	switch {
+	case f.elem != `` && f.coll == dgo.TiArray:
+		fmt.Fprintf(b, `a := x.(dgo.Array)
+v.%s = make(%s, a.Len())
+a.EachWithIndex(func(e dgo.Value, i int) { v.%s[i] = *to%s(e) })
+`, f.name, f.goType, f.name, f.elem)
+	case f.elem != `` && f.coll == dgo.TiMap:
+		fmt.Fprintf(b, `xm := x.(dgo.Map)
+v.%s = make(%s, xm.Len())
+xm.EachEntry(func(e dgo.MapEntry) {
+	var k %s
+	vf.FromValue(e.Key(), &k)
+	v.%s[k] = *to%s(e.Value())
+})
+`, f.name, f.goType, mapKey(f.goType), f.name, f.elem)
+	case f.elem != `` && f.ptr:
+		fmt.Fprintf(b, "v.%s = to%s(x)\n", f.name, f.elem)
+	case f.elem != ``:
+		fmt.Fprintf(b, "v.%s = *to%s(x)\n", f.name, f.elem)
+	case f.ptr:
+		fmt.Fprintf(b, "var f %s\nvf.FromValue(x, &f)\nv.%s = &f\n", f.goType, f.name)
+	default:
+		fmt.Fprintf(b, "vf.FromValue(x, &v.%s)\n", f.name)
+	}
+}
+
+// fromField writes the code that puts the value of the field f of the struct v into the map m
+func (g *generator) fromField(f *field) {
+	b := &g.body
+	nillable := f.ptr || f.optional && isNillable(f.goType)
+	if nillable {
+		fmt.Fprintf(b, "if v.%s != nil {\n", f.name)
+	}
+	switch {
+	case f.coll == dgo.TiArray && (f.elem != `` || f.conv != ``):
+		fmt.Fprintf(b, `a := vf.ArrayWithCapacity(len(v.%s))
+for i := range v.%s {
+	a.Add(%s)
+}
+m.Put(%q, a)
+`, f.name, f.name, f.elemValue(`v.`+f.name+`[i]`), f.key)
+	case f.coll == dgo.TiMap && (f.elem != `` || f.conv != `` || g.scalars[mapKey(f.goType)] != ``):
+		fmt.Fprintf(b, `xm := vf.MapWithCapacity(len(v.%s))
+for k, e := range v.%s {
+	xm.Put(%s, %s)
+}
+m.Put(%q, xm)
+`, f.name, f.name, convert(`k`, g.scalars[mapKey(f.goType)]), f.elemValue(`e`), f.key)
+	case f.elem != `` && f.ptr:
+		fmt.Fprintf(b, "m.Put(%q, from%s(v.%s))\n", f.key, f.elem, f.name)
+	case f.elem != ``:
+		fmt.Fprintf(b, "m.Put(%q, from%s(&v.%s))\n", f.key, f.elem, f.name)
+	case f.ptr:
+		fmt.Fprintf(b, "m.Put(%q, %s)\n", f.key, convert(`*v.`+f.name, f.conv))
+	default:
+		fmt.Fprintf(b, "m.Put(%q, %s)\n", f.key, convert(`v.`+f.name, f.conv))
+	}
+	if nillable {
+		b.WriteString("}\n")
+	}
+}
+
+// elemValue returns the expression that converts the given element of the collection that the field holds
+func (f *field) elemValue(e string) string {
+	if f.elem != `` {
+		return fmt.Sprintf(`from%s(&%s)`, f.elem, e)
+	}
+	return convert(e, f.conv)
+}
+
+func isNillable(goType string) bool {
+	switch goType {
+	case `interface{}`, `error`, `dgo.Type`:
+		return true
+	}
+	return strings.HasPrefix(goType, `[]`) || strings.HasPrefix(goType, `map[`) || strings.HasPrefix(goType, `*`)
+}
+
+func convert(e, conv string) string {
+	if conv == `` {
+		return e
+	}
+	return conv + `(` + e + `)`
+}
+
+// mapKey returns the key type of the given Go map type
+func mapKey(goType string) string {
+	return goType[len(`map[`):strings.Index(goType, `]`)]
+}
@@ -13,13 +13,32 @@ type (
 	}
 
 	aliasMap struct {
-		typeNames  hashMap
-		namedTypes hashMap
+		typeNames       hashMap
+		namedTypes      hashMap
+		annotations     hashMap
+		typeAnnotations hashMap
 	}
 
 	aliasAdder struct {
-		namedTypes hashMap
-		backingMap dgo.AliasMap
+		namedTypes  hashMap
+		annotations hashMap
+		backingMap  dgo.AliasMap
+
+		// typeAnnotations are kept in a slice since the types may contain unresolved aliases until the adder
+		// is applied to a map. They are dropped when shared is true.
+		typeAnnotations []typeAnnotation
+		shared          bool
+	}
+
+	typeAnnotation struct {
+		t           dgo.Type
+		annotations dgo.Map
+	}
+
+	// aliasRecorder records the names of the aliases that are added to an AliasAdder in the order of declaration
+	aliasRecorder struct {
+		dgo.AliasAdder
+		names []string
 	}
 
 	dType        = dgo.Type // To avoid collision with method named Type
@@ -37,9 +56,10 @@ func BuiltInAliases() dgo.AliasMap {
 var defaultLock = sync.Mutex{}
 
 // AddDefaultAliases adds the new aliases to the default alias map by passing an AliasAdder to the function
-// The function is safe from a concurrency perspective.
+// The function is safe from a concurrency perspective. Annotations of anonymous types are not added since the
+// default alias map is shared by everything that is parsed in the process.
 func AddDefaultAliases(adder func(adder dgo.AliasAdder)) {
-	AddAliases(&defaultAliases, &defaultLock, adder)
+	addAliases(&defaultAliases, &defaultLock, adder, true)
 }
 
 // AddAliases will call the given adder function, and if entries were added, lock the appointed Locker, create
@@ -48,9 +68,13 @@ func AddDefaultAliases(adder func(adder dgo.AliasAdder)) {
 //
 // No Locker is locked and no swap will take place if the adder function doesn't add anything.
 func AddAliases(mapToReplace *dgo.AliasMap, lock sync.Locker, adder func(adder dgo.AliasAdder)) {
-	am := &aliasAdder{backingMap: *mapToReplace}
+	addAliases(mapToReplace, lock, adder, false)
+}
+
+func addAliases(mapToReplace *dgo.AliasMap, lock sync.Locker, adder func(adder dgo.AliasAdder), shared bool) {
+	am := &aliasAdder{backingMap: *mapToReplace, shared: shared}
 	adder(am)
-	if am.namedTypes.Len() > 0 {
+	if am.modified() {
 		lock.Lock()
 		defer lock.Unlock()
 		*mapToReplace = (*mapToReplace).(*aliasMap).update(am)
@@ -150,12 +174,24 @@ func init() {
 func (a *aliasMap) Collect(adder func(dgo.AliasAdder)) dgo.AliasMap {
 	am := &aliasAdder{backingMap: a}
 	adder(am)
-	if am.namedTypes.Len() > 0 {
+	if am.modified() {
 		return a.update(am)
 	}
 	return a
 }
 
+// CollectDeclarations calls the Collect method of the given AliasMap with the given function and returns the result
+// together with the names of the aliases that the function added, in the order that they were added. Names that the
+// AliasMap already knows are not included.
+func CollectDeclarations(am dgo.AliasMap, adder func(dgo.AliasAdder)) (dgo.AliasMap, []string) {
+	r := &aliasRecorder{}
+	am = am.Collect(func(aa dgo.AliasAdder) {
+		r.AliasAdder = aa
+		adder(r)
+	})
+	return am, r.names
+}
+
 func (a *aliasMap) update(am *aliasAdder) dgo.AliasMap {
 	// Create a new alias map with enough room to fit the new entries
 	c := &aliasMap{}
@@ -174,11 +210,50 @@ func (a *aliasMap) update(am *aliasAdder) dgo.AliasMap {
 		c.namedTypes.Put(name, t)
 		c.typeNames.Put(t, name)
 	})
+
+	// Add annotations, merged with the annotations that the aliases already have
+	a.annotations.resize(&c.annotations, am.annotations.Len())
+	am.annotations.EachEntry(func(e dgo.MapEntry) {
+		ann := e.Value().(dgo.Map)
+		if ov := c.annotations.Get(e.Key()); ov != nil {
+			ann = ov.(dgo.Map).Merge(ann)
+		}
+		c.annotations.Put(e.Key(), ann)
+	})
+
+	a.typeAnnotations.resize(&c.typeAnnotations, len(am.typeAnnotations))
+	for _, ta := range am.typeAnnotations {
+		t := am.Replace(ta.t)
+		ann := ta.annotations
+		if ov := c.typeAnnotations.Get(t); ov != nil {
+			ann = ov.(dgo.Map).Merge(ann)
+		}
+		c.typeAnnotations.Put(t, ann)
+	}
+
 	c.namedTypes.Freeze()
 	c.typeNames.Freeze()
+	c.annotations.Freeze()
+	c.typeAnnotations.Freeze()
 	return c
 }
 
+// Annotations returns the annotations of the alias with the given name or nil if the alias has no annotations
+func (a *aliasMap) Annotations(n dgo.String) dgo.Map {
+	if v := a.annotations.Get(n); v != nil {
+		return v.(dgo.Map)
+	}
+	return nil
+}
+
+// TypeAnnotations returns the annotations of the given anonymous type or nil if the type has no annotations
+func (a *aliasMap) TypeAnnotations(t dgo.Type) dgo.Map {
+	if v := a.typeAnnotations.Get(t); v != nil {
+		return v.(dgo.Map)
+	}
+	return nil
+}
+
 // GetName returns the name for the given type or nil if the type isn't found
 func (a *aliasMap) GetName(t dgo.Type) dgo.String {
 	if v := a.typeNames.Get(t); v != nil {
@@ -199,6 +274,29 @@ func (a *aliasAdder) Add(t dgo.Type, name dgo.String) {
 	a.namedTypes.Put(name, t)
 }
 
+func (a *aliasAdder) Annotate(n dgo.String, annotations dgo.Map) {
+	if a.GetType(n) == nil {
+		panic(fmt.Errorf(`attempt to annotate unknown alias '%s'`, n))
+	}
+	CheckAnnotations(annotations)
+	ann := annotations.FrozenCopy().(dgo.Map)
+	if ov := a.annotations.Get(n); ov != nil {
+		ann = ov.(dgo.Map).Merge(ann)
+	}
+	a.annotations.Put(n, ann)
+}
+
+func (a *aliasAdder) AnnotateType(t dgo.Type, annotations dgo.Map) {
+	CheckAnnotations(annotations)
+	if !a.shared {
+		a.typeAnnotations = append(a.typeAnnotations, typeAnnotation{t, annotations.FrozenCopy().(dgo.Map)})
+	}
+}
+
+func (a *aliasAdder) modified() bool {
+	return a.namedTypes.Len() > 0 || a.annotations.Len() > 0 || len(a.typeAnnotations) > 0
+}
+
 func (a *aliasAdder) GetType(n dgo.String) dgo.Type {
 	if t := a.namedTypes.Get(n); t != nil {
 		return t.(dgo.Type)
@@ -220,3 +318,10 @@ func (a *aliasAdder) Replace(t dgo.Value) dgo.Value {
 	}
 	return t
 }
+
+func (r *aliasRecorder) Add(t dgo.Type, name dgo.String) {
+	if r.AliasAdder.GetType(name) == nil {
+		r.names = append(r.names, name.GoString())
+	}
+	r.AliasAdder.Add(t, name)
+}
@@ -60,3 +60,70 @@ func TestAddAliases(t *testing.T) {
 	require.Equal(t, aliases.GetType(vf.String(`pnr`)), tf.String(10, 12))
 	require.Nil(t, bi.GetType(vf.String(`pnr`)))
 }
+
+func TestCollectDeclarations(t *testing.T) {
+	am, names := tf.CollectDeclarations(tf.BuiltInAliases(), func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `example.dgo`, `{cdB={a:cdA}, cdA=string, cdC=int}`)
+	})
+	require.Equal(t, vf.Strings(`cdB`, `cdA`, `cdC`), vf.Strings(names...))
+	require.Equal(t, typ.String, am.GetType(vf.String(`cdA`)))
+
+	_, names = tf.CollectDeclarations(am, func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `example.dgo`, `cdA`)
+	})
+	require.Equal(t, 0, len(names))
+}
+
+func TestAliasMap_Annotations(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(a dgo.AliasAdder) {
+		tf.ParseFile(a, `example.dgo`, `@description("TCP port") port=1..65535`)
+	})
+	require.Equal(t, `port`, am.GetName(tf.Integer(1, 65535, true)))
+	require.Equal(t, vf.Map(`description`, `TCP port`), am.Annotations(vf.String(`port`)))
+	require.Nil(t, am.Annotations(vf.String(`int`)))
+
+	// Annotations are merged and are kept when the map is updated
+	am = am.Collect(func(a dgo.AliasAdder) {
+		a.Add(typ.String, vf.String(`text`))
+		a.Annotate(vf.String(`port`), vf.Map(`deprecated`, true))
+		a.Annotate(vf.String(`text`), vf.Map(`x`, 1))
+	})
+	require.Equal(t, vf.Map(`description`, `TCP port`, `deprecated`, true), am.Annotations(vf.String(`port`)))
+	require.Equal(t, vf.Map(`x`, 1), am.Annotations(vf.String(`text`)))
+
+	am = am.Collect(func(a dgo.AliasAdder) {
+		a.Add(typ.Boolean, vf.String(`flag`))
+	})
+	require.Equal(t, vf.Map(`x`, 1), am.Annotations(vf.String(`text`)))
+}
+
+func TestAliasMap_Annotations_equalTypes(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(a dgo.AliasAdder) {
+		tf.ParseFile(a, `example.dgo`, `{a: @description("first name") fname=string, b: @description("city") city=string}`)
+	})
+	require.Equal(t, vf.Map(`description`, `first name`), am.Annotations(vf.String(`fname`)))
+	require.Equal(t, vf.Map(`description`, `city`), am.Annotations(vf.String(`city`)))
+	require.Nil(t, am.Annotations(vf.String(`string`)))
+}
+
+func TestAliasMap_Annotations_recursive(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(a dgo.AliasAdder) {
+		tf.ParseFile(a, `example.dgo`, `{a:@description("a linked list") annList={v:int,next?:annList},x:@examples(1,2) 1..3}`)
+	})
+	require.Equal(t, `a linked list`, typ.Description(am.Annotations(vf.String(`annList`))))
+	require.Nil(t, am.Annotations(vf.String(`x`)))
+}
+
+func TestAliasAdder_Annotate_bad(t *testing.T) {
+	require.Panic(t, func() {
+		tf.BuiltInAliases().Collect(func(a dgo.AliasAdder) {
+			a.Add(typ.String, vf.String(`text`))
+			a.Annotate(vf.String(`text`), vf.Map(`description`, 1))
+		})
+	}, `cannot be assigned`)
+	require.Panic(t, func() {
+		tf.BuiltInAliases().Collect(func(a dgo.AliasAdder) {
+			a.Annotate(vf.String(`text`), vf.Map(`description`, `x`))
+		})
+	}, `attempt to annotate unknown alias 'text'`)
+}
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"github.com/lyraproj/dgo/dgo"
+)
+
+var annType dgo.Type
+
+// AnnotationsType returns the type that annotations must be an instance of
+func AnnotationsType() dgo.Type {
+	if annType == nil {
+		annType = Parse(`map[string]any&{description?:string,examples?:[]any,deprecated?:bool|string,...}`).(dgo.Type)
+	}
+	return annType
+}
+
+// CheckAnnotations panics unless the given map is an instance of the AnnotationsType
+func CheckAnnotations(annotations dgo.Map) {
+	if !AnnotationsType().Instance(annotations) {
+		panic(IllegalAssignment(annType, annotations))
+	}
+}
+
+// AnnotatedStructMapEntry returns a copy of the given StructMapEntry that has the given annotations
+func AnnotatedStructMapEntry(entry dgo.StructMapEntry, annotations dgo.Map) dgo.StructMapEntry {
+	CheckAnnotations(annotations)
+	return &structEntry{
+		mapEntry:    mapEntry{key: entry.Key(), value: entry.Value()},
+		required:    entry.Required(),
+		dflt:        entry.Default(),
+		annotations: annotations.FrozenCopy().(dgo.Map)}
+}
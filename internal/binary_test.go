@@ -121,6 +121,7 @@ func TestExactBinaryType(t *testing.T) {
 	require.NotEqual(t, v.HashCode(), tp.HashCode())
 
 	require.Instance(t, tp.Type(), tp)
+	require.Same(t, typ.Binary, typ.Generic(tp))
 	require.Equal(t, `binary "AQID"`, tp.String())
 	require.Equal(t, reflect.TypeOf([]byte{}), tp.ReflectType())
 
@@ -23,6 +23,9 @@ type (
 	exactMapType struct {
 		deepExactType
 		value dgo.Map
+
+		// annotations of the entries keyed by entry key. Not considered by Equals or HashCode
+		annotations dgo.Map
 	}
 
 	// sizedMapType represents a map with constraints on key type, value type, and size
@@ -1208,9 +1211,16 @@ func (t *exactMapType) Additional() bool {
 	return false
 }
 
+func (t *exactMapType) ApplyDefaults(value dgo.Map) dgo.Map {
+	return value.FrozenCopy().(dgo.Map)
+}
+
 func (t *exactMapType) Each(actor func(dgo.StructMapEntry)) {
 	t.value.EachEntry(func(e dgo.MapEntry) {
-		actor(&structEntry{mapEntry{e.Key().Type(), e.Value().Type()}, true})
+		actor(&structEntry{
+			mapEntry:    mapEntry{key: e.Key().Type(), value: e.Value().Type()},
+			required:    true,
+			annotations: t.entryAnnotations(e.Key())})
 	})
 }
 
@@ -1233,7 +1243,16 @@ func (t *exactMapType) Get(key interface{}) dgo.StructMapEntry {
 		k = et.ExactValue()
 	}
 	if v := t.value.Get(k); v != nil {
-		return &structEntry{mapEntry{k.Type(), v.Type()}, true}
+		return &structEntry{mapEntry: mapEntry{key: k.Type(), value: v.Type()}, required: true, annotations: t.entryAnnotations(k)}
+	}
+	return nil
+}
+
+func (t *exactMapType) entryAnnotations(key dgo.Value) dgo.Map {
+	if t.annotations != nil {
+		if a := t.annotations.Get(key); a != nil {
+			return a.(dgo.Map)
+		}
 	}
 	return nil
 }
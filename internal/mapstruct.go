@@ -12,15 +12,19 @@ import (
 type (
 	// structType describes each mapEntry of a map
 	structType struct {
-		additional bool
-		keys       array
-		values     array
-		required   []bool
+		additional  bool
+		keys        array
+		values      array
+		required    []bool
+		defaults    []dgo.Value
+		annotations []dgo.Map
 	}
 
 	structEntry struct {
 		mapEntry
-		required bool
+		required    bool
+		dflt        dgo.Value
+		annotations dgo.Map
 	}
 )
 
@@ -33,6 +37,8 @@ func StructMapTypeUnresolved(additional bool, entries []dgo.StructMapEntry) dgo.
 	keys := make([]dgo.Value, l)
 	values := make([]dgo.Value, l)
 	required := make([]bool, l)
+	var defaults []dgo.Value
+	var annotations []dgo.Map
 	for i := 0; i < l; i++ {
 		e := entries[i]
 		kt := e.Key().(dgo.Type)
@@ -40,29 +46,53 @@ func StructMapTypeUnresolved(additional bool, entries []dgo.StructMapEntry) dgo.
 		if exact && !(e.Required() && dgo.IsExact(kt) && dgo.IsExact(vt)) {
 			exact = false
 		}
+		if a := e.Annotations(); a != nil {
+			if annotations == nil {
+				annotations = make([]dgo.Map, l)
+			}
+			annotations[i] = a
+		}
 		keys[i] = kt
 		values[i] = vt
 		required[i] = e.Required()
+		if d := e.Default(); d != nil {
+			if defaults == nil {
+				defaults = make([]dgo.Value, l)
+			}
+			defaults[i] = d
+		}
 	}
 
 	if exact {
-		return createExactMap(keys, values)
+		return createExactMap(keys, values, annotations)
 	}
 
 	return &structType{
-		additional: additional,
-		keys:       array{slice: keys, frozen: true},
-		values:     array{slice: values, frozen: true},
-		required:   required}
+		additional:  additional,
+		keys:        array{slice: keys, frozen: true},
+		values:      array{slice: values, frozen: true},
+		required:    required,
+		defaults:    defaults,
+		annotations: annotations}
 }
 
-func createExactMap(keys, values []dgo.Value) dgo.StructMapType {
+func createExactMap(keys, values []dgo.Value, annotations []dgo.Map) dgo.StructMapType {
 	l := len(keys)
 	m := MapWithCapacity(l)
 	for i := 0; i < l; i++ {
 		m.Put(keys[i].(dgo.ExactType).ExactValue(), values[i].(dgo.ExactType).ExactValue())
 	}
-	return m.Type().(dgo.StructMapType)
+	et := m.Type().(*exactMapType)
+	if annotations != nil {
+		am := MapWithCapacity(l)
+		for i, a := range annotations {
+			if a != nil {
+				am.Put(keys[i].(dgo.ExactType).ExactValue(), a)
+			}
+		}
+		et.annotations = am.FrozenCopy().(dgo.Map)
+	}
+	return et
 }
 
 // StructMapType returns a new StructMapType type built from the given StructMapEntries.
@@ -70,6 +100,7 @@ func StructMapType(additional bool, entries []dgo.StructMapEntry) dgo.StructMapT
 	t := StructMapTypeUnresolved(additional, entries)
 	if st, ok := t.(*structType); ok {
 		st.checkExactKeys()
+		st.checkDefaults()
 	}
 	return t
 }
@@ -80,12 +111,13 @@ var sfmType dgo.MapType
 // StructMapTypeFromMap
 func StructFromMapType() dgo.MapType {
 	if sfmType == nil {
-		sfmType = Parse(`map[string](dgo|type|{type:dgo|type,required?:bool,...})`).(dgo.MapType)
+		sfmType = Parse(`map[string](dgo|type|{type:dgo|type,required?:bool,default?:any,annotations?:map[string]any,...})`).(dgo.MapType)
 	}
 	return sfmType
 }
 
-// StructMapTypeFromMap returns a new type built from a map[string](dgo|type|{type:dgo|type,required?:bool,...})
+// StructMapTypeFromMap returns a new type built from a
+// map[string](dgo|type|{type:dgo|type,required?:bool,default?:any,annotations?:map[string]any,...})
 func StructMapTypeFromMap(additional bool, entries dgo.Map) dgo.StructMapType {
 	if !StructFromMapType().Instance(entries) {
 		panic(IllegalAssignment(sfmType, entries))
@@ -94,6 +126,8 @@ func StructMapTypeFromMap(additional bool, entries dgo.Map) dgo.StructMapType {
 	keys := make([]dgo.Value, l)
 	values := make([]dgo.Value, l)
 	required := make([]bool, l)
+	var defaults []dgo.Value
+	var annotations []dgo.Map
 	i := 0
 
 	// turn dgo|type into type
@@ -122,6 +156,23 @@ func StructMapTypeFromMap(additional bool, entries dgo.Map) dgo.StructMapType {
 			if rqv := vm.Get(`required`); rqv != nil {
 				rq = rqv.(dgo.Boolean).GoBool()
 			}
+			if dv := vm.Get(`default`); dv != nil {
+				if rq {
+					panic(requiredWithDefault(e.Key()))
+				}
+				if defaults == nil {
+					defaults = make([]dgo.Value, l)
+				}
+				defaults[i] = dv
+			}
+			if av := vm.Get(`annotations`); av != nil {
+				am := av.(dgo.Map)
+				CheckAnnotations(am)
+				if annotations == nil {
+					annotations = make([]dgo.Map, l)
+				}
+				annotations[i] = am.FrozenCopy().(dgo.Map)
+			}
 		} else {
 			vt = asType(e.Value())
 		}
@@ -135,16 +186,19 @@ func StructMapTypeFromMap(additional bool, entries dgo.Map) dgo.StructMapType {
 	})
 
 	if exact {
-		return createExactMap(keys, values)
+		return createExactMap(keys, values, annotations)
 	}
 
 	t := &structType{
-		additional: additional,
-		keys:       array{slice: keys, frozen: true},
-		values:     array{slice: values, frozen: true},
-		required:   required}
+		additional:  additional,
+		keys:        array{slice: keys, frozen: true},
+		values:      array{slice: values, frozen: true},
+		required:    required,
+		defaults:    defaults,
+		annotations: annotations}
 
 	t.checkExactKeys()
+	t.checkDefaults()
 	return t
 }
 
@@ -157,10 +211,109 @@ func (t *structType) checkExactKeys() {
 	}
 }
 
+// checkDefaults panics unless each default value is an instance of the type of its entry. Defaults of entries with
+// an unresolved alias type are checked when the type is resolved.
+func (t *structType) checkDefaults() {
+	vs := t.values.slice
+	if len(vs) == 0 {
+		// recursive call from Resolve, the defaults are checked when the outermost call is done
+		return
+	}
+	for i, d := range t.defaults {
+		if d != nil {
+			vt := vs[i].(dgo.Type)
+			if _, ok := vt.(dgo.Alias); !ok && !vt.Instance(d) {
+				panic(IllegalAssignment(vt, d))
+			}
+		}
+	}
+}
+
+func requiredWithDefault(key dgo.Value) error {
+	return fmt.Errorf(`required entry '%s' cannot have a default value`, key)
+}
+
+func (t *structType) defaultAt(i int) dgo.Value {
+	if t.defaults == nil {
+		return nil
+	}
+	return t.defaults[i]
+}
+
+func (t *structType) annotationsAt(i int) dgo.Map {
+	if t.annotations == nil {
+		return nil
+	}
+	return t.annotations[i]
+}
+
 func (t *structType) Additional() bool {
 	return t.additional
 }
 
+func (t *structType) ApplyDefaults(value dgo.Map) dgo.Map {
+	m := value.Copy(false)
+	ks := t.keys.slice
+	vs := t.values.slice
+	for i := range ks {
+		k := ks[i].(dgo.ExactType).ExactValue()
+		if v := m.Get(k); v != nil {
+			m.Put(k, applyDefaults(vs[i].(dgo.Type), v))
+		} else if d := t.defaultAt(i); d != nil {
+			m.Put(k, applyDefaults(vs[i].(dgo.Type), d))
+		}
+	}
+	return m.FrozenCopy().(dgo.Map)
+}
+
+// applyDefaults applies the defaults of the struct map types found in the given type to the given value
+func applyDefaults(t dgo.Type, v dgo.Value) dgo.Value {
+	switch t := t.(type) {
+	case *structType:
+		if m, ok := v.(dgo.Map); ok {
+			return t.ApplyDefaults(m)
+		}
+	case dgo.TupleType:
+		es := t.ElementTypes()
+		if a, ok := v.(dgo.Array); ok && es.Len() > 0 {
+			n := es.Len()
+			r := ArrayWithCapacity(a.Len())
+			a.EachWithIndex(func(e dgo.Value, i int) {
+				if i >= n {
+					i = n - 1
+				}
+				r.Add(applyDefaults(es.Get(i).(dgo.Type), e))
+			})
+			return r.FrozenCopy()
+		}
+	case dgo.ArrayType:
+		if a, ok := v.(dgo.Array); ok {
+			et := t.ElementType()
+			return a.Map(func(e dgo.Value) interface{} { return applyDefaults(et, e) }).FrozenCopy()
+		}
+	case dgo.MapType:
+		if m, ok := v.(dgo.Map); ok {
+			vt := t.ValueType()
+			return m.Map(func(e dgo.MapEntry) interface{} { return applyDefaults(vt, e.Value()) }).FrozenCopy()
+		}
+	case dgo.TernaryType:
+		if ti := t.TypeIdentifier(); ti == dgo.TiAnyOf || ti == dgo.TiOneOf {
+			// Defaults are applied using the first operand that the value is an instance of
+			ops := t.Operands()
+			for i, n := 0, ops.Len(); i < n; i++ {
+				if ot := ops.Get(i).(dgo.Type); ot.Instance(v) {
+					return applyDefaults(ot, v)
+				}
+			}
+		}
+	case dgo.UnaryType:
+		if s, ok := v.(dgo.Sensitive); ok && t.TypeIdentifier() == dgo.TiSensitive {
+			return Sensitive(applyDefaults(t.Operand(), s.Unwrap()))
+		}
+	}
+	return v
+}
+
 func (t *structType) Assignable(other dgo.Type) bool {
 	return Assignable(nil, t, other)
 }
@@ -210,7 +363,7 @@ func (t *structType) Each(actor func(dgo.StructMapEntry)) {
 	vs := t.values.slice
 	rs := t.required
 	for i := range ks {
-		actor(&structEntry{mapEntry: mapEntry{key: ks[i], value: vs[i]}, required: rs[i]})
+		actor(&structEntry{mapEntry: mapEntry{key: ks[i], value: vs[i]}, required: rs[i], dflt: t.defaultAt(i), annotations: t.annotationsAt(i)})
 	}
 }
 
@@ -222,6 +375,7 @@ func (t *structType) deepEqual(seen []dgo.Value, other deepEqual) bool {
 	if ot, ok := other.(*structType); ok {
 		return t.additional == ot.additional &&
 			boolsEqual(t.required, ot.required) &&
+			valuesEqual(seen, t.defaults, ot.defaults) &&
 			equals(seen, &t.keys, &ot.keys) &&
 			equals(seen, &t.values, &ot.values)
 	}
@@ -238,6 +392,11 @@ func (t *structType) HashCode() int {
 
 func (t *structType) deepHashCode(seen []dgo.Value) int {
 	h := boolsHash(t.required)*31 + deepHashCode(seen, &t.keys)*31 + deepHashCode(seen, &t.values)
+	for _, d := range t.defaults {
+		if d != nil {
+			h = h*31 + deepHashCode(seen, d)
+		}
+	}
 	if t.additional {
 		h *= 3
 	}
@@ -277,7 +436,8 @@ func (t *structType) Get(key interface{}) dgo.StructMapEntry {
 	}
 	i := t.keys.IndexOf(kv)
 	if i >= 0 {
-		return StructMapEntry(kv, t.values.slice[i], t.required[i])
+		return &structEntry{mapEntry: mapEntry{key: kv, value: t.values.slice[i]}, required: t.required[i], dflt: t.defaultAt(i),
+			annotations: t.annotationsAt(i)}
 	}
 	return nil
 }
@@ -336,6 +496,7 @@ func (t *structType) Resolve(ap dgo.AliasAdder) {
 	t.keys.slice = ks
 	t.values.slice = vs
 	t.checkExactKeys()
+	t.checkDefaults()
 }
 
 func (t *structType) String() string {
@@ -464,6 +625,14 @@ func StructMapEntry(key interface{}, value interface{}, required bool) dgo.Struc
 	return &structEntry{mapEntry: mapEntry{key: kv, value: vv}, required: required}
 }
 
+// StructMapEntryWithDefault returns a new optional StructMapEntry initiated with the given parameters. The default
+// value must be an instance of the value type of the entry once the struct map type that contains the entry is resolved.
+func StructMapEntryWithDefault(key interface{}, value interface{}, dflt interface{}) dgo.StructMapEntry {
+	e := StructMapEntry(key, value, false).(*structEntry)
+	e.dflt = Value(dflt)
+	return e
+}
+
 func (t *structEntry) Equals(other interface{}) bool {
 	return equals(nil, t, other)
 }
@@ -471,12 +640,21 @@ func (t *structEntry) Equals(other interface{}) bool {
 func (t *structEntry) deepEqual(seen []dgo.Value, other deepEqual) bool {
 	if ot, ok := other.(dgo.StructMapEntry); ok {
 		return t.required == ot.Required() &&
+			equals(seen, t.dflt, ot.Default()) &&
 			equals(seen, t.mapEntry.key, ot.Key()) &&
 			equals(seen, t.mapEntry.value, ot.Value())
 	}
 	return false
 }
 
+func (t *structEntry) Annotations() dgo.Map {
+	return t.annotations
+}
+
+func (t *structEntry) Default() dgo.Value {
+	return t.dflt
+}
+
 func (t *structEntry) Required() bool {
 	return t.required
 }
@@ -493,6 +671,19 @@ func boolsHash(s []bool) int {
 	return h
 }
 
+func valuesEqual(seen []dgo.Value, a, b []dgo.Value) bool {
+	l := len(a)
+	if l != len(b) {
+		return false
+	}
+	for l--; l >= 0; l-- {
+		if !equals(seen, a[l], b[l]) {
+			return false
+		}
+	}
+	return true
+}
+
 func boolsEqual(a, b []bool) bool {
 	l := len(a)
 	if l != len(b) {
@@ -273,6 +273,118 @@ func TestStructEntry(t *testing.T) {
 	require.Equal(t, `"a":string`, tp.String())
 }
 
+func TestStructEntry_default(t *testing.T) {
+	tp := tf.StructMapEntryWithDefault(`a`, typ.Integer, 3)
+	require.False(t, tp.Required())
+	require.Equal(t, 3, tp.Default())
+	require.Equal(t, tp, tf.StructMapEntryWithDefault(`a`, typ.Integer, 3))
+	require.NotEqual(t, tp, tf.StructMapEntryWithDefault(`a`, typ.Integer, 4))
+	require.NotEqual(t, tp, tf.StructMapEntry(`a`, typ.Integer, false))
+	require.Nil(t, tf.StructMapEntry(`a`, typ.Integer, false).Default())
+}
+
+func TestStructType_default(t *testing.T) {
+	st := tf.StructMap(false,
+		tf.StructMapEntry(`host`, typ.String, true),
+		tf.StructMapEntryWithDefault(`port`, tf.Integer(1, 65535, true), 8080))
+	require.Equal(t, 8080, st.Get(`port`).Default())
+	require.Nil(t, st.Get(`host`).Default())
+	require.Equal(t, `{"host":string,"port"?:1..65535=8080}`, st.String())
+	require.Instance(t, st, vf.Map(`host`, `example.com`))
+
+	require.Equal(t, st, tf.ParseType(`{host:string,port?:1..65535=8080}`))
+	require.NotEqual(t, st, tf.ParseType(`{host:string,port?:1..65535=80}`))
+	require.NotEqual(t, st, tf.ParseType(`{host:string,port?:1..65535}`))
+	require.NotEqual(t, st.HashCode(), tf.ParseType(`{host:string,port?:1..65535}`).HashCode())
+
+	require.Panic(t, func() {
+		tf.StructMap(false, tf.StructMapEntryWithDefault(`port`, tf.Integer(1, 65535, true), 0))
+	}, `cannot be assigned`)
+}
+
+func TestStructType_default_recursive(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `dfltNode={name?:string="x",children?:[]dfltNode}`)
+	})
+	st := am.GetType(vf.String(`dfltNode`)).(dgo.StructMapType)
+	require.Equal(t, `x`, st.Get(`name`).Default())
+	require.Panic(t, func() {
+		tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+			tf.ParseFile(aa, `test.dgo`, `dfltNode={name?:string=1,children?:[]dfltNode}`)
+		})
+	}, `cannot be assigned`)
+}
+
+func TestStructType_ApplyDefaults(t *testing.T) {
+	st := tf.ParseType(`{
+    host:string,
+    port?:1..65535=8080,
+    tls?:{enabled?:bool=false,ciphers?:[]string={"aes"}}={},
+    routes?:[]{path:string,method?:string="GET"},
+    extra?:map[string]{level?:int=1}
+  }`).(dgo.StructMapType)
+	m := st.ApplyDefaults(vf.Map(
+		`host`, `example.com`,
+		`routes`, vf.Values(vf.Map(`path`, `/a`), vf.Map(`path`, `/b`, `method`, `PUT`)),
+		`extra`, vf.Map(`x`, vf.Map())))
+	require.True(t, m.Frozen())
+	require.Equal(t, vf.Map(
+		`host`, `example.com`,
+		`routes`, vf.Values(vf.Map(`path`, `/a`, `method`, `GET`), vf.Map(`path`, `/b`, `method`, `PUT`)),
+		`extra`, vf.Map(`x`, vf.Map(`level`, 1)),
+		`port`, 8080,
+		`tls`, vf.Map(`enabled`, false, `ciphers`, vf.Strings(`aes`))), m)
+	require.Instance(t, st, m)
+
+	m = st.ApplyDefaults(vf.Map(`host`, `example.com`, `port`, 22, `tls`, vf.Map(`enabled`, true)))
+	require.Equal(t, vf.Map(`host`, `example.com`, `port`, 22, `tls`, vf.Map(`enabled`, true, `ciphers`, vf.Strings(`aes`))), m)
+
+	et := vf.Map(`a`, 1).Type().(dgo.StructMapType)
+	require.Equal(t, vf.Map(`a`, 1), et.ApplyDefaults(vf.Map(`a`, 1)))
+}
+
+func TestStructType_ApplyDefaults_nested(t *testing.T) {
+	st := tf.ParseType(`{
+    proxy?:{port?:int=3128}|nil,
+    either?:1..9|{a:string,b?:int=1}|{c:int,d?:int=2},
+    mixed?:[]({p:int,q?:int=3}|string),
+    secret?:sensitive[{user:string,pass?:string="x"}]
+  }`).(dgo.StructMapType)
+
+	require.Equal(t, vf.Map(`proxy`, vf.Map(`port`, 3128)), st.ApplyDefaults(vf.Map(`proxy`, vf.Map())))
+	require.Equal(t, vf.Map(`proxy`, nil), st.ApplyDefaults(vf.Map(`proxy`, nil)))
+	require.Equal(t, vf.Map(`either`, vf.Map(`c`, 1, `d`, 2)), st.ApplyDefaults(vf.Map(`either`, vf.Map(`c`, 1))))
+	require.Equal(t, vf.Map(`either`, 5), st.ApplyDefaults(vf.Map(`either`, 5)))
+	require.Equal(t, vf.Map(`mixed`, vf.Values(vf.Map(`p`, 1, `q`, 3), `s`)),
+		st.ApplyDefaults(vf.Map(`mixed`, vf.Values(vf.Map(`p`, 1), `s`))))
+
+	m := st.ApplyDefaults(vf.Map(`secret`, vf.Sensitive(vf.Map(`user`, `bob`))))
+	require.Equal(t, vf.Map(`user`, `bob`, `pass`, `x`), m.Get(`secret`).(dgo.Sensitive).Unwrap())
+}
+
+func TestStructEntry_annotations(t *testing.T) {
+	e := tf.AnnotatedStructMapEntry(tf.StructMapEntryWithDefault(`a`, typ.Integer, 1), vf.Map(`description`, `the a`))
+	require.Equal(t, vf.Map(`description`, `the a`), e.Annotations())
+	require.Equal(t, 1, e.Default())
+	require.False(t, e.Required())
+	require.Equal(t, e, tf.StructMapEntryWithDefault(`a`, typ.Integer, 1))
+	require.Nil(t, tf.StructMapEntry(`a`, typ.Integer, true).Annotations())
+
+	require.Panic(t, func() {
+		tf.AnnotatedStructMapEntry(e, vf.Map(`examples`, 1))
+	}, `cannot be assigned`)
+
+	st := tf.StructMap(false, tf.AnnotatedStructMapEntry(tf.StructMapEntry(`a`, vf.Value(1).Type(), true), vf.Map(`x`, true)))
+	require.Equal(t, dgo.TiMapExact, st.TypeIdentifier())
+	require.Equal(t, vf.Map(`x`, true), st.Get(`a`).Annotations())
+	require.Equal(t, vf.Map(`a`, 1).Type(), st)
+	require.Equal(t, `{@x "a":1}`, st.String())
+	st.Each(func(e dgo.StructMapEntry) { require.Equal(t, vf.Map(`x`, true), e.Annotations()) })
+
+	st = tf.StructMapFromMap(false, vf.Map(`a`, vf.Map(`type`, typ.String, `annotations`, vf.Map(`description`, `the a`))))
+	require.Equal(t, `the a`, typ.Description(st.Get(`a`).Annotations()))
+}
+
 func TestStructFromMap(t *testing.T) {
 	require.Panic(t, func() {
 		tf.StructMapFromMap(false, vf.Map(`nope`, `dope`))
@@ -299,6 +411,17 @@ func TestStructFromMap(t *testing.T) {
 	tp = tf.StructMapFromMap(false, vf.Map(`first`, `"x"`))
 	require.Equal(t, tp, tf.StructMap(false, tf.StructMapEntry(`first`, vf.String(`x`).Type(), true)))
 
+	tp = tf.StructMapFromMap(false, vf.Map(`first`, vf.Map(`type`, `string`, `required`, false, `default`, `x`)))
+	require.Equal(t, tp, tf.StructMap(false, tf.StructMapEntryWithDefault(`first`, typ.String, `x`)))
+
+	require.Panic(t, func() {
+		tf.StructMapFromMap(false, vf.Map(`first`, vf.Map(`type`, `string`, `default`, `x`)))
+	}, `required entry 'first' cannot have a default value`)
+
+	require.Panic(t, func() {
+		tf.StructMapFromMap(false, vf.Map(`first`, vf.Map(`type`, `string`, `required`, false, `default`, 1)))
+	}, `cannot be assigned`)
+
 	tp = tf.StructMapFromMap(false, vf.Map())
 	require.Equal(t, tp, tf.StructMap(false))
 	require.False(t, tp.Unbounded())
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/lyraproj/dgo/dgo"
 	require "github.com/lyraproj/dgo/dgo_test"
 	"github.com/lyraproj/dgo/typ"
 	"github.com/lyraproj/dgo/vf"
@@ -90,4 +91,11 @@ func TestTheTester(t *testing.T) {
 	ensureFailed(t, func(ft *testing.T) {
 		require.Panic(ft, func() { panic(errors.New(`this`)) }, `that`)
 	})
+	ensureFailed(t, func(ft *testing.T) {
+		require.ForAll(ft, typ.Integer, func(v dgo.Value) bool { return v.(dgo.Integer).GoInt() < 5 })
+	})
+	ensureFailed(t, func(ft *testing.T) {
+		require.ForAllSeeded(ft, 1, 10, typ.Not, func(v dgo.Value) bool { return true })
+	})
+	require.ForAll(t, typ.String, func(v dgo.Value) bool { return typ.String.Instance(v) })
 }
@@ -0,0 +1,358 @@
+// Package jsonschema converts between dgo types and JSON Schema documents (draft 2020-12)
+package jsonschema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// SchemaURI is the URI of the JSON Schema dialect that is produced by Export
+const SchemaURI = `https://json-schema.org/draft/2020-12/schema`
+
+type exporter struct {
+	aliasMap dgo.AliasMap
+	defs     dgo.Map
+	seen     []dgo.Value
+}
+
+// Export returns a JSON Schema document that describes the given type. Types that are named in the given alias
+// map, which may be nil, are emitted under "$defs" and referenced using "$ref". Recursive types must be named.
+//
+// Annotations of named types and struct map entries are exported as "description", "examples", and "deprecated"
+// and default values of struct map entries are exported as "default".
+//
+// The document only contains strings, numbers, booleans, nil, arrays, and maps with string keys and can be
+// passed to streamer.MarshalJSON. An error is returned when the type has no JSON Schema counterpart.
+func Export(t dgo.Type, aliasMap dgo.AliasMap) (doc dgo.Map, err error) {
+	err = util.Catch(func() {
+		e := &exporter{aliasMap: aliasMap, defs: vf.MapWithCapacity(0)}
+		doc = vf.MapWithCapacity(3)
+		doc.Put(`$schema`, SchemaURI)
+		doc.PutAll(e.schema(t))
+		if e.defs.Len() > 0 {
+			doc.Put(`$defs`, e.defs)
+		}
+		doc = doc.FrozenCopy().(dgo.Map)
+	})
+	return
+}
+
+func unsupported(t dgo.Type) error {
+	return fmt.Errorf(`the type %s cannot be expressed in JSON Schema`, t)
+}
+
+// schema returns a reference to the definition of the given type if it is named in the alias map, and the
+// inlined schema of the type otherwise.
+func (e *exporter) schema(t dgo.Type) dgo.Map {
+	if e.aliasMap != nil {
+		if n := e.aliasMap.GetName(t); n != nil {
+			if e.defs.Get(n) == nil {
+				// placeholder that stops endless recursion while the definition is created
+				e.defs.Put(n, vf.Nil)
+				e.defs.Put(n, annotate(e.inline(t), e.aliasMap.Annotations(n)))
+			}
+			return schemaOf(`$ref`, `#/$defs/`+n.GoString())
+		}
+	}
+	if util.RecursionHit(e.seen, t) {
+		panic(fmt.Errorf(`the recursive type %s must be named in the alias map`, t))
+	}
+	e.seen = append(e.seen, t)
+	s := e.inline(t)
+	e.seen = e.seen[:len(e.seen)-1]
+	return s
+}
+
+func (e *exporter) named(t dgo.Type) bool {
+	return e.aliasMap != nil && e.aliasMap.GetName(t) != nil
+}
+
+// schemaOf returns a mutable schema that contains the given keyword and value pairs
+func schemaOf(kvs ...interface{}) dgo.Map {
+	s := vf.MapWithCapacity(len(kvs)/2 + 2)
+	for i := 0; i < len(kvs); i += 2 {
+		s.Put(kvs[i], kvs[i+1])
+	}
+	return s
+}
+
+func (e *exporter) inline(t dgo.Type) dgo.Map {
+	if et, ok := t.(dgo.ExactType); ok && dgo.IsExact(t) {
+		return schemaOf(`const`, jsonValue(et.ExactValue()))
+	}
+	switch t.TypeIdentifier() {
+	case dgo.TiAny:
+		return schemaOf()
+	case dgo.TiNil:
+		return schemaOf(`type`, `null`)
+	case dgo.TiBoolean:
+		return schemaOf(`type`, `boolean`)
+	case dgo.TiInteger, dgo.TiIntegerRange:
+		return integerSchema(t.(dgo.IntegerType))
+	case dgo.TiFloat, dgo.TiFloatRange:
+		return floatSchema(t.(dgo.FloatType))
+	case dgo.TiString, dgo.TiDgoString, dgo.TiStringSized:
+		return sized(schemaOf(`type`, `string`), t.(dgo.SizedType), `minLength`, `maxLength`)
+	case dgo.TiStringPattern:
+		return schemaOf(`type`, `string`, `pattern`, t.(dgo.ExactType).ExactValue().(dgo.Regexp).GoRegexp().String())
+	case dgo.TiCiString:
+		return schemaOf(`type`, `string`, `pattern`, ciPattern(t.(dgo.ExactType).ExactValue().(dgo.String).GoString()))
+	case dgo.TiBinary:
+		return schemaOf(`type`, `string`, `contentEncoding`, `base64`)
+	case dgo.TiTime:
+		return schemaOf(`type`, `string`, `format`, `date-time`)
+	case dgo.TiRegexp:
+		return schemaOf(`type`, `string`, `format`, `regex`)
+	case dgo.TiSensitive:
+		s := e.schema(t.(dgo.UnaryType).Operand())
+		s.Put(`writeOnly`, true)
+		return s
+	}
+	return e.inlineComplex(t)
+}
+
+func (e *exporter) inlineComplex(t dgo.Type) dgo.Map {
+	switch t.TypeIdentifier() {
+	case dgo.TiArray:
+		return e.array(t.(dgo.ArrayType))
+	case dgo.TiTuple:
+		return e.tuple(t.(dgo.TupleType))
+	case dgo.TiMap:
+		return e.mapType(t.(dgo.MapType))
+	case dgo.TiStruct:
+		return e.structMap(t.(dgo.StructMapType))
+	case dgo.TiAnyOf:
+		return e.anyOf(t.(dgo.TernaryType).Operands())
+	case dgo.TiOneOf:
+		return schemaOf(`oneOf`, e.schemas(t.(dgo.TernaryType).Operands()))
+	case dgo.TiAllOf:
+		return schemaOf(`allOf`, e.schemas(t.(dgo.TernaryType).Operands()))
+	case dgo.TiNot:
+		return schemaOf(`not`, e.schema(t.(dgo.UnaryType).Operand()))
+	}
+	panic(unsupported(t))
+}
+
+func integerSchema(t dgo.IntegerType) dgo.Map {
+	s := schemaOf(`type`, `integer`)
+	if min := t.Min(); min != math.MinInt64 {
+		s.Put(`minimum`, min)
+	}
+	if max := t.Max(); max != math.MaxInt64 {
+		if t.Inclusive() {
+			s.Put(`maximum`, max)
+		} else {
+			s.Put(`exclusiveMaximum`, max)
+		}
+	}
+	return s
+}
+
+func floatSchema(t dgo.FloatType) dgo.Map {
+	s := schemaOf(`type`, `number`)
+	if min := t.Min(); min != -math.MaxFloat64 {
+		s.Put(`minimum`, min)
+	}
+	if max := t.Max(); max != math.MaxFloat64 {
+		if t.Inclusive() {
+			s.Put(`maximum`, max)
+		} else {
+			s.Put(`exclusiveMaximum`, max)
+		}
+	}
+	return s
+}
+
+// sized adds the size constraints of the given type to the given schema using the given keywords
+func sized(s dgo.Map, t dgo.SizedType, minKey, maxKey string) dgo.Map {
+	if min := t.Min(); min > 0 {
+		s.Put(minKey, min)
+	}
+	if max := t.Max(); max != math.MaxInt64 {
+		s.Put(maxKey, max)
+	}
+	return s
+}
+
+// ciPattern returns an anchored pattern that matches the given string case insensitively. Inline flags such
+// as (?i) cannot be used since JSON Schema patterns use the ECMA 262 regexp dialect.
+func ciPattern(s string) string {
+	sb := &strings.Builder{}
+	sb.WriteByte('^')
+	for _, r := range s {
+		u, l := unicode.ToUpper(r), unicode.ToLower(r)
+		if u == l {
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		} else {
+			sb.WriteByte('[')
+			sb.WriteRune(u)
+			sb.WriteRune(l)
+			sb.WriteByte(']')
+		}
+	}
+	sb.WriteByte('$')
+	return sb.String()
+}
+
+func (e *exporter) schemas(ts dgo.Array) dgo.Array {
+	a := vf.ArrayWithCapacity(ts.Len())
+	ts.Each(func(t dgo.Value) { a.Add(e.schema(t.(dgo.Type))) })
+	return a
+}
+
+func (e *exporter) array(t dgo.ArrayType) dgo.Map {
+	s := schemaOf(`type`, `array`)
+	if et := t.ElementType(); et.TypeIdentifier() != dgo.TiAny {
+		s.Put(`items`, e.schema(et))
+	}
+	return sized(s, t, `minItems`, `maxItems`)
+}
+
+func (e *exporter) tuple(t dgo.TupleType) dgo.Map {
+	s := schemaOf(`type`, `array`)
+	es := t.ElementTypes()
+	n := es.Len()
+	if t.Variadic() {
+		n--
+	}
+	if n > 0 {
+		s.Put(`prefixItems`, e.schemas(es.Slice(0, n)))
+		s.Put(`minItems`, n)
+	}
+	if t.Variadic() {
+		if vt := es.Get(n).(dgo.Type); vt.TypeIdentifier() != dgo.TiAny {
+			s.Put(`items`, e.schema(vt))
+		}
+	} else {
+		s.Put(`items`, false)
+	}
+	return s
+}
+
+func (e *exporter) mapType(t dgo.MapType) dgo.Map {
+	s := schemaOf(`type`, `object`)
+	switch kt := t.KeyType(); kt.TypeIdentifier() {
+	case dgo.TiAny, dgo.TiString:
+	default:
+		if !typ.String.Assignable(kt) {
+			panic(fmt.Errorf(`the map key type %s cannot be expressed in JSON Schema`, kt))
+		}
+		s.Put(`propertyNames`, e.schema(kt))
+	}
+	if vt := t.ValueType(); vt.TypeIdentifier() != dgo.TiAny {
+		s.Put(`additionalProperties`, e.schema(vt))
+	}
+	return sized(s, t, `minProperties`, `maxProperties`)
+}
+
+func (e *exporter) structMap(t dgo.StructMapType) dgo.Map {
+	s := schemaOf(`type`, `object`)
+	props := vf.MapWithCapacity(t.Len())
+	required := vf.ArrayWithCapacity(t.Len())
+	t.Each(func(se dgo.StructMapEntry) {
+		k, ok := se.Key().(dgo.ExactType).ExactValue().(dgo.String)
+		if !ok {
+			panic(fmt.Errorf(`the struct map key %s cannot be expressed in JSON Schema`, se.Key()))
+		}
+		ps := annotate(e.schema(se.Value().(dgo.Type)), se.Annotations())
+		if d := se.Default(); d != nil {
+			ps.Put(`default`, jsonValue(d))
+		}
+		props.Put(k, ps)
+		if se.Required() {
+			required.Add(k)
+		}
+	})
+	if props.Len() > 0 {
+		s.Put(`properties`, props)
+	}
+	if required.Len() > 0 {
+		s.Put(`required`, required)
+	}
+	if !t.Additional() {
+		s.Put(`additionalProperties`, false)
+	}
+	return s
+}
+
+// anyOf returns an "enum" when all operands are exact and an "anyOf" otherwise
+func (e *exporter) anyOf(ts dgo.Array) dgo.Map {
+	if ts.Len() == 0 {
+		return schemaOf(`not`, schemaOf())
+	}
+	if s := numberSchema(ts); s != nil {
+		return s
+	}
+	if ts.All(func(t dgo.Value) bool { return dgo.IsExact(t.(dgo.Type)) && !e.named(t.(dgo.Type)) }) {
+		vs := vf.ArrayWithCapacity(ts.Len())
+		ts.Each(func(t dgo.Value) { vs.Add(jsonValue(t.(dgo.ExactType).ExactValue())) })
+		return schemaOf(`enum`, vs)
+	}
+	return schemaOf(`anyOf`, e.schemas(ts))
+}
+
+// numberSchema returns a "number" schema when the given operands are the integer and float types that such a schema
+// is imported as, or nil otherwise
+func numberSchema(ts dgo.Array) dgo.Map {
+	if ts.Len() != 2 {
+		return nil
+	}
+	ft, ok := ts.Get(1).(dgo.FloatType)
+	if !ok {
+		return nil
+	}
+	s := floatSchema(ft)
+	if nt, ok := number(`#`, s).(dgo.TernaryType); !ok || !nt.Operands().Equals(ts) {
+		return nil
+	}
+	return s
+}
+
+// annotate adds the well known annotations found in the given map to the given schema
+func annotate(s dgo.Map, annotations dgo.Map) dgo.Map {
+	if annotations == nil {
+		return s
+	}
+	if d := typ.Description(annotations); d != `` {
+		s.Put(`description`, d)
+	}
+	if x := typ.Examples(annotations); x != nil {
+		s.Put(`examples`, jsonValue(x))
+	}
+	if d, msg := typ.Deprecated(annotations); d {
+		s.Put(`deprecated`, true)
+		if msg != `` {
+			s.Put(`$comment`, msg)
+		}
+	}
+	return s
+}
+
+// jsonValue returns the given value in a form that can be represented in JSON without type information
+func jsonValue(v dgo.Value) dgo.Value {
+	switch v := v.(type) {
+	case dgo.Nil, dgo.Boolean, dgo.Integer, dgo.Float, dgo.String:
+		return v
+	case dgo.Binary:
+		return vf.String(base64.StdEncoding.EncodeToString(v.GoBytes()))
+	case dgo.Time:
+		return vf.String(v.GoTime().Format(time.RFC3339Nano))
+	case dgo.Array:
+		return v.Map(func(e dgo.Value) interface{} { return jsonValue(e) })
+	case dgo.Map:
+		if v.StringKeys() {
+			return v.Map(func(e dgo.MapEntry) interface{} { return jsonValue(e.Value()) })
+		}
+	}
+	panic(fmt.Errorf(`the value %s cannot be expressed in JSON Schema`, v))
+}
@@ -0,0 +1,131 @@
+package jsonschema_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/jsonschema"
+	"github.com/lyraproj/dgo/streamer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleExport() {
+	tp := tf.ParseType(`{name:string[1],port?:1..65535=8080,tags:[]("a"|"b")}`)
+	doc, err := jsonschema.Export(tp, nil)
+	if err == nil {
+		fmt.Println(string(streamer.MarshalJSON(doc, nil)))
+	}
+	// Output:
+	// {"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"name":{"type":"string","minLength":1},"port":{"type":"integer","minimum":1,"maximum":65535,"default":8080},"tags":{"type":"array","items":{"enum":["a","b"]}}},"required":["name","tags"],"additionalProperties":false}
+}
+
+func exportJSON(t *testing.T, tp dgo.Type, am dgo.AliasMap) string {
+	t.Helper()
+	doc, err := jsonschema.Export(tp, am)
+	require.Ok(t, err)
+	doc = doc.Without(`$schema`)
+	return string(streamer.MarshalJSON(doc, nil))
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		t dgo.Type
+		s string
+	}{
+		{tf.ParseType(`any`), `{}`},
+		{tf.ParseType(`nil`), `{"type":"null"}`},
+		{tf.ParseType(`bool`), `{"type":"boolean"}`},
+		{tf.ParseType(`true`), `{"const":true}`},
+		{tf.ParseType(`int`), `{"type":"integer"}`},
+		{tf.ParseType(`0..10`), `{"type":"integer","minimum":0,"maximum":10}`},
+		{tf.ParseType(`0...10`), `{"type":"integer","minimum":0,"exclusiveMaximum":10}`},
+		{tf.ParseType(`..10`), `{"type":"integer","maximum":10}`},
+		{tf.ParseType(`float`), `{"type":"number"}`},
+		{tf.ParseType(`0.5..1.5`), `{"type":"number","minimum":0.5,"maximum":1.5}`},
+		{tf.ParseType(`3`), `{"const":3}`},
+		{tf.ParseType(`string`), `{"type":"string"}`},
+		{tf.ParseType(`string[2,8]`), `{"type":"string","minLength":2,"maxLength":8}`},
+		{tf.ParseType(`/^a.*z$/`), `{"type":"string","pattern":"^a.*z$"}`},
+		{tf.ParseType(`~"Ab.1"`), `{"type":"string","pattern":"^[Aa][Bb]\\.1$"}`},
+		{tf.ParseType(`"a"|"b"|3`), `{"enum":["a","b",3]}`},
+		{tf.ParseType(`"a"|int`), `{"anyOf":[{"const":"a"},{"type":"integer"}]}`},
+		{tf.ParseType(`string^/x/`), `{"oneOf":[{"type":"string"},{"type":"string","pattern":"x"}]}`},
+		{tf.ParseType(`string[1]&/x/`), `{"allOf":[{"type":"string","minLength":1},{"type":"string","pattern":"x"}]}`},
+		{tf.ParseType(`!int`), `{"not":{"type":"integer"}}`},
+		{typ.Binary, `{"type":"string","contentEncoding":"base64"}`},
+		{typ.Time, `{"type":"string","format":"date-time"}`},
+		{typ.Regexp, `{"type":"string","format":"regex"}`},
+		{tf.ParseType(`sensitive[string]`), `{"type":"string","writeOnly":true}`},
+		{tf.ParseType(`[1,3]int`), `{"type":"array","items":{"type":"integer"},"minItems":1,"maxItems":3}`},
+		{tf.ParseType(`[]any`), `{"type":"array"}`},
+		{tf.ParseType(`{string,int}`), `{"type":"array","prefixItems":[{"type":"string"},{"type":"integer"}],"minItems":2,"items":false}`},
+		{tf.ParseType(`{string,...int}`), `{"type":"array","prefixItems":[{"type":"string"}],"minItems":1,"items":{"type":"integer"}}`},
+		{tf.ParseType(`map[string,1,5]int`), `{"type":"object","additionalProperties":{"type":"integer"},"minProperties":1,"maxProperties":5}`},
+		{tf.ParseType(`map[/^x-/]any`), `{"type":"object","propertyNames":{"type":"string","pattern":"^x-"}}`},
+		{tf.ParseType(`{a:int,b?:string,...}`), `{"type":"object","properties":{"a":{"type":"integer"},"b":{"type":"string"}},"required":["a"]}`},
+		{tf.ParseType(`{}`), `{"const":{}}`},
+		{vf.Map(`a`, vf.Values(1, `x`)).Type(), `{"const":{"a":[1,"x"]}}`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.s, exportJSON(t, tt.t, nil))
+	}
+}
+
+func TestExport_schema(t *testing.T) {
+	doc, err := jsonschema.Export(typ.Boolean, nil)
+	require.Ok(t, err)
+	require.Equal(t, jsonschema.SchemaURI, doc.Get(`$schema`))
+	require.True(t, doc.Frozen())
+}
+
+func TestExport_aliases(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `jsNode={value:int,children?:[]jsNode}`)
+		tf.ParseFile(aa, `test.dgo`, `jsHost={name:string,port:jsPort=@description("a TCP port") @examples(80, 443) 1..65535}`)
+	})
+	require.Equal(t,
+		`{"$ref":"#/$defs/jsHost","$defs":{"jsHost":{"type":"object","properties":{"name":{"type":"string"},`+
+			`"port":{"$ref":"#/$defs/jsPort"}},"required":["name","port"],"additionalProperties":false},`+
+			`"jsPort":{"type":"integer","minimum":1,"maximum":65535,"description":"a TCP port","examples":[80,443]}}}`,
+		exportJSON(t, am.GetType(vf.String(`jsHost`)), am))
+
+	require.Equal(t,
+		`{"type":"array","items":{"$ref":"#/$defs/jsNode"},"$defs":{"jsNode":{"type":"object","properties":{`+
+			`"value":{"type":"integer"},"children":{"type":"array","items":{"$ref":"#/$defs/jsNode"}}},`+
+			`"required":["value"],"additionalProperties":false}}}`,
+		exportJSON(t, tf.Array(am.GetType(vf.String(`jsNode`))), am))
+}
+
+func TestExport_entryAnnotations(t *testing.T) {
+	tp := tf.StructMap(false,
+		tf.AnnotatedStructMapEntry(tf.StructMapEntry(`a`, typ.String, true),
+			vf.Map(`description`, `the a`, `deprecated`, `use b`)),
+		tf.StructMapEntryWithDefault(`b`, typ.Binary, vf.Binary([]byte{1, 2, 3}, true)))
+	require.Equal(t,
+		`{"type":"object","properties":{"a":{"type":"string","description":"the a","deprecated":true,"$comment":"use b"},`+
+			`"b":{"type":"string","contentEncoding":"base64","default":"AQID"}},"required":["a"],"additionalProperties":false}`,
+		exportJSON(t, tp, nil))
+}
+
+func TestExport_fail(t *testing.T) {
+	for _, tp := range []dgo.Type{
+		typ.Error,
+		tf.ParseType(`type`),
+		tf.ParseType(`func(int)`),
+		tf.ParseType(`map[int]string`),
+		tf.ParseType(`{1:string}`),
+	} {
+		_, err := jsonschema.Export(tp, nil)
+		require.NotOk(t, `cannot be expressed in JSON Schema`, err)
+	}
+
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `jsList={value:int,next?:jsList}`)
+	})
+	_, err := jsonschema.Export(am.GetType(vf.String(`jsList`)), nil)
+	require.NotOk(t, `must be named in the alias map`, err)
+}
@@ -0,0 +1,639 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// keyword kinds
+const (
+	kwAny = iota
+	kwRoot
+	kwAnnotation
+	kwNumber
+	kwString
+	kwArray
+	kwObject
+)
+
+// keywords are the keywords that Import understands. All other keywords are reported as unsupported.
+var keywords = map[string]int{
+	`$schema`:              kwRoot,
+	`$id`:                  kwRoot,
+	`$defs`:                kwRoot,
+	`definitions`:          kwRoot,
+	`$ref`:                 kwAny,
+	`type`:                 kwAny,
+	`const`:                kwAny,
+	`enum`:                 kwAny,
+	`allOf`:                kwAny,
+	`anyOf`:                kwAny,
+	`oneOf`:                kwAny,
+	`not`:                  kwAny,
+	`title`:                kwAnnotation,
+	`description`:          kwAnnotation,
+	`examples`:             kwAnnotation,
+	`deprecated`:           kwAnnotation,
+	`default`:              kwAnnotation,
+	`$comment`:             kwAnnotation,
+	`readOnly`:             kwAnnotation,
+	`writeOnly`:            kwAnnotation,
+	`minimum`:              kwNumber,
+	`maximum`:              kwNumber,
+	`exclusiveMinimum`:     kwNumber,
+	`exclusiveMaximum`:     kwNumber,
+	`minLength`:            kwString,
+	`maxLength`:            kwString,
+	`pattern`:              kwString,
+	`format`:               kwString,
+	`contentEncoding`:      kwString,
+	`items`:                kwArray,
+	`prefixItems`:          kwArray,
+	`minItems`:             kwArray,
+	`maxItems`:             kwArray,
+	`uniqueItems`:          kwArray,
+	`properties`:           kwObject,
+	`required`:             kwObject,
+	`additionalProperties`: kwObject,
+	`patternProperties`:    kwObject,
+	`propertyNames`:        kwObject,
+	`minProperties`:        kwObject,
+	`maxProperties`:        kwObject,
+}
+
+// formats maps the supported values of the "format" keyword to patterns that strings of that format must match
+var formats = map[string]string{
+	`date-time`: `^\d{4}-\d{2}-\d{2}[Tt]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`,
+	`date`:      `^\d{4}-\d{2}-\d{2}$`,
+	`time`:      `^\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`,
+	`email`:     `^[^@\s]+@[^@\s]+$`,
+	`ipv4`:      `^((25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(25[0-5]|2[0-4]\d|1?\d?\d)$`,
+	`uuid`:      `^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`,
+}
+
+// encodings maps the supported values of the "contentEncoding" keyword to patterns that encoded strings must match
+var encodings = map[string]string{
+	`base64`: `^[A-Za-z0-9+/]*={0,2}$`,
+}
+
+type importer struct {
+	aliasAdder dgo.AliasAdder
+	defs       dgo.Map
+}
+
+// Import returns the type described by the given JSON Schema document, typically obtained using
+// streamer.UnmarshalJSON, together with an alias map where each entry in "$defs" is named. References must
+// be local to the document and point to an entry in "$defs".
+//
+// The types produced by Import validate JSON data. Hence, formats and content encodings become string patterns and
+// numbers become a union of an integer and a float type with the same bounds, since decoded JSON numbers without a
+// fraction are integers. The annotations "description", "examples", and "deprecated" are retained for entries in
+// "$defs" and for properties. Other annotations are ignored since they don't affect validation.
+//
+// An error is returned when the document uses keywords, formats, or combinations of keywords that have no dgo
+// counterpart. The error contains a JSON pointer to the offending schema.
+func Import(doc dgo.Value) (t dgo.Type, aliasMap dgo.AliasMap, err error) {
+	err = util.Catch(func() {
+		aliasMap = tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+			im := &importer{aliasAdder: aa}
+			t = aa.Replace(im.document(doc)).(dgo.Type)
+		})
+	})
+	return
+}
+
+func unsupportedKeyword(path, key string) error {
+	return fmt.Errorf(`unsupported JSON Schema keyword "%s" at %s`, key, path)
+}
+
+func invalidKeyword(path, key string) error {
+	return fmt.Errorf(`invalid value for JSON Schema keyword "%s" at %s`, key, path)
+}
+
+func childPath(path, key string) string {
+	return path + `/` + strings.NewReplacer(`~`, `~0`, `/`, `~1`).Replace(key)
+}
+
+func (im *importer) document(doc dgo.Value) dgo.Type {
+	if m, ok := doc.(dgo.Map); ok {
+		defsKey := `$defs`
+		im.defs, _ = m.Get(defsKey).(dgo.Map)
+		if im.defs == nil {
+			defsKey = `definitions`
+			im.defs, _ = m.Get(defsKey).(dgo.Map)
+		}
+		if im.defs != nil {
+			im.defs.EachEntry(func(e dgo.MapEntry) {
+				name := e.Key().(dgo.String)
+				t := im.schema(childPath(`#/`+defsKey, name.GoString()), e.Value())
+				im.aliasAdder.Add(t, name)
+				if sm, ok := e.Value().(dgo.Map); ok {
+					if a := annotations(sm); a != nil {
+						im.aliasAdder.Annotate(name, a)
+					}
+				}
+			})
+		}
+	}
+	return im.schema(`#`, doc)
+}
+
+// schema returns the type described by the given schema. All keywords that apply to the schema are combined
+// into one type using allOf.
+func (im *importer) schema(path string, v dgo.Value) dgo.Type {
+	m, ok := v.(dgo.Map)
+	if !ok {
+		if b, ok := v.(dgo.Boolean); ok {
+			if b.GoBool() {
+				return typ.Any
+			}
+			return tf.Not(typ.Any)
+		}
+		panic(fmt.Errorf(`expected a JSON Schema at %s, got %s`, path, v))
+	}
+	m.EachKey(func(k dgo.Value) {
+		ks := k.String()
+		kind, ok := keywords[ks]
+		if !ok || kind == kwRoot && path != `#` {
+			panic(unsupportedKeyword(path, ks))
+		}
+	})
+	var parts []interface{}
+	if r := m.Get(`$ref`); r != nil {
+		parts = append(parts, im.ref(path, r))
+	}
+	if c := m.Get(`const`); c != nil {
+		parts = append(parts, c.Type())
+	}
+	if e := m.Get(`enum`); e != nil {
+		parts = append(parts, enum(path, e))
+	}
+	if t := im.typed(path, m); t != nil {
+		parts = append(parts, t)
+	}
+	if a := m.Get(`allOf`); a != nil {
+		parts = append(parts, im.schemas(childPath(path, `allOf`), a)...)
+	}
+	if a := m.Get(`anyOf`); a != nil {
+		parts = append(parts, tf.AnyOf(im.schemas(childPath(path, `anyOf`), a)...))
+	}
+	if a := m.Get(`oneOf`); a != nil {
+		parts = append(parts, tf.OneOf(im.schemas(childPath(path, `oneOf`), a)...))
+	}
+	if n := m.Get(`not`); n != nil {
+		parts = append(parts, tf.Not(im.schema(childPath(path, `not`), n)))
+	}
+	switch len(parts) {
+	case 0:
+		return typ.Any
+	case 1:
+		return parts[0].(dgo.Type)
+	default:
+		return tf.AllOf(parts...)
+	}
+}
+
+func (im *importer) schemas(path string, v dgo.Value) []interface{} {
+	a, ok := v.(dgo.Array)
+	if !ok || a.Len() == 0 {
+		panic(fmt.Errorf(`expected a non empty array of JSON Schemas at %s`, path))
+	}
+	ts := make([]interface{}, a.Len())
+	a.EachWithIndex(func(s dgo.Value, i int) { ts[i] = im.schema(fmt.Sprintf(`%s/%d`, path, i), s) })
+	return ts
+}
+
+// ref returns an alias for the "$defs" entry appointed by the given reference. The alias is resolved when all
+// entries have been imported.
+func (im *importer) ref(path string, r dgo.Value) dgo.Type {
+	rs, ok := r.(dgo.String)
+	if !ok {
+		panic(invalidKeyword(path, `$ref`))
+	}
+	s := rs.GoString()
+	for _, prefix := range []string{`#/$defs/`, `#/definitions/`} {
+		if strings.HasPrefix(s, prefix) {
+			name := vf.String(strings.NewReplacer(`~1`, `/`, `~0`, `~`).Replace(s[len(prefix):]))
+			if im.defs == nil || im.defs.Get(name) == nil {
+				panic(fmt.Errorf(`unresolved JSON Schema reference "%s" at %s`, s, path))
+			}
+			return parser.NewAlias(name)
+		}
+	}
+	panic(fmt.Errorf(`unsupported JSON Schema reference "%s" at %s. Only references to local definitions are supported`, s, path))
+}
+
+func enum(path string, e dgo.Value) dgo.Type {
+	a, ok := e.(dgo.Array)
+	if !ok || a.Len() == 0 {
+		panic(invalidKeyword(path, `enum`))
+	}
+	if a.Len() == 1 {
+		return a.Get(0).Type()
+	}
+	ts := make([]interface{}, a.Len())
+	a.EachWithIndex(func(v dgo.Value, i int) { ts[i] = v.Type() })
+	return tf.AnyOf(ts...)
+}
+
+// typed returns the type described by the "type" keyword and the keywords that constrain values of that type. When
+// "type" is missing, it is inferred from the presence of such keywords. The returned type is nil when no such
+// keywords exist.
+func (im *importer) typed(path string, m dgo.Map) dgo.Type {
+	var names []string
+	switch t := m.Get(`type`).(type) {
+	case nil:
+		names = impliedTypes(m)
+	case dgo.String:
+		names = []string{t.GoString()}
+	case dgo.Array:
+		t.Each(func(n dgo.Value) {
+			s, ok := n.(dgo.String)
+			if !ok {
+				panic(invalidKeyword(path, `type`))
+			}
+			names = append(names, s.GoString())
+		})
+	default:
+		panic(invalidKeyword(path, `type`))
+	}
+	ts := make([]interface{}, len(names))
+	for i, n := range names {
+		ts[i] = im.typeNamed(path, n, m)
+	}
+	switch len(ts) {
+	case 0:
+		return nil
+	case 1:
+		return ts[0].(dgo.Type)
+	default:
+		return tf.AnyOf(ts...)
+	}
+}
+
+func impliedTypes(m dgo.Map) []string {
+	var kinds [kwObject + 1]bool
+	m.EachKey(func(k dgo.Value) { kinds[keywords[k.String()]] = true })
+	var names []string
+	for kind, name := range []string{kwNumber: `number`, kwString: `string`, kwArray: `array`, kwObject: `object`} {
+		if name != `` && kinds[kind] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (im *importer) typeNamed(path, name string, m dgo.Map) dgo.Type {
+	switch name {
+	case `null`:
+		return typ.Nil
+	case `boolean`:
+		return typ.Boolean
+	case `integer`:
+		return integer(path, m)
+	case `number`:
+		return number(path, m)
+	case `string`:
+		return str(path, m)
+	case `array`:
+		return im.array(path, m)
+	case `object`:
+		return im.object(path, m)
+	}
+	panic(invalidKeyword(path, `type`))
+}
+
+func float(path string, m dgo.Map, key string) (float64, bool) {
+	switch v := m.Get(key).(type) {
+	case nil:
+		return 0, false
+	case dgo.Integer:
+		return float64(v.GoInt()), true
+	case dgo.Float:
+		return v.GoFloat(), true
+	}
+	panic(invalidKeyword(path, key))
+}
+
+func clampInt(f float64) int64 {
+	switch {
+	case f <= math.MinInt64:
+		return math.MinInt64
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	}
+	return int64(f)
+}
+
+func integer(path string, m dgo.Map) dgo.Type {
+	min := int64(math.MinInt64)
+	max := int64(math.MaxInt64)
+	inclusive := true
+	if f, ok := float(path, m, `minimum`); ok {
+		min = clampInt(math.Ceil(f))
+	}
+	if f, ok := float(path, m, `exclusiveMinimum`); ok {
+		if c := clampInt(math.Floor(f) + 1); c > min {
+			min = c
+		}
+	}
+	if f, ok := float(path, m, `maximum`); ok {
+		max = clampInt(math.Floor(f))
+	}
+	if f, ok := float(path, m, `exclusiveMaximum`); ok {
+		if c := clampInt(math.Ceil(f)); c <= max {
+			max = c
+			inclusive = false
+		}
+	}
+	if min == math.MinInt64 && max == math.MaxInt64 {
+		return typ.Integer
+	}
+	return tf.Integer(min, max, inclusive)
+}
+
+func number(path string, m dgo.Map) dgo.Type {
+	if m.Get(`exclusiveMinimum`) != nil {
+		panic(unsupportedKeyword(path, `exclusiveMinimum`))
+	}
+	min := -math.MaxFloat64
+	max := math.MaxFloat64
+	inclusive := true
+	if f, ok := float(path, m, `minimum`); ok {
+		min = f
+	}
+	if f, ok := float(path, m, `maximum`); ok {
+		max = f
+	}
+	if f, ok := float(path, m, `exclusiveMaximum`); ok && f <= max {
+		max = f
+		inclusive = false
+	}
+	var ft dgo.Type
+	if min == -math.MaxFloat64 && max == math.MaxFloat64 {
+		ft = typ.Float
+	} else {
+		ft = tf.Float(min, max, inclusive)
+	}
+	if c := math.Ceil(min); c < max || c == max && inclusive {
+		return tf.AnyOf(integer(path, m), ft)
+	}
+	return ft
+}
+
+// size returns the value of the given size keyword or the given default if the keyword is missing
+func size(path string, m dgo.Map, key string, dflt int) int {
+	switch v := m.Get(key).(type) {
+	case nil:
+		return dflt
+	case dgo.Integer:
+		if i := v.GoInt(); i >= 0 {
+			return int(i)
+		}
+	}
+	panic(invalidKeyword(path, key))
+}
+
+func pattern(path, key, s string) dgo.Type {
+	rx, err := regexp.Compile(s)
+	if err != nil {
+		panic(invalidKeyword(path, key))
+	}
+	return tf.Pattern(rx)
+}
+
+func stringValue(path string, m dgo.Map, key string) (string, bool) {
+	switch v := m.Get(key).(type) {
+	case nil:
+		return ``, false
+	case dgo.String:
+		return v.GoString(), true
+	}
+	panic(invalidKeyword(path, key))
+}
+
+func str(path string, m dgo.Map) dgo.Type {
+	var parts []interface{}
+	min := size(path, m, `minLength`, 0)
+	max := size(path, m, `maxLength`, math.MaxInt64)
+	if min > 0 || max != math.MaxInt64 {
+		parts = append(parts, tf.String(min, max))
+	}
+	if s, ok := stringValue(path, m, `pattern`); ok {
+		parts = append(parts, pattern(path, `pattern`, s))
+	}
+	if s, ok := stringValue(path, m, `format`); ok {
+		rx, ok := formats[s]
+		if !ok {
+			panic(fmt.Errorf(`unsupported JSON Schema format "%s" at %s`, s, path))
+		}
+		parts = append(parts, pattern(path, `format`, rx))
+	}
+	if s, ok := stringValue(path, m, `contentEncoding`); ok {
+		rx, ok := encodings[s]
+		if !ok {
+			panic(fmt.Errorf(`unsupported JSON Schema content encoding "%s" at %s`, s, path))
+		}
+		parts = append(parts, pattern(path, `contentEncoding`, rx))
+	}
+	switch len(parts) {
+	case 0:
+		return typ.String
+	case 1:
+		return parts[0].(dgo.Type)
+	default:
+		return tf.AllOf(parts...)
+	}
+}
+
+// array returns an array type or, when "prefixItems" is present, a tuple type. Since dgo tuples have a fixed
+// number of elements, prefix items that are optional result in one tuple for each possible length.
+func (im *importer) array(path string, m dgo.Map) dgo.Type {
+	if u, ok := m.Get(`uniqueItems`).(dgo.Boolean); ok && u.GoBool() {
+		panic(unsupportedKeyword(path, `uniqueItems`))
+	}
+	min := size(path, m, `minItems`, 0)
+	max := size(path, m, `maxItems`, math.MaxInt64)
+	var items dgo.Type = typ.Any
+	closed := false
+	if v := m.Get(`items`); v != nil {
+		if isFalse(v) {
+			closed = true
+		} else {
+			items = im.schema(childPath(path, `items`), v)
+		}
+	}
+	pv := m.Get(`prefixItems`)
+	if pv == nil {
+		if closed {
+			max = 0
+		}
+		if min == 0 && max == math.MaxInt64 {
+			return tf.Array(items)
+		}
+		return tf.Array(items, min, max)
+	}
+	ps := im.schemas(childPath(path, `prefixItems`), pv)
+	n := len(ps)
+	var alts []interface{}
+	for l := min; l < n && l <= max; l++ {
+		alts = append(alts, tf.Tuple(ps[:l]...))
+	}
+	if n >= min && n <= max {
+		switch {
+		case closed:
+			alts = append(alts, tf.Tuple(ps...))
+		case max != math.MaxInt64:
+			alts = append(alts, tf.AllOf(tf.VariadicTuple(append(ps, items)...), tf.Array(typ.Any, n, max)))
+		default:
+			alts = append(alts, tf.VariadicTuple(append(ps, items)...))
+		}
+	} else if !closed && min > n {
+		alts = append(alts, tf.AllOf(tf.VariadicTuple(append(ps, items)...), tf.Array(typ.Any, min, max)))
+	}
+	switch len(alts) {
+	case 0:
+		return tf.Not(typ.Any)
+	case 1:
+		return alts[0].(dgo.Type)
+	default:
+		return tf.AnyOf(alts...)
+	}
+}
+
+func (im *importer) object(path string, m dgo.Map) dgo.Type {
+	props, ok := m.Get(`properties`).(dgo.Map)
+	if !ok && m.Get(`properties`) != nil {
+		panic(invalidKeyword(path, `properties`))
+	}
+	required := stringSet(path, m, `required`)
+	additional := m.Get(`additionalProperties`)
+	if props != nil || required.Len() > 0 || isFalse(additional) && m.Get(`patternProperties`) == nil {
+		return im.structMap(path, m, props, required)
+	}
+	return im.mapType(path, m)
+}
+
+func isFalse(v dgo.Value) bool {
+	b, ok := v.(dgo.Boolean)
+	return ok && !b.GoBool()
+}
+
+func stringSet(path string, m dgo.Map, key string) dgo.Array {
+	switch v := m.Get(key).(type) {
+	case nil:
+		return vf.Values()
+	case dgo.Array:
+		if v.All(func(e dgo.Value) bool { _, ok := e.(dgo.String); return ok }) {
+			return v
+		}
+	}
+	panic(invalidKeyword(path, key))
+}
+
+func (im *importer) structMap(path string, m dgo.Map, props dgo.Map, required dgo.Array) dgo.Type {
+	for _, k := range []string{`patternProperties`, `propertyNames`, `minProperties`, `maxProperties`} {
+		if m.Get(k) != nil {
+			panic(fmt.Errorf(`unsupported combination of JSON Schema keywords "properties" and "%s" at %s`, k, path))
+		}
+	}
+	additional := true
+	switch a := m.Get(`additionalProperties`).(type) {
+	case nil:
+	case dgo.Boolean:
+		additional = a.GoBool()
+	default:
+		panic(fmt.Errorf(`unsupported combination of JSON Schema keywords "properties" and "additionalProperties" at %s`, path))
+	}
+	var entries []dgo.StructMapEntry
+	if props != nil {
+		pp := childPath(path, `properties`)
+		props.EachEntry(func(e dgo.MapEntry) {
+			entries = append(entries, im.entry(childPath(pp, e.Key().String()), e.Key(), e.Value(), required.IndexOf(e.Key()) >= 0))
+		})
+	}
+	required.Each(func(k dgo.Value) {
+		if props == nil || props.Get(k) == nil {
+			entries = append(entries, tf.StructMapEntry(k, typ.Any, true))
+		}
+	})
+	return tf.StructMap(additional, entries...)
+}
+
+func (im *importer) entry(path string, key, v dgo.Value, required bool) dgo.StructMapEntry {
+	t := im.schema(path, v)
+	var e dgo.StructMapEntry
+	sm, _ := v.(dgo.Map)
+	if d := get(sm, `default`); d != nil && !required {
+		e = tf.StructMapEntryWithDefault(key, t, d)
+	} else {
+		e = tf.StructMapEntry(key, t, required)
+	}
+	if a := annotations(sm); a != nil {
+		e = tf.AnnotatedStructMapEntry(e, a)
+	}
+	return e
+}
+
+func (im *importer) mapType(path string, m dgo.Map) dgo.Type {
+	var kt dgo.Type = typ.String
+	if pn := m.Get(`propertyNames`); pn != nil {
+		kt = im.schema(childPath(path, `propertyNames`), pn)
+	}
+	var vt dgo.Type = typ.Any
+	if pp := m.Get(`patternProperties`); pp != nil {
+		ppm, ok := pp.(dgo.Map)
+		if !ok || ppm.Len() != 1 || !isFalse(m.Get(`additionalProperties`)) || m.Get(`propertyNames`) != nil {
+			panic(fmt.Errorf(`unsupported use of JSON Schema keyword "patternProperties" at %s. Only one pattern `+
+				`combined with "additionalProperties": false is supported`, path))
+		}
+		ppm.EachEntry(func(e dgo.MapEntry) {
+			kt = pattern(path, `patternProperties`, e.Key().String())
+			vt = im.schema(childPath(childPath(path, `patternProperties`), e.Key().String()), e.Value())
+		})
+	} else if a := m.Get(`additionalProperties`); a != nil {
+		vt = im.schema(childPath(path, `additionalProperties`), a)
+	}
+	min := size(path, m, `minProperties`, 0)
+	max := size(path, m, `maxProperties`, math.MaxInt64)
+	return tf.Map(kt, vt, min, max)
+}
+
+// annotations returns the well known annotations of the given schema or nil if it has none. A "$comment" is used
+// as the deprecation message of a deprecated schema.
+func annotations(m dgo.Map) dgo.Map {
+	a := vf.MapWithCapacity(3)
+	if d, ok := get(m, `description`).(dgo.String); ok {
+		a.Put(typ.DescriptionKey, d)
+	}
+	if x, ok := get(m, `examples`).(dgo.Array); ok {
+		a.Put(typ.ExamplesKey, x)
+	}
+	if d, ok := get(m, `deprecated`).(dgo.Boolean); ok && d.GoBool() {
+		if c, ok := get(m, `$comment`).(dgo.String); ok {
+			a.Put(typ.DeprecatedKey, c)
+		} else {
+			a.Put(typ.DeprecatedKey, true)
+		}
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	return a
+}
+
+func get(m dgo.Map, key string) dgo.Value {
+	if m == nil {
+		return nil
+	}
+	return m.Get(key)
+}
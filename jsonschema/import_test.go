@@ -0,0 +1,239 @@
+package jsonschema_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/jsonschema"
+	"github.com/lyraproj/dgo/streamer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleImport() {
+	doc := streamer.UnmarshalJSON([]byte(`{
+    "type": "object",
+    "properties": {
+      "name": {"type": "string", "minLength": 1},
+      "tags": {"type": "array", "items": {"enum": ["a", "b"]}}
+    },
+    "required": ["name"]
+  }`), nil)
+	t, _, err := jsonschema.Import(doc)
+	if err == nil {
+		fmt.Println(t)
+		fmt.Println(t.Instance(vf.Map(`name`, `x`, `tags`, vf.Strings(`a`))))
+	}
+	// Output:
+	// {"name":string[1],"tags"?:[]("a"|"b"),...}
+	// true
+}
+
+func importJSON(t *testing.T, s string) dgo.Type {
+	t.Helper()
+	tp, _, err := jsonschema.Import(streamer.UnmarshalJSON([]byte(s), nil))
+	require.Ok(t, err)
+	return tp
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		s string
+		t dgo.Type
+	}{
+		{`true`, typ.Any},
+		{`{}`, typ.Any},
+		{`false`, tf.Not(typ.Any)},
+		{`{"type":"null"}`, typ.Nil},
+		{`{"type":"boolean"}`, typ.Boolean},
+		{`{"type":"integer"}`, typ.Integer},
+		{`{"type":"integer","minimum":1,"maximum":10}`, tf.Integer(1, 10, true)},
+		{`{"type":"integer","minimum":0.5,"maximum":9.5}`, tf.Integer(1, 9, true)},
+		{`{"type":"integer","exclusiveMinimum":0,"exclusiveMaximum":10}`, tf.Integer(1, 10, false)},
+		{`{"type":"number"}`, tf.AnyOf(typ.Integer, typ.Float)},
+		{`{"type":"number","minimum":0.5,"exclusiveMaximum":2}`, tf.AnyOf(tf.Integer(1, 2, false), tf.Float(0.5, 2, false))},
+		{`{"type":"number","minimum":0.5,"maximum":0.7}`, tf.Float(0.5, 0.7, true)},
+		{`{"minimum":3}`, tf.AnyOf(tf.Integer(3, math.MaxInt64, true), tf.Float(3, math.MaxFloat64, true))},
+		{`{"type":"string"}`, typ.String},
+		{`{"type":"string","minLength":1,"maxLength":5}`, tf.String(1, 5)},
+		{`{"type":"string","pattern":"^a"}`, tf.ParseType(`/^a/`)},
+		{`{"type":"string","format":"date"}`, tf.ParseType(`/^\d{4}-\d{2}-\d{2}$/`)},
+		{`{"type":"string","minLength":1,"pattern":"^a"}`, tf.AllOf(tf.String(1), tf.ParseType(`/^a/`))},
+		{`{"type":["string","null"]}`, tf.AnyOf(typ.String, typ.Nil)},
+		{`{"const":3}`, vf.Integer(3).Type()},
+		{`{"const":null}`, typ.Nil},
+		{`{"enum":["a","b"]}`, tf.Enum(`a`, `b`)},
+		{`{"enum":["a"]}`, vf.String(`a`).Type()},
+		{`{"type":"array"}`, tf.Array(typ.Any)},
+		{`{"type":"array","items":{"type":"string"},"maxItems":3}`, tf.Array(typ.String, 0, 3)},
+		{`{"type":"array","items":false}`, tf.Array(typ.Any, 0, 0)},
+		{`{"type":"array","prefixItems":[{"type":"string"},{"type":"integer"}],"minItems":2,"items":false}`,
+			tf.Tuple(typ.String, typ.Integer)},
+		{`{"type":"array","prefixItems":[{"type":"string"}],"minItems":1,"items":{"type":"integer"}}`,
+			tf.VariadicTuple(typ.String, typ.Integer)},
+		{`{"type":"array","prefixItems":[{"type":"string"}],"items":false}`,
+			tf.AnyOf(tf.Tuple(), tf.Tuple(typ.String))},
+		{`{"type":"array","prefixItems":[{"type":"string"}],"minItems":1,"maxItems":3}`,
+			tf.AllOf(tf.VariadicTuple(typ.String, typ.Any), tf.Array(typ.Any, 1, 3))},
+		{`{"type":"object"}`, tf.Map(typ.String, typ.Any)},
+		{`{"type":"object","additionalProperties":{"type":"integer"},"minProperties":1}`,
+			tf.Map(typ.String, typ.Integer, 1, math.MaxInt64)},
+		{`{"type":"object","patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`,
+			tf.ParseType(`map[/^x-/]string`)},
+		{`{"type":"object","propertyNames":{"maxLength":3}}`, tf.Map(tf.String(0, 3), typ.Any)},
+		{`{"type":"object","additionalProperties":false}`, tf.StructMap(false)},
+		{`{"required":["a"]}`, tf.StructMap(true, tf.StructMapEntry(`a`, typ.Any, true))},
+		{`{"type":"object","properties":{"a":{"type":"integer"},"b":{"type":"string","default":"x"}},` +
+			`"required":["a"],"additionalProperties":false}`,
+			tf.StructMap(false,
+				tf.StructMapEntry(`a`, typ.Integer, true),
+				tf.StructMapEntryWithDefault(`b`, typ.String, `x`))},
+		{`{"allOf":[{"type":"string"},{"minLength":2}]}`, tf.AllOf(typ.String, tf.String(2))},
+		{`{"anyOf":[{"type":"string"},{"type":"integer"}]}`, tf.AnyOf(typ.String, typ.Integer)},
+		{`{"oneOf":[{"type":"string"},{"pattern":"x"}]}`, tf.OneOf(typ.String, tf.ParseType(`/x/`))},
+		{`{"not":{"type":"null"}}`, tf.Not(typ.Nil)},
+		{`{"type":"string","not":{"const":""}}`, tf.AllOf(typ.String, tf.Not(vf.String(``).Type()))},
+		{`{"description":"any value","$comment":"ignored","readOnly":true}`, typ.Any},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.t, importJSON(t, tt.s))
+	}
+}
+
+func TestImport_formats(t *testing.T) {
+	tp := importJSON(t, `{"type":"string","format":"date-time"}`)
+	require.Instance(t, tp, `2019-10-06T07:15:00Z`)
+	require.Instance(t, tp, `2019-10-06T07:15:00.123+02:00`)
+	require.NotInstance(t, tp, `2019-10-06`)
+
+	tp = importJSON(t, `{"type":"string","format":"uuid"}`)
+	require.Instance(t, tp, `6b7f5d2a-93b1-4c6e-8a0e-3c8e5f1f9a21`)
+	require.NotInstance(t, tp, `6b7f5d2a`)
+
+	tp = importJSON(t, `{"type":"string","contentEncoding":"base64"}`)
+	require.Instance(t, tp, `AQID`)
+	require.NotInstance(t, tp, `A-B`)
+}
+
+func TestImport_defs(t *testing.T) {
+	tp, am, err := jsonschema.Import(streamer.UnmarshalJSON([]byte(`{
+    "$schema": "https://json-schema.org/draft/2020-12/schema",
+    "type": "array",
+    "items": {"$ref": "#/$defs/node"},
+    "$defs": {
+      "node": {
+        "type": "object",
+        "description": "a node in a tree",
+        "properties": {
+          "value": {"$ref": "#/$defs/value"},
+          "children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+        },
+        "required": ["value"],
+        "additionalProperties": false
+      },
+      "value": {"type": "integer", "minimum": 0}
+    }
+  }`), nil))
+	require.Ok(t, err)
+	node := am.GetType(vf.String(`node`))
+	require.Equal(t, `node`, am.GetName(node))
+	require.Equal(t, `{"value":0..,"children"?:[]<recursive self reference to struct type>}`, node.String())
+	require.Equal(t, tf.Integer(0, math.MaxInt64, true), am.GetType(vf.String(`value`)))
+	require.Equal(t, `a node in a tree`, typ.Description(am.Annotations(vf.String(`node`))))
+	require.Equal(t, tf.Array(node), tp)
+
+	require.Instance(t, tp, vf.Values(vf.Map(`value`, 1, `children`, vf.Values(vf.Map(`value`, 2)))))
+	require.NotInstance(t, tp, vf.Values(vf.Map(`value`, 1, `children`, vf.Values(vf.Map(`value`, -2)))))
+}
+
+func TestImport_definitions(t *testing.T) {
+	tp, am, err := jsonschema.Import(streamer.UnmarshalJSON([]byte(`{
+    "$ref": "#/definitions/a~1b",
+    "definitions": {"a/b": {"type": "string"}}
+  }`), nil))
+	require.Ok(t, err)
+	require.Equal(t, typ.String, tp)
+	require.Equal(t, typ.String, am.GetType(vf.String(`a/b`)))
+}
+
+func TestImport_entryAnnotations(t *testing.T) {
+	tp := importJSON(t, `{"properties":{"a":{"type":"string","description":"the a","examples":["x"],`+
+		`"deprecated":true,"$comment":"use b"}}}`)
+	e := tp.(dgo.StructMapType).Get(`a`)
+	require.Equal(t, vf.Map(`description`, `the a`, `examples`, vf.Strings(`x`), `deprecated`, `use b`), e.Annotations())
+}
+
+func TestImport_refDefault(t *testing.T) {
+	tp := importJSON(t, `{"type":"object","properties":{"port":{"$ref":"#/$defs/port","default":8080}},`+
+		`"$defs":{"port":{"type":"integer","minimum":1,"maximum":65535}}}`)
+	e := tp.(dgo.StructMapType).Get(`port`)
+	require.Equal(t, tf.Integer(1, 65535, true), e.Value())
+	require.Equal(t, 8080, e.Default())
+
+	_, _, err := jsonschema.Import(streamer.UnmarshalJSON([]byte(`{"type":"object",`+
+		`"properties":{"port":{"$ref":"#/$defs/port","default":0}},"$defs":{"port":{"type":"integer","minimum":1}}}`), nil))
+	require.NotOk(t, `the value 0 cannot be assigned`, err)
+}
+
+func TestImport_number(t *testing.T) {
+	tp := importJSON(t, `{"type":"object","properties":{"n":{"type":"number","maximum":10}}}`)
+	for _, s := range []string{`{"n":3}`, `{"n":3.5}`, `{"n":-2}`} {
+		require.Instance(t, tp, streamer.UnmarshalJSON([]byte(s), nil))
+	}
+	require.NotInstance(t, tp, streamer.UnmarshalJSON([]byte(`{"n":11}`), nil))
+	require.NotInstance(t, tp, streamer.UnmarshalJSON([]byte(`{"n":10.5}`), nil))
+}
+
+func TestImport_roundTrip(t *testing.T) {
+	for _, s := range []string{
+		`{name:string[1],port?:1..65535=8080,tags:[]("a"|"b"),...}`,
+		`{string,...int}`,
+		`map[/^x-/](1|0.5..1.5)`,
+		`int|float`,
+		`!nil`,
+	} {
+		tp := tf.ParseType(s)
+		doc, err := jsonschema.Export(tp, nil)
+		require.Ok(t, err)
+		it, _, err := jsonschema.Import(doc)
+		require.Ok(t, err)
+		require.Equal(t, tp, it)
+	}
+}
+
+func TestImport_fail(t *testing.T) {
+	tests := []struct {
+		s string
+		e string
+	}{
+		{`{"type":"integer","multipleOf":2}`, `unsupported JSON Schema keyword "multipleOf" at #`},
+		{`{"properties":{"a":{"if":{}}}}`, `unsupported JSON Schema keyword "if" at #/properties/a`},
+		{`{"items":{"$defs":{}}}`, `unsupported JSON Schema keyword "\$defs" at #/items`},
+		{`{"type":"number","exclusiveMinimum":0}`, `unsupported JSON Schema keyword "exclusiveMinimum" at #`},
+		{`{"type":"array","uniqueItems":true}`, `unsupported JSON Schema keyword "uniqueItems" at #`},
+		{`{"$ref":"#"}`, `unsupported JSON Schema reference "#" at #`},
+		{`{"$ref":"other.json#/$defs/a"}`, `unsupported JSON Schema reference`},
+		{`{"$ref":"#/$defs/a"}`, `unresolved JSON Schema reference "#/\$defs/a" at #`},
+		{`{"type":"string","format":"hostname"}`, `unsupported JSON Schema format "hostname" at #`},
+		{`{"type":"string","contentEncoding":"base32"}`, `unsupported JSON Schema content encoding "base32" at #`},
+		{`{"properties":{},"minProperties":1}`, `unsupported combination of JSON Schema keywords "properties" and "minProperties"`},
+		{`{"properties":{},"additionalProperties":{}}`, `unsupported combination of JSON Schema keywords "properties" and "additionalProp`},
+		{`{"patternProperties":{"a":{},"b":{}},"additionalProperties":false}`, `unsupported use of JSON Schema keyword "patternProperties"`},
+		{`{"type":"strin"}`, `invalid value for JSON Schema keyword "type" at #`},
+		{`{"type":"string","minLength":-1}`, `invalid value for JSON Schema keyword "minLength" at #`},
+		{`{"type":"string","pattern":"("}`, `invalid value for JSON Schema keyword "pattern" at #`},
+		{`{"type":"integer","minimum":"0"}`, `invalid value for JSON Schema keyword "minimum" at #`},
+		{`{"enum":[]}`, `invalid value for JSON Schema keyword "enum" at #`},
+		{`{"required":[1]}`, `invalid value for JSON Schema keyword "required" at #`},
+		{`{"anyOf":[]}`, `expected a non empty array of JSON Schemas at #/anyOf`},
+		{`{"not":3}`, `expected a JSON Schema at #/not, got 3`},
+	}
+	for _, tt := range tests {
+		_, _, err := jsonschema.Import(streamer.UnmarshalJSON([]byte(tt.s), nil))
+		require.NotOk(t, tt.e, err)
+	}
+}
@@ -0,0 +1,391 @@
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/loader"
+)
+
+// ManifestName is the name of the file in a bundle that contains the SHA-256 hashes of all other files in the
+// bundle. Each line of the manifest contains the hex encoded hash of a file followed by two spaces and the slash
+// separated path of the file, which is the format used by sha256sum.
+const ManifestName = `MANIFEST`
+
+// Bundle is the content of a bundle archive, i.e. a zip or tar archive that contains type files and data files
+// organized in the same way as the files in a directory that is used by NewLoader.
+type Bundle struct {
+	fileName string
+	files    map[string][]byte
+}
+
+// ReadBundle reads the bundle archive with the given name. The format of the archive is determined by the extension
+// of the name, which must be one of .zip, .tar, .tar.gz, or .tgz.
+//
+// The bundle must contain a manifest. The hash of each file is verified against it and an error is raised if the
+// manifest is missing, if a file is missing from the manifest, is listed but missing from the bundle, or if its hash
+// doesn't match. Use ReadUnverifiedBundle to read a bundle without a manifest.
+func ReadBundle(fileName string) *Bundle {
+	b := readBundle(fileName)
+	m, ok := b.files[ManifestName]
+	if !ok {
+		panic(fmt.Errorf(`bundle %s: the manifest %s is missing`, fileName, ManifestName))
+	}
+	delete(b.files, ManifestName)
+	b.verify(m)
+	return b
+}
+
+// ReadUnverifiedBundle reads the bundle archive with the given name in the same way as ReadBundle, except that the
+// files are not verified. A manifest is not required and it is ignored when present.
+func ReadUnverifiedBundle(fileName string) *Bundle {
+	b := readBundle(fileName)
+	delete(b.files, ManifestName)
+	return b
+}
+
+func readBundle(fileName string) *Bundle {
+	var files map[string][]byte
+	switch bundleFormat(fileName) {
+	case `zip`:
+		files = readZip(fileName)
+	case `tar`:
+		files = readTar(fileName, false)
+	default:
+		files = readTar(fileName, true)
+	}
+	return &Bundle{fileName: fileName, files: files}
+}
+
+// NewBundleLoader returns a Loader for the root namespace that finds its entries in the root of the bundle archive
+// with the given name and its namespaces in the directories of that bundle. The bundle is read using ReadBundle, so
+// it must contain a manifest.
+func NewBundleLoader(fileName string) dgo.Loader {
+	return ReadBundle(fileName).NewLoader()
+}
+
+// NewLoader returns a Loader for the root namespace that finds its entries in the root of the bundle and its
+// namespaces in the directories of the bundle. The loader uses a Lister for the bundle to discover entries and
+// namespaces when it is walked.
+func (b *Bundle) NewLoader() dgo.Loader {
+	return loader.NewListing(nil, ``, nil, b.Finder(``), b.NsCreator(``), b.Lister(``))
+}
+
+// Finder returns a Finder that finds entries in the given slash separated directory of the bundle. The entries are
+// found and decoded in the same way as the entries found by the Finder function, and errors are reported with the
+// name of the bundle followed by "!/" and the path of the file in the bundle.
+func (b *Bundle) Finder(dir string) dgo.Finder {
+	return func(_ dgo.Loader, name string) interface{} {
+		if !validName(name) {
+			return nil
+		}
+		for _, ext := range Extensions {
+			path := bundlePath(dir, name+ext)
+			if content, ok := b.files[path]; ok {
+				return decode(name, b.fileName+`!/`+path, content)
+			}
+		}
+		return nil
+	}
+}
+
+// NsCreator returns an NsCreator that creates a namespace for each directory in the given slash separated directory
+// of the bundle. The namespace uses a Finder and an NsCreator for its directory.
+func (b *Bundle) NsCreator(dir string) dgo.NsCreator {
+	return func(l dgo.Loader, name string) dgo.Loader {
+		if !validName(name) {
+			return nil
+		}
+		sub := bundlePath(dir, name)
+		prefix := sub + `/`
+		for path := range b.files {
+			if strings.HasPrefix(path, prefix) {
+				return loader.NewListing(l, name, nil, b.Finder(sub), b.NsCreator(sub), b.Lister(sub))
+			}
+		}
+		return nil
+	}
+}
+
+// Lister returns a Lister that lists the names of the entries that a Finder for the given slash separated directory
+// of the bundle can find and the names of the namespaces that an NsCreator for that directory can create
+func (b *Bundle) Lister(dir string) dgo.Lister {
+	return func(_ dgo.Loader) (entries, namespaces []string) {
+		prefix := bundlePath(dir, ``)
+		for path := range b.files {
+			if !strings.HasPrefix(path, prefix) {
+				continue
+			}
+			rest := path[len(prefix):]
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				if validName(rest[:i]) {
+					namespaces = append(namespaces, rest[:i])
+				}
+			} else if n, ok := entryName(rest); ok {
+				entries = append(entries, n)
+			}
+		}
+		return uniqueNames(entries), uniqueNames(namespaces)
+	}
+}
+
+func (b *Bundle) verify(manifest []byte) {
+	listed := make(map[string]bool, len(b.files))
+	s := bufio.NewScanner(bytes.NewReader(manifest))
+	for s.Scan() {
+		line := s.Text()
+		if line == `` {
+			continue
+		}
+		parts := strings.SplitN(line, `  `, 2)
+		if len(parts) != 2 {
+			panic(fmt.Errorf(`bundle %s: invalid manifest line %q`, b.fileName, line))
+		}
+		path := parts[1]
+		content, ok := b.files[path]
+		if !ok {
+			panic(fmt.Errorf(`bundle %s: file %s is listed in the manifest but is missing`, b.fileName, path))
+		}
+		if hash(content) != parts[0] {
+			panic(fmt.Errorf(`bundle %s: hash mismatch for file %s`, b.fileName, path))
+		}
+		listed[path] = true
+	}
+	for path := range b.files {
+		if !listed[path] {
+			panic(fmt.Errorf(`bundle %s: file %s is not listed in the manifest`, b.fileName, path))
+		}
+	}
+}
+
+// WriteBundle writes a bundle archive with the given name that contains the type files and data files in the given
+// directory and in its sub directories, i.e. the files that a loader returned by NewLoader for that directory can
+// find entries in, and a manifest with the hashes of those files. The format of the archive is determined by the
+// extension of the name in the same way as for ReadBundle.
+//
+// The archive is written to a temporary file in the same directory which is then renamed to the given name, so an
+// existing file with that name is replaced only when the archive is complete. The temporary file is removed when an
+// error is raised.
+func WriteBundle(fileName, dir string) {
+	paths := bundleFiles(dir)
+	format := bundleFormat(fileName)
+	f, err := ioutil.TempFile(filepath.Dir(fileName), `.`+filepath.Base(fileName)+`.*`)
+	if err != nil {
+		panic(err)
+	}
+	done := false
+	defer func() {
+		if !done {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	writeArchive(f, format, dir, paths)
+	if err = f.Chmod(0644); err != nil {
+		panic(err)
+	}
+	if err = f.Close(); err != nil {
+		panic(err)
+	}
+	done = true
+	if err = os.Rename(f.Name(), fileName); err != nil {
+		_ = os.Remove(f.Name())
+		panic(err)
+	}
+}
+
+// writeArchive writes an archive with the given format that contains the files with the given slash separated paths
+// in the given directory, and a manifest with the hashes of those files, to the given writer
+func writeArchive(f io.Writer, format, dir string, paths []string) {
+	var manifest bytes.Buffer
+	var add func(path string, content []byte)
+	var closeArchive func() error
+	switch format {
+	case `zip`:
+		zw := zip.NewWriter(f)
+		add = func(path string, content []byte) {
+			w, err := zw.Create(path)
+			if err == nil {
+				_, err = w.Write(content)
+			}
+			if err != nil {
+				panic(err)
+			}
+		}
+		closeArchive = zw.Close
+	default:
+		var w io.Writer = f
+		var gw *gzip.Writer
+		if format == `tgz` {
+			gw = gzip.NewWriter(f)
+			w = gw
+		}
+		tw := tar.NewWriter(w)
+		add = func(path string, content []byte) {
+			err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: path, Mode: 0644, Size: int64(len(content))})
+			if err == nil {
+				_, err = tw.Write(content)
+			}
+			if err != nil {
+				panic(err)
+			}
+		}
+		closeArchive = func() error {
+			err := tw.Close()
+			if err == nil && gw != nil {
+				err = gw.Close()
+			}
+			return err
+		}
+	}
+
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			panic(err)
+		}
+		add(path, content)
+		manifest.WriteString(hash(content) + `  ` + path + "\n")
+	}
+	add(ManifestName, manifest.Bytes())
+	if err := closeArchive(); err != nil {
+		panic(err)
+	}
+}
+
+// bundleFiles returns the sorted slash separated paths of the type files and data files in the given directory and
+// in its sub directories
+func bundleFiles(dir string) []string {
+	var paths []string
+	err := filepath.Walk(dir, func(fn string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fn == dir {
+			return nil
+		}
+		name := fi.Name()
+		if fi.IsDir() {
+			if !validName(name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := entryName(name); ok {
+			rel, err := filepath.Rel(dir, fn)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// bundleFormat returns the format of the bundle archive with the given name, one of "zip", "tar", or "tgz"
+func bundleFormat(fileName string) string {
+	switch {
+	case strings.HasSuffix(fileName, `.zip`):
+		return `zip`
+	case strings.HasSuffix(fileName, `.tar`):
+		return `tar`
+	case strings.HasSuffix(fileName, `.tar.gz`), strings.HasSuffix(fileName, `.tgz`):
+		return `tgz`
+	}
+	panic(fmt.Errorf(`bundle %s: unknown archive format, expected extension .zip, .tar, .tar.gz, or .tgz`, fileName))
+}
+
+func bundlePath(dir, name string) string {
+	if dir == `` {
+		return name
+	}
+	return dir + `/` + name
+}
+
+func hash(content []byte) string {
+	h := sha256.Sum256(content)
+	return hex.EncodeToString(h[:])
+}
+
+func readZip(fileName string) map[string][]byte {
+	zr, err := zip.OpenReader(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+	files := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			panic(err)
+		}
+		content, err := ioutil.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			panic(err)
+		}
+		files[strings.TrimPrefix(f.Name, `./`)] = content
+	}
+	return files
+}
+
+func readTar(fileName string, gzipped bool) map[string][]byte {
+	f, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var r io.Reader = f
+	if gzipped {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			panic(fmt.Errorf(`bundle %s: %s`, fileName, err.Error()))
+		}
+		r = gr
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(fmt.Errorf(`bundle %s: %s`, fileName, err.Error()))
+		}
+		if !h.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			panic(fmt.Errorf(`bundle %s: %s`, fileName, err.Error()))
+		}
+		files[strings.TrimPrefix(h.Name, `./`)] = content
+	}
+	return files
+}
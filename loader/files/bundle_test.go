@@ -0,0 +1,184 @@
+package files_test
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/loader/files"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir(``, `bundle`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+// writeZip writes a zip archive with the given files, given as alternating paths and contents
+func writeZip(t *testing.T, fileName string, pathsAndContents ...string) {
+	t.Helper()
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zw := zip.NewWriter(f)
+	for i := 0; i < len(pathsAndContents); i += 2 {
+		w, err := zw.Create(pathsAndContents[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(pathsAndContents[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sha(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+func TestNewBundleLoader(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	for _, ext := range []string{`.zip`, `.tar`, `.tar.gz`, `.tgz`} {
+		bundle := filepath.Join(dir, `types`+ext)
+		files.WriteBundle(bundle, `testdata`)
+		l := files.NewBundleLoader(bundle)
+		require.Equal(t, tf.Integer(1, 65535, true), l.Load(`port`))
+		require.Equal(t, vf.Map(`name`, `server`, `ports`, vf.Values(80, 443)), l.Load(`config`))
+		require.Equal(t, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), l.Load(`common/id`))
+		require.Equal(t, typ.String, l.Load(`common/both`))
+		require.Equal(t, `/common`, l.Namespace(`common`).AbsoluteName())
+		require.True(t, l.Load(`missing`) == nil)
+		require.True(t, l.Load(`missing/id`) == nil)
+		require.True(t, l.Load(`..`) == nil)
+		require.True(t, l.Namespace(`..`) == nil)
+
+		require.True(t, l.Load(`host`) == nil)
+		require.NotNil(t, l.Load(`net`))
+		require.Equal(t, typ.String, l.Load(`host`))
+
+		require.Equal(t, vf.Strings(`both`, `id`),
+			loader.Export(l.Namespace(`common`), true).Get(`entries`).(dgo.Map).Keys())
+		require.Equal(t, vf.Strings(`common`), loader.Export(l, false).Get(`namespaces`).(dgo.Map).Keys())
+
+		require.Panic(t, func() { l.Load(`bad/syntax`) },
+			regexp.QuoteMeta(`(file: `+bundle+`!/bad/syntax.dgo, line: 3, column: 1)`))
+	}
+}
+
+func TestReadBundle_manifest(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	bundle := filepath.Join(dir, `types.zip`)
+	port := `0..65535`
+
+	writeZip(t, bundle, `port.dgo`, port, `ns/id.dgo`, `string`, files.ManifestName,
+		sha(port)+`  port.dgo`+"\n"+sha(`string`)+`  ns/id.dgo`+"\n")
+	l := files.ReadBundle(bundle).NewLoader()
+	require.Equal(t, tf.Integer(0, 65535, true), l.Load(`port`))
+	require.Equal(t, typ.String, l.Load(`ns/id`))
+
+	writeZip(t, bundle, `port.dgo`, port, files.ManifestName, sha(`1..65535`)+`  port.dgo`)
+	require.Panic(t, func() { files.ReadBundle(bundle) }, `hash mismatch for file port\.dgo`)
+
+	writeZip(t, bundle, `port.dgo`, port, `id.dgo`, `string`, files.ManifestName, sha(port)+`  port.dgo`)
+	require.Panic(t, func() { files.ReadBundle(bundle) }, `file id\.dgo is not listed in the manifest`)
+
+	writeZip(t, bundle, `port.dgo`, port, files.ManifestName, sha(port)+`  port.dgo`+"\n"+sha(port)+`  id.dgo`)
+	require.Panic(t, func() { files.ReadBundle(bundle) }, `file id\.dgo is listed in the manifest but is missing`)
+
+	writeZip(t, bundle, `port.dgo`, port, files.ManifestName, sha(port)+` port.dgo`)
+	require.Panic(t, func() { files.ReadBundle(bundle) }, `invalid manifest line`)
+
+	// A bundle without a manifest can only be read unverified
+	writeZip(t, bundle, `./port.dgo`, port)
+	require.Panic(t, func() { files.ReadBundle(bundle) }, `bundle .*types\.zip: the manifest MANIFEST is missing`)
+	require.Panic(t, func() { files.NewBundleLoader(bundle) }, `the manifest MANIFEST is missing`)
+	require.Equal(t, tf.Integer(0, 65535, true), files.ReadUnverifiedBundle(bundle).NewLoader().Load(`port`))
+
+	entries, namespaces := files.ReadUnverifiedBundle(bundle).Lister(``)(nil)
+	require.Equal(t, vf.Strings(`port`), vf.Strings(entries...))
+	require.True(t, namespaces == nil)
+
+	// A manifest is ignored by an unverified read
+	writeZip(t, bundle, `port.dgo`, port, files.ManifestName, sha(`1..65535`)+`  port.dgo`)
+	entries, _ = files.ReadUnverifiedBundle(bundle).Lister(``)(nil)
+	require.Equal(t, vf.Strings(`port`), vf.Strings(entries...))
+}
+
+func TestReadBundle_errors(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	require.Panic(t, func() { files.ReadBundle(filepath.Join(dir, `types.rar`)) }, `unknown archive format`)
+	require.Panic(t, func() { files.ReadBundle(filepath.Join(dir, `missing.zip`)) }, `no such file`)
+	require.Panic(t, func() { files.ReadBundle(filepath.Join(dir, `missing.tar`)) }, `no such file`)
+
+	bad := filepath.Join(dir, `bad.tgz`)
+	if err := ioutil.WriteFile(bad, []byte(`not gzipped`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	require.Panic(t, func() { files.ReadBundle(bad) }, `bundle .*bad\.tgz: `)
+	require.Panic(t, func() { files.WriteBundle(filepath.Join(dir, `types.rar`), `testdata`) }, `unknown archive format`)
+	require.Panic(t, func() { files.WriteBundle(filepath.Join(dir, `types.zip`), `missing`) }, `no such file`)
+}
+
+func TestWriteBundle_failure(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	src := filepath.Join(dir, `src`)
+	out := filepath.Join(dir, `out`)
+	for _, d := range []string{src, out} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, `a.dgo`), []byte(`string`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a dangling link is listed as a type file but it cannot be read
+	if err := os.Symlink(filepath.Join(src, `missing`), filepath.Join(src, `b.dgo`)); err != nil {
+		t.Fatal(err)
+	}
+	bundle := filepath.Join(out, `types.zip`)
+	if err := ioutil.WriteFile(bundle, []byte(`previous`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	require.Panic(t, func() { files.WriteBundle(bundle, src) }, `no such file`)
+
+	// the existing bundle is kept and no temporary file is left behind
+	content, err := ioutil.ReadFile(bundle)
+	require.Ok(t, err)
+	require.Equal(t, `previous`, string(content))
+	fis, err := ioutil.ReadDir(out)
+	require.Ok(t, err)
+	require.Equal(t, 1, len(fis))
+
+	require.Ok(t, os.Remove(filepath.Join(src, `b.dgo`)))
+	files.WriteBundle(bundle, src)
+	require.Equal(t, typ.String, files.NewBundleLoader(bundle).Load(`a`))
+	fis, err = ioutil.ReadDir(out)
+	require.Ok(t, err)
+	require.Equal(t, 1, len(fis))
+	require.Equal(t, os.FileMode(0644), fis[0].Mode().Perm())
+}
@@ -0,0 +1,113 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/streamer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// Extensions are the extensions of the files that contain entries, in order of precedence. A file with the
+// extension .dgo is a type file and a file with the extension .json is a data file.
+var Extensions = []string{`.dgo`, `.json`}
+
+// validName returns true if the given name can be used as the name of a file or a directory
+func validName(name string) bool {
+	return name != `` && name != `.` && name != `..` && !strings.ContainsAny(name, `/\`)
+}
+
+// entryName returns the name of the entry that can be found in the file with the given name, and true, or false if
+// no entry can be found in that file
+func entryName(fileName string) (string, bool) {
+	for _, ext := range Extensions {
+		if strings.HasSuffix(fileName, ext) {
+			name := strings.TrimSuffix(fileName, ext)
+			return name, validName(name)
+		}
+	}
+	return ``, false
+}
+
+// uniqueNames returns the given names sorted and without duplicates
+func uniqueNames(names []string) []string {
+	sort.Strings(names)
+	u := names[:0]
+	for i, n := range names {
+		if i == 0 || n != names[i-1] {
+			u = append(u, n)
+		}
+	}
+	return u
+}
+
+// decode decodes the content of the file with the given name into the value of the entry with the given name
+func decode(name, fileName string, content []byte) interface{} {
+	if strings.HasSuffix(fileName, `.json`) {
+		return decodeJSON(fileName, content)
+	}
+	return decodeTypes(name, fileName, string(content))
+}
+
+// decodeTypes parses the given type file. The result is the parsed value when the file doesn't declare any
+// aliases. Otherwise, the result contains multiple entries, one for each declared alias, and the parsed value
+// with the given name unless an alias has that name.
+func decodeTypes(name, fileName, content string) interface{} {
+	var v dgo.Value
+	am, names := tf.CollectDeclarations(tf.BuiltInAliases(), func(aa dgo.AliasAdder) {
+		v = tf.ParseFile(aa, fileName, content)
+	})
+	if len(names) == 0 {
+		return v
+	}
+	m := vf.MapWithCapacity(len(names) + 1)
+	for _, n := range names {
+		m.Put(n, am.GetType(vf.String(n)))
+	}
+	if m.Get(name) == nil {
+		m.Put(name, v)
+	}
+	return loader.Multiple(m)
+}
+
+// decodeJSON decodes the given data file. Syntax errors are reported with the position where they were detected.
+func decodeJSON(fileName string, content []byte) (v dgo.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case *json.SyntaxError:
+				panic(positionedError(r.Error(), fileName, content, int(r.Offset)))
+			case error:
+				if r == io.ErrUnexpectedEOF {
+					panic(positionedError(r.Error(), fileName, content, len(content)))
+				}
+				panic(fmt.Errorf(`%s: (file: %s)`, r.Error(), fileName))
+			default:
+				panic(r)
+			}
+		}
+	}()
+	return streamer.UnmarshalJSON(content, nil)
+}
+
+// positionedError returns an error that is positioned at the character that precedes the given offset
+func positionedError(msg, fileName string, content []byte, offset int) error {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	line := 1 + strings.Count(string(content[:offset]), "\n")
+	ls := strings.LastIndexByte(string(content[:offset]), '\n') + 1
+	column := utf8.RuneCount(content[ls:offset])
+	if column == 0 {
+		column = 1
+	}
+	return &parser.Error{Message: msg, File: fileName, Line: line, Column: column}
+}
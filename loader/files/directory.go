@@ -0,0 +1,153 @@
+package files
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/loader"
+)
+
+// Finder returns a Finder that finds entries in the given directory. An entry is found in the file that has the
+// name of the entry and one of the Extensions. Type files are parsed using tf.ParseFile and data files are decoded
+// using streamer.UnmarshalJSON.
+//
+// Each alias that a type file declares becomes an entry of its own, so a file that declares several aliases yields
+// several entries. The parsed type file is the entry that has the name of the file unless it declares an alias with
+// that name.
+//
+// Errors in files are reported together with the name of the file and the position of the error.
+func Finder(dir string) dgo.Finder {
+	return finder(dir, false)
+}
+
+// ReloadingFinder returns a Finder that is like the one returned by Finder, but that also makes the loader check
+// the modification times of the files each time an entry is loaded. An entry is found again when its file has been
+// changed, removed, or when a file with an extension of higher precedence has been added. The same is true for
+// entries that were not found.
+func ReloadingFinder(dir string) dgo.Finder {
+	return finder(dir, true)
+}
+
+func finder(dir string, reload bool) dgo.Finder {
+	return func(_ dgo.Loader, name string) interface{} {
+		if !validName(name) {
+			return nil
+		}
+		if !reload {
+			return find(dir, name)
+		}
+		s := stamps(dir, name)
+		return loader.Checked(find(dir, name), func() bool { return !equalStamps(s, stamps(dir, name)) })
+	}
+}
+
+func find(dir, name string) interface{} {
+	for _, ext := range Extensions {
+		fileName := filepath.Join(dir, name+ext)
+		content, err := ioutil.ReadFile(fileName)
+		if err == nil {
+			return decode(name, fileName, content)
+		}
+		if !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+	return nil
+}
+
+// stamp identifies a version of a file. The zero stamp represents a missing file.
+type stamp struct {
+	modTime int64
+	size    int64
+}
+
+// stamps returns the stamps of the files that an entry with the given name can be found in, up to and including the
+// first file that exists
+func stamps(dir, name string) []stamp {
+	s := make([]stamp, len(Extensions))
+	for i, ext := range Extensions {
+		if fi, err := os.Stat(filepath.Join(dir, name+ext)); err == nil {
+			s[i] = stamp{modTime: fi.ModTime().UnixNano(), size: fi.Size()}
+			break
+		}
+	}
+	return s
+}
+
+func equalStamps(a, b []stamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NsCreator returns an NsCreator that creates a namespace for each sub directory of the given directory. The
+// namespace uses a Finder and an NsCreator for its sub directory.
+func NsCreator(dir string) dgo.NsCreator {
+	return nsCreator(dir, false)
+}
+
+// ReloadingNsCreator returns an NsCreator that is like the one returned by NsCreator, but that creates namespaces
+// that use a ReloadingFinder and a ReloadingNsCreator.
+func ReloadingNsCreator(dir string) dgo.NsCreator {
+	return nsCreator(dir, true)
+}
+
+func nsCreator(dir string, reload bool) dgo.NsCreator {
+	return func(l dgo.Loader, name string) dgo.Loader {
+		if !validName(name) {
+			return nil
+		}
+		sub := filepath.Join(dir, name)
+		if fi, err := os.Stat(sub); err == nil && fi.IsDir() {
+			return loader.NewListing(l, name, nil, finder(sub, reload), nsCreator(sub, reload), Lister(sub))
+		}
+		return nil
+	}
+}
+
+// Lister returns a Lister that lists the names of the entries that a Finder for the given directory can find and
+// the names of the namespaces that an NsCreator for that directory can create
+func Lister(dir string) dgo.Lister {
+	return func(_ dgo.Loader) (entries, namespaces []string) {
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			panic(err)
+		}
+		for _, fi := range fis {
+			name := fi.Name()
+			if fi.IsDir() {
+				if validName(name) {
+					namespaces = append(namespaces, name)
+				}
+			} else if n, ok := entryName(name); ok {
+				entries = append(entries, n)
+			}
+		}
+		return uniqueNames(entries), namespaces
+	}
+}
+
+// NewLoader returns a Loader for the root namespace that finds its entries in the given directory and its
+// namespaces in the sub directories of that directory. The loader uses a Lister for the directory to discover
+// entries and namespaces when it is walked.
+func NewLoader(dir string) dgo.Loader {
+	return loader.NewListing(nil, ``, nil, Finder(dir), NsCreator(dir), Lister(dir))
+}
+
+// NewReloadingLoader returns a Loader that is like the one returned by NewLoader, but that finds entries again when
+// their files change. The loader is a dgo.Invalidator, so functions that depend on its entries can use OnChange to
+// be notified when that happens.
+func NewReloadingLoader(dir string) dgo.Loader {
+	return loader.NewListing(nil, ``, nil, ReloadingFinder(dir), ReloadingNsCreator(dir), Lister(dir))
+}
@@ -0,0 +1,108 @@
+package files_test
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/loader/files"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func TestNewLoader(t *testing.T) {
+	l := files.NewLoader(`testdata`)
+	require.Equal(t, tf.Integer(1, 65535, true), l.Load(`port`))
+	require.Equal(t, vf.Map(`name`, `server`, `ports`, vf.Values(80, 443)), l.Load(`config`))
+	require.Equal(t, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), l.Load(`common/id`))
+	require.Equal(t, `/common`, l.Namespace(`common`).AbsoluteName())
+	require.True(t, l.Load(`missing`) == nil)
+	require.True(t, l.Load(`missing/id`) == nil)
+	require.True(t, l.Namespace(`port.dgo`) == nil)
+	require.True(t, l.Namespace(`..`) == nil)
+	require.True(t, l.Load(`..`) == nil)
+}
+
+func TestNewLoader_precedence(t *testing.T) {
+	require.Equal(t, typ.String, files.NewLoader(`testdata`).Load(`common/both`))
+}
+
+func TestNewLoader_multiple(t *testing.T) {
+	l := files.NewLoader(`testdata`)
+
+	// host is declared in net.dgo so it isn't found until that file has been loaded
+	require.True(t, l.Load(`host`) == nil)
+
+	endpoint := tf.StructMap(false,
+		tf.StructMapEntry(`host`, typ.String, true),
+		tf.StructMapEntry(`port`, tf.Integer(1, 65535, true), true))
+	require.Equal(t, tf.Tuple(typ.String, endpoint), l.Load(`net`))
+	require.Equal(t, typ.String, l.Load(`host`))
+	require.Equal(t, endpoint, l.Load(`endpoint`))
+}
+
+func TestFinder(t *testing.T) {
+	l := loader.New(nil, `types`, nil, files.Finder(filepath.Join(`testdata`, `common`)), nil)
+	require.Equal(t, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), l.Load(`id`))
+	require.True(t, l.Namespace(`common`) == nil)
+}
+
+func TestNsCreator(t *testing.T) {
+	l := loader.New(nil, ``, vf.Map(`a`, `the a`), nil, files.NsCreator(`testdata`))
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), l.Load(`common/id`))
+	require.True(t, l.Load(`port`) == nil)
+}
+
+func TestNewLoader_errors(t *testing.T) {
+	l := files.NewLoader(`testdata`)
+	bad := filepath.Join(`testdata`, `bad`)
+	require.Panic(t, func() { l.Load(`bad/syntax`) },
+		regexp.QuoteMeta(`expected one of ',' or ']', got '}': (file: `+filepath.Join(bad, `syntax.dgo`)+`, line: 3, column: 1)`))
+	require.Panic(t, func() { l.Load(`bad/data`) },
+		regexp.QuoteMeta(`invalid character '2' after object key: (file: `+filepath.Join(bad, `data.json`)+`, line: 3, column: 7)`))
+	require.Panic(t, func() { l.Load(`bad/eof`) },
+		regexp.QuoteMeta(`unexpected EOF: (file: `+filepath.Join(bad, `eof.json`)+`, line: 1, column: 9)`))
+	require.Panic(t, func() { files.Finder(filepath.Join(`testdata`, `port.dgo`))(l, `x`) }, `not a directory`)
+
+	var nl dgo.Loader
+	require.True(t, files.NsCreator(`testdata`)(nl, `port.dgo`) == nil)
+}
+
+func TestNewLoader_walk(t *testing.T) {
+	l := files.NewLoader(`testdata`)
+	require.Equal(t, vf.Map(`entries`, vf.Map(), `namespaces`, vf.Map()), loader.Export(l, false))
+
+	common := l.Namespace(`common`)
+	require.Equal(t, vf.Map(
+		`entries`, vf.Map(`both`, typ.String, `id`, tf.Pattern(regexp.MustCompile(`^[a-z]+$`))),
+		`namespaces`, vf.Map()), loader.Export(common, true))
+	require.Equal(t, `/common/both`, walkedNames(l)[0])
+
+	// discovery loads all entries, so errors are raised
+	require.Panic(t, func() { loader.Export(l, true) }, `\(file: `+regexp.QuoteMeta(filepath.Join(`testdata`, `bad`)))
+}
+
+func TestLister(t *testing.T) {
+	entries, namespaces := files.Lister(`testdata`)(nil)
+	require.Equal(t, vf.Strings(`config`, `net`, `port`), vf.Strings(entries...))
+	require.Equal(t, vf.Strings(`bad`, `common`), vf.Strings(namespaces...))
+
+	entries, namespaces = files.Lister(filepath.Join(`testdata`, `common`))(nil)
+	require.Equal(t, vf.Strings(`both`, `id`), vf.Strings(entries...))
+	require.True(t, namespaces == nil)
+
+	entries, _ = files.Lister(`missing`)(nil)
+	require.True(t, entries == nil)
+	require.Panic(t, func() { files.Lister(filepath.Join(`testdata`, `port.dgo`))(nil) }, `not a directory`)
+}
+
+func walkedNames(l dgo.Loader) []string {
+	var names []string
+	loader.Walk(l, false, func(name string, _ dgo.Value) { names = append(names, name) })
+	return names
+}
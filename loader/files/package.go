@@ -0,0 +1,2 @@
+// Package files contains the loader finders that find entries in type files and data files.
+package files
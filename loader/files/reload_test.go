@@ -0,0 +1,67 @@
+package files_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader/files"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// writeFile writes the given content to the given file and gives it a modification time that differs from the
+// time of the previous write
+func writeFile(t *testing.T, fileName, content string, version int) {
+	t.Helper()
+	if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mt := time.Date(2020, 1, 1, 0, 0, version, 0, time.UTC)
+	if err := os.Chtimes(fileName, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewReloadingLoader(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `reload`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err = os.Mkdir(filepath.Join(dir, `ns`), 0755); err != nil {
+		t.Fatal(err)
+	}
+	port := filepath.Join(dir, `ns`, `port.dgo`)
+	writeFile(t, port, `1..65535`, 1)
+
+	l := files.NewReloadingLoader(dir).(dgo.Invalidator)
+	var changed []string
+	l.OnChange(func(name string) { changed = append(changed, name) })
+	require.Equal(t, tf.Integer(1, 65535, true), l.Load(`ns/port`))
+	require.True(t, l.Load(`ns/host`) == nil)
+
+	writeFile(t, port, `1..1023`, 2)
+	require.Equal(t, tf.Integer(1, 1023, true), l.Load(`ns/port`))
+	require.Equal(t, vf.Strings(`/ns/port`), vf.Strings(changed...))
+
+	// a file with an extension of lower precedence is not used when it's added
+	writeFile(t, filepath.Join(dir, `ns`, `port.json`), `80`, 3)
+	require.Equal(t, tf.Integer(1, 1023, true), l.Load(`ns/port`))
+
+	// but it is used when the file of higher precedence is removed
+	if err = os.Remove(port); err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, 80, l.Load(`ns/port`))
+
+	// entries that were not found are found when their file is added
+	writeFile(t, filepath.Join(dir, `ns`, `host.dgo`), `string`, 4)
+	require.Equal(t, typ.String, l.Load(`ns/host`))
+	require.Equal(t, vf.Strings(`/ns/port`, `/ns/port`, `/ns/host`), vf.Strings(changed...))
+}
@@ -16,6 +16,49 @@ type (
 		dgo.Map
 	}
 
+	checkedEntry struct {
+		dgo.Value
+		stale func() bool
+	}
+
+	// group is a set of entries that were found by one call to the finder. The entries are invalidated together
+	// and they become stale together.
+	group struct {
+		names []string
+		stale func() bool
+	}
+
+	// call is a call to the finder that is in progress or completed. Callers that need the result of a call that
+	// is in progress wait for it to be done. A call is dropped when its key is invalidated while it is in progress
+	// and its result is then not added to the loader.
+	//
+	// The outer call is the call whose finder made this call, inner is the call that the finder of this call is
+	// currently making, and waiting is the call that the finder of this call waits for. They are used to detect
+	// finders that load a key that they are finding themselves, and finders that wait for each other. They are
+	// protected by the lock of the loader.
+	call struct {
+		key     string
+		done    chan struct{}
+		dropped bool
+		value   dgo.Value
+		err     interface{}
+		outer   *call
+		inner   *call
+		waiting *call
+	}
+
+	// finding is the loader that is passed to the finder. It is the loader that the finder was called for, marked
+	// with the call that it was called for.
+	finding struct {
+		*loader
+		call *call
+	}
+
+	// changeNotifier is implemented by loaders that notify functions when entries or namespaces are invalidated
+	changeNotifier interface {
+		changed(absoluteName string)
+	}
+
 	mapLoader struct {
 		name     string
 		parentNs dgo.Loader
@@ -28,6 +71,15 @@ type (
 		namespaces dgo.Map
 		finder     dgo.Finder
 		nsCreator  dgo.NsCreator
+		lister     dgo.Lister
+
+		// groups holds the group of each entry that was found together with other entries or that can become
+		// stale. Listeners are notified when entries or namespaces are invalidated.
+		groups    map[string]*group
+		listeners []func(string)
+
+		// calls holds the calls to the finder that are in progress
+		calls map[string]*call
 	}
 
 	childLoader struct {
@@ -43,6 +95,14 @@ func Multiple(m dgo.Map) dgo.Value {
 	return multipleEntries{m}
 }
 
+// Checked creates a value that holds the result of a finder together with a function that tells if that result has
+// become stale. A finder uses it when the loader should check the result each time one of its entries is loaded.
+// When the result is stale, all its entries are invalidated and the finder is called again. The result may be nil
+// or a value created by Multiple.
+func Checked(v interface{}, stale func() bool) dgo.Value {
+	return checkedEntry{Value: vf.Value(v), stale: stale}
+}
+
 func load(l dgo.Loader, name string) dgo.Value {
 	parts := strings.Split(name, `/`)
 	last := len(parts) - 1
@@ -56,7 +116,29 @@ func load(l dgo.Loader, name string) dgo.Value {
 }
 
 // New returns a new Loader instance
+//
+// The finder is called at most once concurrently for each key. Go routines that load a key while the finder is
+// finding it wait for the finder and receive the same result, or the same panic. When a key is invalidated while the
+// finder is finding it, the result of that find is returned to its callers but it is not added to the loader.
+//
+// The loader that is passed to the finder is a loader that knows what the finder is finding. A finder that uses it
+// to load the key that it is finding, directly or through other keys, doesn't wait for itself but is called again.
+// Finders that are called from different go routines and that wait for each other's keys panic with an error rather
+// than waiting forever.
 func New(parentNs dgo.Loader, name string, entries dgo.Map, finder dgo.Finder, nsCreator dgo.NsCreator) dgo.Loader {
+	return NewListing(parentNs, name, entries, finder, nsCreator, nil)
+}
+
+// NewListing returns a new Loader instance that is like the one returned by New, but that also uses the given lister
+// to discover the entries and namespaces that the finder and the nsCreator can find when the loader is walked, see
+// Walk.
+func NewListing(
+	parentNs dgo.Loader,
+	name string,
+	entries dgo.Map,
+	finder dgo.Finder,
+	nsCreator dgo.NsCreator,
+	lister dgo.Lister) dgo.Loader {
 	if entries == nil {
 		entries = vf.Map()
 	}
@@ -75,7 +157,8 @@ func New(parentNs dgo.Loader, name string, entries dgo.Map, finder dgo.Finder, n
 		mapLoader:  mapLoader{parentNs: parentNs, name: name, entries: entries.Copy(finder == nil)},
 		namespaces: namespaces,
 		finder:     finder,
-		nsCreator:  nsCreator}
+		nsCreator:  nsCreator,
+		lister:     lister}
 }
 
 // Type is the basic immutable loader dgo.Type
@@ -188,13 +271,36 @@ func (l *loader) initMap() dgo.Map {
 func (l *loader) add(key, value dgo.Value) dgo.Value {
 	l.lock.Lock()
 	defer l.lock.Unlock()
+	return l.put(key, value, true)
+}
+
+// put returns the entry for the given key from the given value that was returned from the finder. The entries in
+// the value are added to the loader when cache is true. The caller must hold the lock.
+func (l *loader) put(key, value dgo.Value, cache bool) dgo.Value {
+	var g *group
+	if c, ok := value.(checkedEntry); ok {
+		g = &group{stale: c.stale}
+		value = c.Value
+	}
 
 	addEntry := func(key, value dgo.Value) {
-		if old := l.entries.Get(key); old == nil {
+		if !cache {
+			return
+		}
+		if old := l.entries.Get(key); old == nil || old == vf.Nil {
+			// An entry that wasn't found earlier may be provided when the finder finds another entry
 			l.entries.Put(key, value)
 		} else if !old.Equals(value) {
 			panic(fmt.Errorf(`attempt to override entry %q`, key))
 		}
+		if g != nil {
+			if l.groups == nil {
+				l.groups = make(map[string]*group)
+			}
+			name := key.(dgo.String).GoString()
+			g.names = append(g.names, name)
+			l.groups[name] = g
+		}
 	}
 
 	if m, ok := value.(multipleEntries); ok {
@@ -202,6 +308,9 @@ func (l *loader) add(key, value dgo.Value) dgo.Value {
 		if value == nil {
 			panic(fmt.Errorf(`map returned from finder doesn't contain original key %q`, key))
 		}
+		if g == nil {
+			g = &group{}
+		}
 		m.EachEntry(func(e dgo.MapEntry) { addEntry(e.Key(), e.Value()) })
 	} else {
 		addEntry(key, value)
@@ -221,16 +330,29 @@ func (l *loader) HashCode() int {
 }
 
 func (l *loader) Get(ki interface{}) dgo.Value {
+	return l.get(ki, nil)
+}
+
+// get returns the entry for the given key. The outer call is the call that the finder that gets the entry was
+// called for, or nil when the entry isn't loaded by a finder.
+func (l *loader) get(ki interface{}, outer *call) dgo.Value {
 	key, ok := vf.Value(ki).(dgo.String)
 	if !ok {
 		return nil
 	}
 	l.lock.RLock()
 	v := l.entries.Get(key)
+	g := l.groups[key.GoString()]
 	l.lock.RUnlock()
+	if v != nil && g != nil && g.stale != nil && g.stale() {
+		l.lock.Lock()
+		removed := l.removeGroup(g)
+		l.lock.Unlock()
+		l.notify(removed)
+		v = nil
+	}
 	if v == nil && l.finder != nil {
-		v = vf.Value(l.finder(l, key.GoString()))
-		v = l.add(key, v)
+		v = l.find(key, outer)
 	}
 	if vf.Nil == v {
 		v = nil
@@ -238,10 +360,138 @@ func (l *loader) Get(ki interface{}) dgo.Value {
 	return v
 }
 
+// find calls the finder for the given key and adds the result. A key is found at most once concurrently. Callers
+// that find a key while another go routine is finding it wait for that call and receive its result. The outer call
+// is the call that the finder that finds the key was called for, or nil.
+func (l *loader) find(key dgo.String, outer *call) dgo.Value {
+	k := key.GoString()
+	l.lock.Lock()
+	if v := l.entries.Get(key); v != nil {
+		// Found by another go routine
+		l.lock.Unlock()
+		return v
+	}
+	if c, ok := l.calls[k]; ok {
+		if c.encloses(outer) {
+			// The finder loads the key that it is finding
+			l.lock.Unlock()
+			return l.add(key, vf.Value(l.finder(&finding{loader: l, call: outer}, k)))
+		}
+		if outer != nil {
+			if c.waitsFor(outer) {
+				l.lock.Unlock()
+				panic(fmt.Errorf(`the finders of %q and %q wait for each other`, outer.key, k))
+			}
+			outer.waiting = c
+		}
+		l.lock.Unlock()
+		<-c.done
+		if outer != nil {
+			l.lock.Lock()
+			outer.waiting = nil
+			l.lock.Unlock()
+		}
+		if c.err != nil {
+			panic(c.err)
+		}
+		return c.value
+	}
+	c := &call{key: k, done: make(chan struct{}), outer: outer}
+	if l.calls == nil {
+		l.calls = make(map[string]*call)
+	}
+	l.calls[k] = c
+	if outer != nil {
+		outer.inner = c
+	}
+	l.lock.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = r
+			l.lock.Lock()
+			l.endCall(k, c)
+			l.lock.Unlock()
+		}
+		close(c.done)
+		if c.err != nil {
+			panic(c.err)
+		}
+	}()
+	v := vf.Value(l.finder(&finding{loader: l, call: c}, k))
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	c.value = l.put(key, v, !c.dropped)
+	l.endCall(k, c)
+	return c.value
+}
+
+// encloses returns true if the given call is this call or a call that the finder of this call made, directly or
+// through other calls
+func (c *call) encloses(d *call) bool {
+	for ; d != nil; d = d.outer {
+		if d == c {
+			return true
+		}
+	}
+	return false
+}
+
+// waitsFor returns true if waiting for this call would make the given call wait for itself, i.e. if the innermost
+// call of this call waits for a call that encloses the given call, directly or through other waiting calls. The
+// caller must hold the lock.
+func (c *call) waitsFor(d *call) bool {
+	seen := make(map[*call]bool)
+	for c != nil && !seen[c] {
+		seen[c] = true
+		for c.inner != nil {
+			c = c.inner
+		}
+		c = c.waiting
+		if c.encloses(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// endCall removes the given call unless it has been dropped. The caller must hold the lock.
+func (l *loader) endCall(k string, c *call) {
+	if l.calls[k] == c {
+		delete(l.calls, k)
+	}
+	if o := c.outer; o != nil && o.inner == c {
+		o.inner = nil
+	}
+}
+
+// dropCall drops the call that is finding the given key, if any. The caller must hold the lock.
+func (l *loader) dropCall(k string) {
+	if c, ok := l.calls[k]; ok {
+		c.dropped = true
+		delete(l.calls, k)
+	}
+}
+
 func (l *loader) Load(name string) dgo.Value {
 	return load(l, name)
 }
 
+func (l *finding) Get(ki interface{}) dgo.Value {
+	return l.loader.get(ki, l.call)
+}
+
+func (l *finding) Load(name string) dgo.Value {
+	return load(l, name)
+}
+
+func (l *finding) Namespace(name string) dgo.Loader {
+	if name == `` {
+		return l
+	}
+	return l.loader.Namespace(name)
+}
+
 func (l *loader) Namespace(name string) dgo.Loader {
 	if name == `` {
 		return l
@@ -282,6 +532,101 @@ func (l *loader) NewChild(finder dgo.Finder, nsCreator dgo.NsCreator) dgo.Loader
 	return loaderWithParent(l, finder, nsCreator)
 }
 
+func (l *loader) Invalidate(name string) bool {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		if ns := l.cachedNamespace(name[:i]); ns != nil {
+			return ns.Invalidate(name[i+1:])
+		}
+		return false
+	}
+
+	var removed []string
+	l.lock.Lock()
+	l.dropCall(name)
+	if g := l.groups[name]; g != nil {
+		removed = l.removeGroup(g)
+	} else if l.finder != nil && l.entries.Get(name) != nil {
+		l.entries.Remove(name)
+		removed = []string{name}
+	}
+	l.lock.Unlock()
+	l.notify(removed)
+	return len(removed) > 0
+}
+
+func (l *loader) InvalidateNamespace(name string) bool {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		if ns := l.cachedNamespace(name[:i]); ns != nil {
+			return ns.InvalidateNamespace(name[i+1:])
+		}
+		return false
+	}
+	if name == `` || l.nsCreator == nil {
+		return false
+	}
+
+	l.lock.Lock()
+	ns, ok := l.namespaces.Remove(name).(dgo.Loader)
+	l.lock.Unlock()
+	if ok {
+		l.changed(ns.AbsoluteName())
+	}
+	return ok
+}
+
+func (l *loader) OnChange(f func(absoluteName string)) {
+	l.lock.Lock()
+	l.listeners = append(l.listeners, f)
+	l.lock.Unlock()
+}
+
+// cachedNamespace returns the namespace with the given name if it has been created, otherwise nil
+func (l *loader) cachedNamespace(name string) dgo.Invalidator {
+	if name == `` {
+		return l
+	}
+	l.lock.RLock()
+	ns, _ := l.namespaces.Get(name).(dgo.Invalidator)
+	l.lock.RUnlock()
+	return ns
+}
+
+// removeGroup removes the entries of the given group and returns their names. The caller must hold the write lock.
+func (l *loader) removeGroup(g *group) []string {
+	var removed []string
+	for _, n := range g.names {
+		if l.groups[n] == g {
+			delete(l.groups, n)
+			l.entries.Remove(n)
+			removed = append(removed, n)
+		}
+	}
+	return removed
+}
+
+// notify notifies the listeners that the entries with the given names have been removed
+func (l *loader) notify(names []string) {
+	an := l.AbsoluteName()
+	if an == `/` {
+		an = ``
+	}
+	for _, n := range names {
+		l.changed(an + `/` + n)
+	}
+}
+
+func (l *loader) changed(absoluteName string) {
+	l.lock.RLock()
+	ls := l.listeners
+	l.lock.RUnlock()
+	for _, f := range ls {
+		f(absoluteName)
+	}
+	if p, ok := l.parentNs.(changeNotifier); ok {
+		p.changed(absoluteName)
+	}
+}
+
 func (l *loader) String() string {
 	return MutableType.ValueString(l)
 }
@@ -359,6 +704,31 @@ func (l *childLoader) NewChild(finder dgo.Finder, nsCreator dgo.NsCreator) dgo.L
 	return loaderWithParent(l, finder, nsCreator)
 }
 
+func (l *childLoader) Invalidate(name string) bool {
+	removed := false
+	l.each(func(i dgo.Invalidator) { removed = i.Invalidate(name) || removed })
+	return removed
+}
+
+func (l *childLoader) InvalidateNamespace(name string) bool {
+	removed := false
+	l.each(func(i dgo.Invalidator) { removed = i.InvalidateNamespace(name) || removed })
+	return removed
+}
+
+func (l *childLoader) OnChange(f func(absoluteName string)) {
+	l.each(func(i dgo.Invalidator) { i.OnChange(f) })
+}
+
+// each calls the given function with the loader and the parent of this loader when they are invalidators
+func (l *childLoader) each(f func(dgo.Invalidator)) {
+	for _, ld := range []dgo.Loader{l.Loader, l.parent} {
+		if i, ok := ld.(dgo.Invalidator); ok {
+			f(i)
+		}
+	}
+}
+
 func (l *childLoader) String() string {
 	return ChildType.ValueString(l)
 }
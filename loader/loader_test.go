@@ -1,7 +1,10 @@
 package loader_test
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/lyraproj/dgo/tf"
 
@@ -112,6 +115,20 @@ func TestLoader_Load_multiple(t *testing.T) {
 	require.Equal(t, `the b`, l.Load(`b`))
 }
 
+func TestLoader_Load_multipleAfterNotFound(t *testing.T) {
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, name string) interface{} {
+		if name == `a` {
+			return loader.Multiple(vf.Map(
+				`a`, `the a`,
+				`b`, `the b`))
+		}
+		return nil
+	}, nil)
+	require.Nil(t, l.Load(`b`))
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, `the b`, l.Load(`b`))
+}
+
 func TestLoader_Load_multipleNotRequested(t *testing.T) {
 	l := loader.New(nil, ``, nil, func(l dgo.Loader, name string) interface{} {
 		return loader.Multiple(vf.Map(
@@ -220,3 +237,336 @@ func TestLoader_parsed(t *testing.T) {
 	require.Equal(t, `the b`, ld.Get(`b`))
 	require.Equal(t, `the a`, ld.Get(`a`))
 }
+
+func TestLoader_Invalidate(t *testing.T) {
+	calls := 0
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		calls++
+		if key == `a` || key == `b` {
+			return loader.Multiple(vf.Map(`a`, `the a`, `b`, `the b`))
+		}
+		return `the ` + key
+	}, testNamespace()).(dgo.Invalidator)
+	var changed []string
+	l.OnChange(func(name string) { changed = append(changed, name) })
+
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, `the c`, l.Load(`c`))
+	require.Equal(t, 2, calls)
+
+	// entries that were found together are invalidated together
+	require.True(t, l.Invalidate(`b`))
+	require.Equal(t, vf.Strings(`/a`, `/b`), vf.Strings(changed...))
+	require.False(t, l.Invalidate(`b`))
+	require.Equal(t, `the b`, l.Load(`b`))
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, 3, calls)
+
+	changed = nil
+	require.True(t, l.Invalidate(`c`))
+	require.Equal(t, vf.Strings(`/c`), vf.Strings(changed...))
+	require.Equal(t, `the c`, l.Load(`c`))
+	require.Equal(t, 4, calls)
+}
+
+func TestLoader_InvalidateNamespace(t *testing.T) {
+	l := loader.New(nil, `root`, nil, testFinder(), testNamespace()).(dgo.Invalidator)
+	var changed []string
+	l.OnChange(func(name string) { changed = append(changed, name) })
+
+	ns := l.Namespace(`ns`)
+	require.Equal(t, `the x`, l.Load(`ns/sub/x`))
+
+	require.False(t, l.Invalidate(`ns/sub/y`))
+	require.False(t, l.Invalidate(`other/x`))
+	require.True(t, l.Invalidate(`ns/sub/x`))
+	require.False(t, l.Invalidate(`/ns/sub/x`))
+	require.Equal(t, vf.Strings(`/root/ns/sub/x`), vf.Strings(changed...))
+
+	changed = nil
+	require.True(t, l.InvalidateNamespace(`ns/sub`))
+	require.False(t, l.InvalidateNamespace(`ns/sub`))
+	require.False(t, l.InvalidateNamespace(`other/sub`))
+	require.Equal(t, vf.Strings(`/root/ns/sub`), vf.Strings(changed...))
+	require.Same(t, ns, l.Namespace(`ns`))
+
+	require.True(t, l.InvalidateNamespace(`ns`))
+	require.NotSame(t, ns, l.Namespace(`ns`))
+	require.False(t, l.InvalidateNamespace(``))
+
+	// loaders without finder or namespace creator have nothing to invalidate
+	f := loader.New(nil, ``, vf.Map(`a`, `the a`), nil, testNamespace()).(dgo.Invalidator)
+	require.False(t, f.Invalidate(`a`))
+	f = loader.New(nil, ``, nil, testFinder(), nil).(dgo.Invalidator)
+	require.False(t, f.InvalidateNamespace(`a`))
+	_, ok := loader.New(nil, ``, vf.Map(`a`, `the a`), nil, nil).(dgo.Invalidator)
+	require.False(t, ok)
+}
+
+func TestLoader_Checked(t *testing.T) {
+	version := 1
+	stale := false
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		stale = false
+		v := version
+		if key == `missing` {
+			return loader.Checked(nil, func() bool { return stale })
+		}
+		return loader.Checked(loader.Multiple(vf.Map(`a`, v, `b`, v)), func() bool { return stale })
+	}, nil).(dgo.Invalidator)
+	var changed []string
+	l.OnChange(func(name string) { changed = append(changed, name) })
+
+	require.Equal(t, 1, l.Load(`a`))
+	require.Nil(t, l.Load(`missing`))
+	version = 2
+	require.Equal(t, 1, l.Load(`b`))
+	stale = true
+	require.Equal(t, 2, l.Load(`b`))
+	require.Equal(t, 2, l.Load(`a`))
+	require.Equal(t, vf.Strings(`/a`, `/b`), vf.Strings(changed...))
+
+	changed = nil
+	stale = true
+	require.Nil(t, l.Load(`missing`))
+	require.Equal(t, vf.Strings(`/missing`), vf.Strings(changed...))
+}
+
+func TestChildLoader_Invalidate(t *testing.T) {
+	p := loader.New(nil, ``, nil, testFinder(), nil)
+	c := p.NewChild(testFinder(), testNamespace()).(dgo.Invalidator)
+	var changed []string
+	c.OnChange(func(name string) { changed = append(changed, name) })
+	require.Equal(t, `the a`, c.Load(`a`))
+	require.Equal(t, `the x`, c.Load(`ns/x`))
+	require.True(t, c.Invalidate(`a`))
+	require.False(t, c.Invalidate(`a`))
+	require.True(t, c.InvalidateNamespace(`ns`))
+	require.False(t, c.InvalidateNamespace(`ns`))
+	require.Equal(t, vf.Strings(`/a`, `/ns`), vf.Strings(changed...))
+}
+
+func TestLoader_Invalidate_concurrent(t *testing.T) {
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		return loader.Checked(loader.Multiple(vf.Map(`a`, `the a`, `b`, `the b`)), func() bool { return true })
+	}, testNamespace()).(dgo.Invalidator)
+	l.OnChange(func(name string) {})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.Equal(t, `the b`, l.Load(`b`))
+				require.Equal(t, `the x`, l.Load(`ns/x`))
+				if (i+j)%3 == 0 {
+					l.Invalidate(`a`)
+					l.InvalidateNamespace(`ns`)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLoader_Load_singleFlight(t *testing.T) {
+	var lock sync.Mutex
+	calls := map[string]int{}
+	release := make(chan struct{})
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		lock.Lock()
+		calls[key]++
+		lock.Unlock()
+		<-release
+		if key == `c` {
+			return nil
+		}
+		return `the ` + key
+	}, testNamespace())
+
+	var wg sync.WaitGroup
+	results := make([]dgo.Value, 30)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = vf.Value(l.Load([]string{`a`, `b`, `c`}[i%3]))
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+
+	for i, r := range results {
+		switch i % 3 {
+		case 0:
+			require.Equal(t, `the a`, r)
+		case 1:
+			require.Equal(t, `the b`, r)
+		default:
+			require.Equal(t, vf.Nil, r)
+		}
+	}
+	require.Equal(t, 1, calls[`a`])
+	require.Equal(t, 1, calls[`b`])
+	require.Equal(t, 1, calls[`c`])
+}
+
+func TestLoader_Load_singleFlightPanic(t *testing.T) {
+	var lock sync.Mutex
+	calls := 0
+	release := make(chan struct{})
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		lock.Lock()
+		calls++
+		lock.Unlock()
+		<-release
+		panic(`bad ` + key)
+	}, nil)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf(`%v`, r)
+				}
+			}()
+			l.Load(`a`)
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+
+	// a failed find is not cached, so callers that arrive after the failure call the finder again
+	for _, err := range errs {
+		require.Equal(t, `bad a`, err.Error())
+	}
+	require.True(t, calls >= 1 && calls <= len(errs))
+}
+
+func TestLoader_finderGetsLoader(t *testing.T) {
+	var found dgo.Loader
+	l := loader.New(nil, `x`, nil, func(l dgo.Loader, key string) interface{} {
+		found = l
+		return `the ` + key
+	}, nil)
+	l.Get(`a`)
+	require.Equal(t, `/x`, found.AbsoluteName())
+	require.Equal(t, `the b`, found.Get(`b`))
+	require.Equal(t, `the c`, found.Load(`c`))
+	require.Same(t, found, found.Namespace(``))
+
+	// entries that are loaded through the loader of the finder are added to the loader
+	found = nil
+	require.Equal(t, `the b`, l.Get(`b`))
+	require.Nil(t, found)
+}
+
+func TestLoader_finderCycle(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		// make sure that each finder is finding its key before it loads the other one
+		started.Done()
+		started.Wait()
+		return l.Get(map[string]string{`a`: `b`, `b`: `a`}[key])
+	}, nil)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, key := range []string{`a`, `b`} {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf(`%v`, r)
+				}
+			}()
+			l.Get(key)
+		}(i, key)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal(`finders that wait for each other are deadlocked`)
+	}
+	for _, err := range errs {
+		require.NotOk(t, `the finders of "[ab]" and "[ab]" wait for each other`, err)
+	}
+}
+
+func TestLoader_Invalidate_inFlight(t *testing.T) {
+	var lock sync.Mutex
+	calls := 0
+	started := make(chan struct{})
+	release := make(chan struct{})
+	l := loader.New(nil, ``, nil, func(l dgo.Loader, key string) interface{} {
+		lock.Lock()
+		calls++
+		n := calls
+		lock.Unlock()
+		if n == 1 {
+			close(started)
+			<-release
+			return `stale`
+		}
+		return `fresh`
+	}, nil).(dgo.Invalidator)
+
+	done := make(chan dgo.Value)
+	go func() { done <- l.Get(`a`) }()
+	<-started
+	require.False(t, l.Invalidate(`a`))
+	close(release)
+
+	// the caller receives the result of the dropped find, but that result is not stored
+	require.Equal(t, `stale`, <-done)
+	require.Equal(t, `fresh`, l.Get(`a`))
+	require.Equal(t, 2, calls)
+}
+
+func TestLoader_concurrent(t *testing.T) {
+	var lock sync.Mutex
+	calls := map[string]int{}
+	var finder dgo.Finder = func(l dgo.Loader, key string) interface{} {
+		lock.Lock()
+		calls[l.AbsoluteName()+`/`+key]++
+		lock.Unlock()
+		return `the ` + key
+	}
+	var nsCreator dgo.NsCreator
+	nsCreator = func(l dgo.Loader, name string) dgo.Loader {
+		return loader.New(l, name, nil, finder, nsCreator)
+	}
+	l := loader.New(nil, `root`, nil, finder, nsCreator)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ns := []string{`a`, `b`, `c`}[(i+j)%3]
+				name := []string{`x`, `y`}[j%2]
+				require.Equal(t, `the `+name, l.Load(ns+`/`+name))
+				require.Equal(t, `the `+name, l.Load(ns+`/sub/`+name))
+				require.Equal(t, `/root/`+ns, l.Namespace(ns).AbsoluteName())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 12, len(calls))
+	for _, c := range calls {
+		require.Equal(t, 1, c)
+	}
+}
@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/vf"
+)
+
+type (
+	// ModuleSource returns the file name and the content of the type file of the module with the given path. The
+	// path is the absolute name of the module namespace without the leading '/', e.g. "common/net". The function
+	// must return false when no such module exists.
+	ModuleSource func(path string) (fileName, content string, ok bool)
+
+	modules struct {
+		lock      sync.Mutex
+		source    ModuleSource
+		parsed    map[string]dgo.Map
+		importers map[string]map[string]bool
+		root      dgo.Invalidator
+	}
+)
+
+// NewModuleLoader returns a Loader where each namespace is a module, i.e. a type file that is obtained from the
+// given source. The entries of a module namespace are the aliases that its type file declares. Since each module
+// has its own namespace, aliases with the same name in different modules don't collide.
+//
+// A type file may import other modules by their path and reference their aliases using the qualifier of the
+// import, see tf.ParseModule. A module is parsed once and the result is cached. An import cycle is an error.
+//
+// Walking the loader discovers the aliases of the modules that have been loaded, but not the modules themselves.
+//
+// The returned loader is a dgo.Invalidator. When an entry of a module is invalidated, the module is parsed again
+// when it is next loaded, and so are all modules that import it.
+func NewModuleLoader(source ModuleSource) dgo.Loader {
+	m := &modules{source: source, parsed: make(map[string]dgo.Map), importers: make(map[string]map[string]bool)}
+	m.root = NewListing(nil, ``, nil, m.find, m.newNamespace, m.list).(dgo.Invalidator)
+	m.root.OnChange(m.changed)
+	return m.root
+}
+
+// Importer returns an Importer that resolves import paths to namespaces of the given loader
+func Importer(l dgo.Loader) dgo.Importer {
+	return func(path string) dgo.Loader {
+		return namespace(l, path)
+	}
+}
+
+func namespace(l dgo.Loader, path string) dgo.Loader {
+	for _, n := range strings.Split(path, `/`) {
+		if l = l.Namespace(n); l == nil {
+			break
+		}
+	}
+	return l
+}
+
+func (m *modules) newNamespace(l dgo.Loader, name string) dgo.Loader {
+	return NewListing(l, name, nil, m.find, m.newNamespace, m.list)
+}
+
+// list lists the aliases of the module that is the given namespace. Modules can't be listed since they are only
+// known to the source.
+func (m *modules) list(l dgo.Loader) (entries, _ []string) {
+	path := strings.TrimPrefix(l.AbsoluteName(), `/`)
+	if path == `` {
+		return nil, nil
+	}
+	if aliases := m.module(path, nil); aliases != nil {
+		aliases.EachKey(func(k dgo.Value) { entries = append(entries, k.(dgo.String).GoString()) })
+	}
+	return entries, nil
+}
+
+func (m *modules) find(l dgo.Loader, name string) interface{} {
+	path := strings.TrimPrefix(l.AbsoluteName(), `/`)
+	if path == `` {
+		return nil
+	}
+	if entries := m.module(path, nil); entries != nil && entries.Get(name) != nil {
+		return Multiple(entries)
+	}
+	return nil
+}
+
+// module returns the aliases of the module with the given path, or nil if no such module exists. The given chain
+// contains the paths of the modules that are being parsed because of the import of this module.
+func (m *modules) module(path string, chain []string) dgo.Map {
+	for _, p := range chain {
+		if p == path {
+			panic(fmt.Errorf(`import cycle: %s -> %s`, strings.Join(chain, ` -> `), path))
+		}
+	}
+	m.lock.Lock()
+	entries, ok := m.parsed[path]
+	m.lock.Unlock()
+	if ok {
+		return entries
+	}
+
+	if fileName, content, found := m.source(path); found {
+		entries = m.parse(fileName, content, append(chain[:len(chain):len(chain)], path))
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if old, ok := m.parsed[path]; ok {
+		// Parsed from another go routine
+		return old
+	}
+	m.parsed[path] = entries
+	return entries
+}
+
+func (m *modules) parse(fileName, content string, chain []string) dgo.Map {
+	importer := func(path string) dgo.Loader {
+		if m.module(path, chain) == nil {
+			return nil
+		}
+		m.lock.Lock()
+		ip := m.importers[path]
+		if ip == nil {
+			ip = make(map[string]bool)
+			m.importers[path] = ip
+		}
+		ip[chain[len(chain)-1]] = true
+		m.lock.Unlock()
+		return namespace(m.root, path)
+	}
+	am, names := tf.CollectDeclarations(tf.BuiltInAliases(), func(aa dgo.AliasAdder) {
+		tf.ParseModule(aa, importer, fileName, content)
+	})
+	entries := vf.MapWithCapacity(len(names))
+	for _, n := range names {
+		entries.Put(n, am.GetType(vf.String(n)))
+	}
+	return entries.FrozenCopy().(dgo.Map)
+}
+
+// changed is called when the entry or the namespace with the given absolute name has been invalidated. It forgets
+// the module that is that namespace or that declares that entry, and invalidates the namespaces of the modules that
+// import it.
+func (m *modules) changed(absoluteName string) {
+	path := strings.TrimPrefix(absoluteName, `/`)
+	var importers []string
+	m.lock.Lock()
+	if _, ok := m.parsed[path]; ok {
+		importers = m.forget(path, importers)
+	}
+	if i := strings.LastIndexByte(path, '/'); i > 0 {
+		if entries := m.parsed[path[:i]]; entries != nil && entries.Get(path[i+1:]) != nil {
+			importers = m.forget(path[:i], importers)
+		}
+	}
+	m.lock.Unlock()
+	for _, ip := range importers {
+		m.root.InvalidateNamespace(ip)
+	}
+}
+
+// forget removes the module with the given path and all modules that import it from the cache. The paths of the
+// importing modules are appended to the given slice. The caller must hold the lock.
+func (m *modules) forget(path string, importers []string) []string {
+	delete(m.parsed, path)
+	for ip := range m.importers[path] {
+		if _, ok := m.parsed[ip]; ok {
+			importers = m.forget(ip, append(importers, ip))
+		}
+	}
+	delete(m.importers, path)
+	return importers
+}
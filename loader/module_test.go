@@ -0,0 +1,146 @@
+package loader_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func testSource(files map[string]string, calls map[string]int) loader.ModuleSource {
+	return func(path string) (string, string, bool) {
+		calls[path]++
+		c, ok := files[path]
+		return path + `.dgo`, c, ok
+	}
+}
+
+func TestModuleLoader(t *testing.T) {
+	calls := map[string]int{}
+	l := loader.NewModuleLoader(testSource(map[string]string{
+		`common/net`: `{
+  id=/^[a-z]+$/,
+  port=1..65535,
+  endpoint={host: string, port: port}
+}`,
+		`server`: `
+import "common/net"
+{
+  id=int,
+  server={id: id, name: net.id, endpoints: []net.endpoint}
+}`}, calls))
+
+	port := tf.Integer(1, 65535, true)
+	endpoint := tf.StructMap(false,
+		tf.StructMapEntry(`host`, typ.String, true),
+		tf.StructMapEntry(`port`, port, true))
+	require.Equal(t, port, l.Load(`common/net/port`))
+	require.Equal(t, endpoint, l.Load(`common/net/endpoint`))
+
+	// aliases with the same name in different modules don't collide
+	require.Equal(t, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), l.Load(`common/net/id`))
+	require.Equal(t, typ.Integer, l.Load(`server/id`))
+	require.Equal(t, tf.StructMap(false,
+		tf.StructMapEntry(`id`, typ.Integer, true),
+		tf.StructMapEntry(`name`, tf.Pattern(regexp.MustCompile(`^[a-z]+$`)), true),
+		tf.StructMapEntry(`endpoints`, tf.Array(endpoint), true)), l.Load(`server/server`))
+
+	require.True(t, l.Load(`server/port`) == nil)
+	require.True(t, l.Load(`common/port`) == nil)
+	require.True(t, l.Load(`missing/port`) == nil)
+
+	// each module is parsed once
+	require.Equal(t, 1, calls[`common/net`])
+	require.Equal(t, 1, calls[`server`])
+	require.Equal(t, 1, calls[`missing`])
+}
+
+func TestModuleLoader_Importer(t *testing.T) {
+	l := loader.NewModuleLoader(testSource(map[string]string{`net`: `{port=1..65535}`}, map[string]int{}))
+	var tp dgo.Value
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tp = tf.ParseModule(aa, loader.Importer(l), `main.dgo`, "import \"net\"\n{port: net.port}")
+	})
+	require.Equal(t, tf.StructMap(false, tf.StructMapEntry(`port`, tf.Integer(1, 65535, true), true)), tp)
+	require.True(t, loader.Importer(loader.New(nil, ``, nil, nil, nil))(`net`) == nil)
+}
+
+func TestModuleLoader_cycle(t *testing.T) {
+	l := loader.NewModuleLoader(testSource(map[string]string{
+		`a`: "import \"b\"\n{x=b.y}",
+		`b`: "import \"c\"\n{y=int, z=c.z}",
+		`c`: "import \"a\"\n{z=a.x}",
+	}, map[string]int{}))
+	require.Panic(t, func() { l.Load(`a/x`) }, `import cycle: a -> b -> c -> a: \(file: c\.dgo, line: 1, column: 8\)`)
+
+	// a module that is imported twice is not a cycle
+	l = loader.NewModuleLoader(testSource(map[string]string{
+		`a`: "import \"b\"\nimport \"c\"\n{x=b.y, w=c.z}",
+		`b`: "import \"c\"\n{y=c.z}",
+		`c`: `{z=int}`,
+	}, map[string]int{}))
+	require.Equal(t, typ.Integer, l.Load(`a/x`))
+	require.Equal(t, typ.Integer, l.Load(`a/w`))
+}
+
+func TestModuleLoader_errors(t *testing.T) {
+	l := loader.NewModuleLoader(testSource(map[string]string{
+		`a`: "import \"b\"\n{x=b.y}",
+		`b`: "{\n  y=[int\n}",
+		`c`: "import \"d\"\n{x=int}",
+	}, map[string]int{}))
+	require.Panic(t, func() { l.Load(`a/x`) }, `expected one of ',' or '\]', got '\}': \(file: b\.dgo, line: 3, column: 1\)`)
+	require.Panic(t, func() { l.Load(`c/x`) }, `unable to import "d": \(file: c\.dgo, line: 1, column: 8\)`)
+
+	// a failed module is not cached
+	require.Panic(t, func() { l.Load(`a/x`) }, `got '\}'`)
+}
+
+func TestModuleLoader_invalidate(t *testing.T) {
+	calls := map[string]int{}
+	files := map[string]string{
+		`net`:    `{port=1..65535}`,
+		`server`: "import \"net\"\n{server={port: net.port}}",
+		`client`: "import \"net\"\n{client={port: net.port}}",
+	}
+	l := loader.NewModuleLoader(testSource(files, calls)).(dgo.Invalidator)
+	require.Equal(t, tf.StructMap(false, tf.StructMapEntry(`port`, tf.Integer(1, 65535, true), true)), l.Load(`server/server`))
+	require.Equal(t, 1, calls[`net`])
+	require.Equal(t, 1, calls[`server`])
+
+	files[`net`] = `{port=1..1023}`
+	require.True(t, l.Invalidate(`net/port`))
+	require.Equal(t, tf.Integer(1, 1023, true), l.Load(`net/port`))
+	require.Equal(t, 2, calls[`net`])
+
+	// the importing module is parsed again
+	require.Equal(t, tf.StructMap(false, tf.StructMapEntry(`port`, tf.Integer(1, 1023, true), true)), l.Load(`server/server`))
+	require.Equal(t, 2, calls[`server`])
+
+	files[`server`] = `{server=string}`
+	require.True(t, l.InvalidateNamespace(`server`))
+	require.Equal(t, typ.String, l.Load(`server/server`))
+	require.Equal(t, 3, calls[`server`])
+	require.Equal(t, 0, calls[`client`])
+}
+
+func TestModuleLoader_walk(t *testing.T) {
+	calls := map[string]int{}
+	l := loader.NewModuleLoader(testSource(map[string]string{
+		`net`:    `{host=string, port=1..65535}`,
+		`server`: `{server=string}`,
+	}, calls))
+	require.True(t, l.Namespace(`net`) != nil)
+	empty := vf.Map(`entries`, vf.Map(), `namespaces`, vf.Map())
+	require.Equal(t, vf.Map(`entries`, vf.Map(), `namespaces`, vf.Map(`net`, empty)), loader.Export(l, false))
+
+	var names []string
+	loader.Walk(l, true, func(name string, _ dgo.Value) { names = append(names, name) })
+	require.Equal(t, vf.Strings(`/net/host`, `/net/port`), vf.Strings(names...))
+	require.Equal(t, 0, calls[`server`])
+}
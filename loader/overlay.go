@@ -0,0 +1,228 @@
+package loader
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/vf"
+)
+
+type (
+	// Shadow describes an entry in one layer of an overlay loader that hides an entry with the same name in a
+	// later layer
+	Shadow struct {
+		// Name is the absolute name of the entry
+		Name string
+
+		// Layer is the index of the layer that the entry was loaded from and Value is the loaded value
+		Layer int
+		Value dgo.Value
+
+		// Hidden is the index of the layer where the entry is hidden and HiddenValue is the value of the
+		// hidden entry
+		Hidden      int
+		HiddenValue dgo.Value
+	}
+
+	// shadows keeps track of the shadowed names that have been reported by an overlay loader and its namespaces
+	shadows struct {
+		lock     sync.Mutex
+		reported map[string]bool
+		report   func(Shadow)
+	}
+
+	overlayLoader struct {
+		name     string
+		parentNs dgo.Loader
+		layers   []dgo.Loader
+
+		// indexes are the indexes of the layers in the root overlay loader
+		indexes []int
+		shadows *shadows
+	}
+)
+
+// NewOverlay returns a loader that searches the given layers in order. An entry is loaded from the first layer
+// that has it, so entries in earlier layers hide entries with the same name in later layers. A namespace is an
+// overlay of the namespaces with the same name in each layer, which means that the search is done per namespace
+// as well as per entry.
+//
+// The given function, when not nil, is called once for each loaded name that is found in more than one layer. The
+// overlay loader must then load the name from all layers, so the function should only be used for diagnostics.
+func NewOverlay(name string, shadowed func(Shadow), layers ...dgo.Loader) dgo.Loader {
+	indexes := make([]int, len(layers))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	l := &overlayLoader{name: name, layers: layers, indexes: indexes}
+	if shadowed != nil {
+		l.shadows = &shadows{reported: make(map[string]bool), report: shadowed}
+	}
+	return l
+}
+
+// OverlayType is the overlay loader dgo.Type
+var OverlayType = tf.NewNamed(`overlayLoader`,
+	func(args dgo.Value) dgo.Value {
+		l := &overlayLoader{}
+		l.init(args.(dgo.Map))
+		return l
+	},
+	func(v dgo.Value) dgo.Value {
+		return v.(*overlayLoader).initMap()
+	},
+	reflect.TypeOf(&overlayLoader{}),
+	reflect.TypeOf((*dgo.Loader)(nil)).Elem(),
+	nil)
+
+func (l *overlayLoader) init(im dgo.Map) {
+	l.name = im.Get(`name`).(dgo.String).GoString()
+	la := im.Get(`layers`).(dgo.Array)
+	l.layers = make([]dgo.Loader, la.Len())
+	l.indexes = make([]int, la.Len())
+	la.EachWithIndex(func(v dgo.Value, i int) {
+		l.layers[i] = v.(dgo.Loader)
+		l.indexes[i] = i
+	})
+}
+
+func (l *overlayLoader) initMap() dgo.Map {
+	m := vf.MapWithCapacity(2)
+	m.Put(`name`, l.name)
+	m.Put(`layers`, l.layerArray())
+	return m
+}
+
+func (l *overlayLoader) layerArray() dgo.Array {
+	la := vf.ArrayWithCapacity(len(l.layers))
+	for _, ld := range l.layers {
+		la.Add(ld)
+	}
+	return la
+}
+
+func (l *overlayLoader) AbsoluteName() string {
+	an := `/` + l.name
+	if l.parentNs != nil {
+		if pn := l.parentNs.AbsoluteName(); pn != `/` {
+			an = pn + an
+		}
+	}
+	return an
+}
+
+func (l *overlayLoader) Equals(other interface{}) bool {
+	if ov, ok := other.(*overlayLoader); ok {
+		return l.name == ov.name && l.layerArray().Equals(ov.layerArray())
+	}
+	return false
+}
+
+func (l *overlayLoader) Get(key interface{}) dgo.Value {
+	for i, ld := range l.layers {
+		if v := ld.Get(key); v != nil {
+			if l.shadows != nil {
+				l.reportShadows(key, i, v)
+			}
+			return v
+		}
+	}
+	return nil
+}
+
+// reportShadows reports the entries with the given key in the layers after the given layer, unless the key has
+// been reported before
+func (l *overlayLoader) reportShadows(key interface{}, layer int, v dgo.Value) {
+	s := l.shadows
+	name := l.AbsoluteName()
+	if name == `/` {
+		name = ``
+	}
+	name += `/` + vf.Value(key).(dgo.String).GoString()
+
+	s.lock.Lock()
+	reported := s.reported[name]
+	s.reported[name] = true
+	s.lock.Unlock()
+	if reported {
+		return
+	}
+	for i := layer + 1; i < len(l.layers); i++ {
+		if hv := l.layers[i].Get(key); hv != nil {
+			s.report(Shadow{Name: name, Layer: l.indexes[layer], Value: v, Hidden: l.indexes[i], HiddenValue: hv})
+		}
+	}
+}
+
+func (l *overlayLoader) HashCode() int {
+	return l.layerArray().HashCode()
+}
+
+func (l *overlayLoader) Load(name string) dgo.Value {
+	return load(l, name)
+}
+
+func (l *overlayLoader) Name() string {
+	return l.name
+}
+
+func (l *overlayLoader) Namespace(name string) dgo.Loader {
+	if name == `` {
+		return l
+	}
+	var layers []dgo.Loader
+	var indexes []int
+	for i, ld := range l.layers {
+		if ns := ld.Namespace(name); ns != nil {
+			layers = append(layers, ns)
+			indexes = append(indexes, l.indexes[i])
+		}
+	}
+	if layers == nil {
+		return nil
+	}
+	return &overlayLoader{name: name, parentNs: l, layers: layers, indexes: indexes, shadows: l.shadows}
+}
+
+func (l *overlayLoader) NewChild(finder dgo.Finder, nsCreator dgo.NsCreator) dgo.Loader {
+	return loaderWithParent(l, finder, nsCreator)
+}
+
+func (l *overlayLoader) ParentNamespace() dgo.Loader {
+	return l.parentNs
+}
+
+func (l *overlayLoader) Invalidate(name string) bool {
+	removed := false
+	l.each(func(i dgo.Invalidator) { removed = i.Invalidate(name) || removed })
+	return removed
+}
+
+func (l *overlayLoader) InvalidateNamespace(name string) bool {
+	removed := false
+	l.each(func(i dgo.Invalidator) { removed = i.InvalidateNamespace(name) || removed })
+	return removed
+}
+
+func (l *overlayLoader) OnChange(f func(absoluteName string)) {
+	l.each(func(i dgo.Invalidator) { i.OnChange(f) })
+}
+
+// each calls the given function with each layer that is an invalidator
+func (l *overlayLoader) each(f func(dgo.Invalidator)) {
+	for _, ld := range l.layers {
+		if i, ok := ld.(dgo.Invalidator); ok {
+			f(i)
+		}
+	}
+}
+
+func (l *overlayLoader) String() string {
+	return OverlayType.ValueString(l)
+}
+
+func (l *overlayLoader) Type() dgo.Type {
+	return tf.ExactNamed(OverlayType, l)
+}
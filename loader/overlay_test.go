@@ -0,0 +1,134 @@
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func layer(entries dgo.Map, namespaces map[string]dgo.Map) dgo.Loader {
+	return loader.New(nil, ``, entries, nil, func(l dgo.Loader, name string) dgo.Loader {
+		if ns, ok := namespaces[name]; ok {
+			return loader.New(l, name, ns, nil, nil)
+		}
+		return nil
+	})
+}
+
+func TestNewOverlay(t *testing.T) {
+	project := layer(vf.Map(`a`, `project a`), map[string]dgo.Map{`ns`: vf.Map(`x`, `project x`)})
+	team := layer(vf.Map(`a`, `team a`, `b`, `team b`), nil)
+	platform := layer(vf.Map(`b`, `platform b`, `c`, `platform c`),
+		map[string]dgo.Map{`ns`: vf.Map(`x`, `platform x`, `y`, `platform y`)})
+
+	l := loader.NewOverlay(``, nil, project, team, platform)
+	require.Equal(t, `project a`, l.Load(`a`))
+	require.Equal(t, `team b`, l.Load(`b`))
+	require.Equal(t, `platform c`, l.Load(`c`))
+	require.True(t, l.Load(`d`) == nil)
+
+	require.Equal(t, `project x`, l.Load(`ns/x`))
+	require.Equal(t, `platform y`, l.Load(`ns/y`))
+	require.True(t, l.Load(`ns/z`) == nil)
+	require.True(t, l.Namespace(`other`) == nil)
+	require.True(t, l.Load(`other/x`) == nil)
+
+	ns := l.Namespace(`ns`)
+	require.Equal(t, `ns`, ns.Name())
+	require.Equal(t, `/ns`, ns.AbsoluteName())
+	require.Same(t, l, ns.ParentNamespace())
+	require.Same(t, l, l.Namespace(``))
+	require.Nil(t, l.ParentNamespace())
+}
+
+func TestNewOverlay_shadowed(t *testing.T) {
+	project := layer(vf.Map(`a`, `project a`), map[string]dgo.Map{`ns`: vf.Map(`x`, `project x`)})
+	team := layer(vf.Map(`a`, `team a`, `b`, `team b`), nil)
+	platform := layer(vf.Map(`a`, `platform a`, `b`, `platform b`),
+		map[string]dgo.Map{`ns`: vf.Map(`x`, `platform x`, `y`, `platform y`)})
+
+	var shadows []loader.Shadow
+	l := loader.NewOverlay(`types`, func(s loader.Shadow) { shadows = append(shadows, s) }, project, team, platform)
+	require.Equal(t, `project a`, l.Load(`a`))
+	require.Equal(t, `project a`, l.Load(`a`))
+	require.Equal(t, `team b`, l.Load(`b`))
+	require.Equal(t, `project x`, l.Load(`ns/x`))
+	require.Equal(t, `platform y`, l.Load(`ns/y`))
+
+	require.Equal(t, 4, len(shadows))
+	s := shadows[0]
+	require.Equal(t, `/types/a`, s.Name)
+	require.Equal(t, 0, s.Layer)
+	require.Equal(t, `project a`, s.Value)
+	require.Equal(t, 1, s.Hidden)
+	require.Equal(t, `team a`, s.HiddenValue)
+
+	s = shadows[1]
+	require.Equal(t, `/types/a`, s.Name)
+	require.Equal(t, 2, s.Hidden)
+	require.Equal(t, `platform a`, s.HiddenValue)
+
+	s = shadows[2]
+	require.Equal(t, `/types/b`, s.Name)
+	require.Equal(t, 1, s.Layer)
+	require.Equal(t, 2, s.Hidden)
+
+	// Layer indexes are the indexes of the layers of the overlay even though team has no ns namespace
+	s = shadows[3]
+	require.Equal(t, `/types/ns/x`, s.Name)
+	require.Equal(t, 0, s.Layer)
+	require.Equal(t, 2, s.Hidden)
+	require.Equal(t, `platform x`, s.HiddenValue)
+}
+
+func TestNewOverlay_Type(t *testing.T) {
+	l := loader.NewOverlay(`my`, nil, loader.New(nil, ``, vf.Map(`a`, `the a`), nil, nil))
+	tp := l.Type().(dgo.NamedType)
+	l2 := tp.New(tp.ExtractInitArg(l))
+	require.Equal(t, l, l2)
+	require.Equal(t, l.HashCode(), l2.HashCode())
+	require.NotEqual(t, l, loader.NewOverlay(`other`, nil, loader.New(nil, ``, vf.Map(`a`, `the a`), nil, nil)))
+	require.NotEqual(t, l, vf.Map(`a`, `the a`))
+	require.Same(t, loader.OverlayType, typ.Generic(tp))
+	require.Instance(t, l.Type(), l)
+	require.Equal(t, `the a`, l2.(dgo.Loader).Load(`a`))
+	require.Equal(t, `/my`, l.AbsoluteName())
+	require.Equal(t, `overlayLoader{"name":"my","layers":{mapLoader{"name":"","entries":{"a":"the a"}}}}`, l.String())
+}
+
+func TestNewOverlay_NewChild(t *testing.T) {
+	l := loader.NewOverlay(``, nil, loader.New(nil, ``, vf.Map(`a`, `the a`), nil, nil))
+	c := l.NewChild(testFinder(), nil)
+	require.Equal(t, `the a`, c.Load(`a`))
+	require.Equal(t, `the b`, c.Load(`b`))
+	require.True(t, l.Load(`b`) == nil)
+}
+
+func TestNewOverlay_Invalidate(t *testing.T) {
+	count := 0
+	finder := func(_ dgo.Loader, key string) interface{} {
+		count++
+		return `the ` + key
+	}
+	project := loader.New(nil, ``, nil, finder, testNamespace())
+	platform := loader.New(nil, ``, vf.Map(`a`, `platform a`), nil, nil)
+	l := loader.NewOverlay(``, nil, project, platform).(dgo.Invalidator)
+
+	var changed []string
+	l.OnChange(func(name string) { changed = append(changed, name) })
+
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, `the x`, l.Load(`ns/x`))
+	require.True(t, l.Invalidate(`a`))
+	require.False(t, l.Invalidate(`a`))
+	require.True(t, l.InvalidateNamespace(`ns`))
+	require.False(t, l.InvalidateNamespace(`ns`))
+	require.Equal(t, vf.Strings(`/a`, `/ns`), vf.Strings(changed...))
+
+	require.Equal(t, `the a`, l.Load(`a`))
+	require.Equal(t, 2, count)
+}
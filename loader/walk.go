@@ -0,0 +1,150 @@
+package loader
+
+import (
+	"sort"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// walker is implemented by loaders that can enumerate their contents
+type walker interface {
+	// contents returns the entries and the namespaces of the loader. Only entries and namespaces that have been
+	// loaded are returned unless discover is true, in which case the entries and namespaces that are listed by
+	// the lister of the loader are loaded first.
+	contents(discover bool) (entries dgo.Map, namespaces map[string]dgo.Loader)
+}
+
+// Walk calls the given function with the absolute name and the value of each entry of the given loader and, in
+// turn, of each entry of its namespaces. Entries are visited in name order before the namespaces, which are also
+// visited in name order.
+//
+// Only entries and namespaces that have already been loaded are visited unless discover is true. Loaders created
+// with NewListing then use their lister to discover the entries and namespaces that their finder and nsCreator can
+// find. Those entries and namespaces are loaded, so errors in them are raised.
+//
+// Entries of loaders that are not created by this package are not visited.
+func Walk(l dgo.Loader, discover bool, f func(absoluteName string, value dgo.Value)) {
+	entries, namespaces := contents(l, discover)
+	prefix := l.AbsoluteName()
+	if prefix != `/` {
+		prefix += `/`
+	}
+	for _, n := range sortedKeys(entries) {
+		f(prefix+n, entries.Get(n))
+	}
+	for _, n := range sortedNames(namespaces) {
+		Walk(namespaces[n], discover, f)
+	}
+}
+
+// Export returns the contents of the given loader as a Map that can be streamed, e.g. using streamer.MarshalJSON,
+// to examine how names are resolved. The map has the key "entries" with a map of the entries of the loader and the
+// key "namespaces" with a map of the exported namespaces of the loader. Entries and namespaces are selected in the
+// same way as for Walk.
+func Export(l dgo.Loader, discover bool) dgo.Map {
+	entries, namespaces := contents(l, discover)
+	em := vf.MapWithCapacity(entries.Len())
+	for _, n := range sortedKeys(entries) {
+		em.Put(n, entries.Get(n))
+	}
+	nm := vf.MapWithCapacity(len(namespaces))
+	for _, n := range sortedNames(namespaces) {
+		nm.Put(n, Export(namespaces[n], discover))
+	}
+	return vf.Map(`entries`, em, `namespaces`, nm)
+}
+
+func contents(l dgo.Loader, discover bool) (dgo.Map, map[string]dgo.Loader) {
+	if w, ok := l.(walker); ok {
+		return w.contents(discover)
+	}
+	return vf.Map(), nil
+}
+
+func sortedKeys(m dgo.Map) []string {
+	names := make([]string, 0, m.Len())
+	m.EachKey(func(k dgo.Value) { names = append(names, k.(dgo.String).GoString()) })
+	sort.Strings(names)
+	return names
+}
+
+func sortedNames(m map[string]dgo.Loader) []string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (l *mapLoader) contents(_ bool) (dgo.Map, map[string]dgo.Loader) {
+	return l.entries, nil
+}
+
+func (l *loader) contents(discover bool) (dgo.Map, map[string]dgo.Loader) {
+	if discover && l.lister != nil {
+		entries, namespaces := l.lister(l)
+		for _, n := range entries {
+			l.Get(n)
+		}
+		for _, n := range namespaces {
+			l.Namespace(n)
+		}
+	}
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	em := vf.MapWithCapacity(l.entries.Len())
+	l.entries.EachEntry(func(e dgo.MapEntry) {
+		if e.Value() != vf.Nil {
+			em.Put(e.Key(), e.Value())
+		}
+	})
+	nm := make(map[string]dgo.Loader, l.namespaces.Len())
+	l.namespaces.EachEntry(func(e dgo.MapEntry) {
+		nm[e.Key().(dgo.String).GoString()] = e.Value().(dgo.Loader)
+	})
+	return em, nm
+}
+
+func (l *childLoader) contents(discover bool) (dgo.Map, map[string]dgo.Loader) {
+	entries, namespaces := contents(l.Loader, discover)
+	pe, pn := contents(l.parent, discover)
+
+	// Entries in the parent take precedence, just like they do in Get
+	em := entries.Merge(pe)
+	nm := make(map[string]dgo.Loader, len(namespaces)+len(pn))
+	for n, ns := range namespaces {
+		nm[n] = ns
+	}
+	for n, pns := range pn {
+		if ns, ok := nm[n]; ok {
+			nm[n] = &childLoader{Loader: ns, parent: pns}
+		} else {
+			nm[n] = pns
+		}
+	}
+	return em, nm
+}
+
+func (l *overlayLoader) contents(discover bool) (dgo.Map, map[string]dgo.Loader) {
+	em := vf.Map()
+	layers := make(map[string][]dgo.Loader)
+	indexes := make(map[string][]int)
+
+	// Entries in earlier layers take precedence, just like they do in Get
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		entries, namespaces := contents(l.layers[i], discover)
+		em = em.Merge(entries)
+		for n, ns := range namespaces {
+			layers[n] = append([]dgo.Loader{ns}, layers[n]...)
+			indexes[n] = append([]int{l.indexes[i]}, indexes[n]...)
+		}
+	}
+	nm := make(map[string]dgo.Loader, len(layers))
+	for n, nsl := range layers {
+		nm[n] = &overlayLoader{name: n, parentNs: l, layers: nsl, indexes: indexes[n], shadows: l.shadows}
+	}
+	return em, nm
+}
@@ -0,0 +1,93 @@
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
+	"github.com/lyraproj/dgo/streamer"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func walked(l dgo.Loader, discover bool) dgo.Map {
+	m := vf.MutableMap()
+	loader.Walk(l, discover, func(name string, v dgo.Value) { m.Put(name, v) })
+	return m
+}
+
+func TestWalk(t *testing.T) {
+	l := loader.New(nil, ``, vf.Map(`a`, `the a`), func(_ dgo.Loader, key string) interface{} {
+		if key == `missing` {
+			return nil
+		}
+		return `the ` + key
+	}, testNamespace())
+	require.Equal(t, vf.Map(`/a`, `the a`), walked(l, false))
+
+	l.Load(`c`)
+	l.Load(`b`)
+	l.Load(`missing`)
+	l.Load(`ns/sub/x`)
+	l.Namespace(`empty`)
+	require.Equal(t, vf.Map(`/a`, `the a`, `/b`, `the b`, `/c`, `the c`, `/ns/sub/x`, `the x`), walked(l, false))
+
+	// discovery requires a lister
+	require.Equal(t, vf.Map(`/a`, `the a`, `/b`, `the b`, `/c`, `the c`, `/ns/sub/x`, `the x`), walked(l, true))
+}
+
+func TestWalk_discover(t *testing.T) {
+	lister := func(l dgo.Loader) ([]string, []string) {
+		return []string{`x`, `y`}, []string{`ns`}
+	}
+	l := loader.NewListing(nil, `root`, nil, testFinder(), func(l dgo.Loader, name string) dgo.Loader {
+		return loader.NewListing(l, name, nil, testFinder(), nil, lister)
+	}, lister)
+	require.Equal(t, vf.Map(), walked(l, false))
+	require.Equal(t, vf.Map(
+		`/root/x`, `the x`,
+		`/root/y`, `the y`,
+		`/root/ns/x`, `the x`,
+		`/root/ns/y`, `the y`), walked(l, true))
+	require.Equal(t, `the x`, l.Get(`x`))
+	require.Equal(t, 4, walked(l, false).Len())
+}
+
+func TestWalk_child(t *testing.T) {
+	p := loader.New(nil, ``, vf.Map(`a`, `parent a`), nil, testNamespace())
+	c := p.NewChild(testFinder(), testNamespace())
+	c.Load(`a`)
+	c.Load(`b`)
+	c.Load(`ns/x`)
+	p.Load(`ns/y`)
+	p.Load(`other/z`)
+	require.Equal(t, vf.Map(`/a`, `parent a`, `/b`, `the b`, `/ns/x`, `the x`, `/ns/y`, `the y`, `/other/z`, `the z`),
+		walked(c, false))
+}
+
+func TestWalk_overlay(t *testing.T) {
+	project := layer(vf.Map(`a`, `project a`), map[string]dgo.Map{`ns`: vf.Map(`x`, `project x`)})
+	platform := layer(vf.Map(`a`, `platform a`, `b`, `platform b`), map[string]dgo.Map{`ns`: vf.Map(`x`, `platform x`)})
+	l := loader.NewOverlay(`types`, nil, project, platform)
+	l.Load(`ns/x`)
+	require.Equal(t, vf.Map(`/types/a`, `project a`, `/types/b`, `platform b`, `/types/ns/x`, `project x`),
+		walked(l, false))
+}
+
+func TestExport(t *testing.T) {
+	l := loader.New(nil, ``, vf.Map(`a`, typ.String), nil, func(l dgo.Loader, name string) dgo.Loader {
+		return loader.New(l, name, vf.Map(`b`, 42), nil, nil)
+	})
+	l.Namespace(`ns`)
+	m := loader.Export(l, false)
+	require.Equal(t, vf.Map(
+		`entries`, vf.Map(`a`, typ.String),
+		`namespaces`, vf.Map(`ns`, vf.Map(`entries`, vf.Map(`b`, 42), `namespaces`, vf.Map()))), m)
+	require.Equal(t,
+		`{"entries":{"a":{"__type":"string"}},"namespaces":{"ns":{"entries":{"b":42},"namespaces":{}}}}`,
+		string(streamer.MarshalJSON(m, nil)))
+
+	// loaders that are not created by this package have no known contents
+	require.Equal(t, vf.Map(`entries`, vf.Map(), `namespaces`, vf.Map()), loader.Export(struct{ dgo.Loader }{l}, false))
+}
@@ -0,0 +1,298 @@
+package lsp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/stringer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+// formatWidth is the width that formatted documents are kept within
+const formatWidth = 100
+
+// unresolvedReference matches the message of the error that is raised when an alias is referenced but never declared
+var unresolvedReference = regexp.MustCompile(`^reference to unresolved type '([^']+)'$`)
+
+// document is an open text document and the result of its analysis. The syntax tree and its alias map are only
+// present when the document has no errors.
+type document struct {
+	uri   string
+	text  string
+	lines []string
+	names []string
+	errs  parser.Errors
+	tree  *parser.Node
+	am    dgo.AliasMap
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lines: strings.Split(text, "\n")}
+	_, d.names = tf.CollectDeclarations(tf.BuiltInAliases(), func(aa dgo.AliasAdder) {
+		_, d.errs = parser.RecoverParseFile(aa, uri, text)
+	})
+	if len(d.errs) == 0 {
+		err := util.Catch(func() {
+			d.am = tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) { d.tree = parser.ParseTree(aa, uri, text) })
+		})
+		if err != nil {
+			d.tree = nil
+			d.errs = append(d.errs, &parser.Error{Message: err.Error(), File: uri, Line: 1, Column: 1})
+		}
+	}
+	return d
+}
+
+// diagnostics returns a diagnostic for each error in the document
+func (d *document) diagnostics() []diagnostic {
+	ds := make([]diagnostic, len(d.errs))
+	for i, e := range d.errs {
+		start := position{Line: e.Line - 1, Character: e.Column - 1}
+		if m := unresolvedReference.FindStringSubmatch(e.Message); m != nil {
+			// The error is detected when the parse is complete so its position is the end of the document
+			if p, ok := d.find(m[1]); ok {
+				start = p
+			}
+		}
+		ds[i] = diagnostic{Range: d.wordRange(start), Severity: severityError, Source: `dgo`, Message: e.Message}
+	}
+	return ds
+}
+
+// find returns the position of the first occurrence of the given identifier
+func (d *document) find(name string) (position, bool) {
+	rx := regexp.MustCompile(`(?:^|[^\w$])(` + regexp.QuoteMeta(name) + `)(?:[^\w$]|$)`)
+	for i, l := range d.lines {
+		if m := rx.FindStringSubmatchIndex(l); m != nil {
+			return position{Line: i, Character: len([]rune(l[:m[2]]))}, true
+		}
+	}
+	return position{}, false
+}
+
+// wordRange returns a range that starts at the given position and extends to the end of the identifier or number
+// found there. The range covers at least one character unless the position is at the end of its line.
+func (d *document) wordRange(start position) textRange {
+	end := start
+	if start.Line >= 0 && start.Line < len(d.lines) {
+		l := []rune(d.lines[start.Line])
+		for end.Character < len(l) && parser.IsIdentifier(l[end.Character]) {
+			end.Character++
+		}
+		if end.Character == start.Character && end.Character < len(l) {
+			end.Character++
+		}
+	}
+	return textRange{Start: start, End: end}
+}
+
+// fullRange returns the range of the whole document
+func (d *document) fullRange() textRange {
+	last := len(d.lines) - 1
+	return textRange{End: position{Line: last, Character: len([]rune(d.lines[last]))}}
+}
+
+func nodeRange(n *parser.Node) textRange {
+	return textRange{
+		Start: position{Line: n.Start.Line - 1, Character: n.Start.Column - 1},
+		End:   position{Line: n.End.Line - 1, Character: n.End.Column - 1}}
+}
+
+// identifierAt returns the identifier node at the given position and the name that it contains
+func (d *document) identifierAt(p position) (*parser.Node, string) {
+	if d.tree == nil {
+		return nil, ``
+	}
+	n := d.tree.At(p.Line+1, p.Character+1)
+	if n == nil || n.Kind != parser.IdentifierNode {
+		return nil, ``
+	}
+	return n, strings.Trim(d.text[n.Start.Offset:n.End.Offset], `<> `)
+}
+
+// declared returns true if the document declares an alias with the given name
+func (d *document) declared(name string) bool {
+	for _, n := range d.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hover returns the resolved type of the identifier at the given position together with its description, or nil
+// if there is no identifier at the position
+func (d *document) hover(p position) *hover {
+	n, name := d.identifierAt(p)
+	if n == nil {
+		return nil
+	}
+	t, ok := n.Value.(dgo.Type)
+	if !ok {
+		t = n.Value.Type()
+	}
+	var s string
+	if d.declared(name) {
+		s = stringer.PrettyDeclaration(name, d.am, formatWidth, 0)
+	} else {
+		s = stringer.PrettyTypeString(t, d.am, formatWidth)
+	}
+	s = "```dgo\n" + s + "\n```"
+	if a := d.am.Annotations(vf.String(name)); a != nil {
+		if desc, ok := a.Get(`description`).(dgo.String); ok {
+			s += "\n\n" + desc.GoString()
+		}
+	}
+	return &hover{Contents: markupContent{Kind: `markdown`, Value: s}, Range: nodeRange(n)}
+}
+
+// definition returns the location of the declaration of the alias that is referenced at the given position, or
+// nil if there is no such reference
+func (d *document) definition(p position) *location {
+	n, name := d.identifierAt(p)
+	if n == nil {
+		return nil
+	}
+	var found *parser.Node
+	d.tree.Walk(func(c *parser.Node) bool {
+		if found == nil && c.Kind == parser.AliasNode && c.Children[0].Value.Equals(name) {
+			found = c.Children[0]
+		}
+		return found == nil
+	})
+	if found == nil {
+		return nil
+	}
+	return &location{URI: d.uri, Range: nodeRange(found)}
+}
+
+// completion returns the names of the aliases that the document declares followed by the keywords of the parser
+func (d *document) completion() []completionItem {
+	ks := parser.Keywords()
+	items := make([]completionItem, 0, len(d.names)+len(ks))
+	for _, n := range d.names {
+		item := completionItem{Label: n, Kind: completionTypeParameter}
+		if d.am != nil {
+			item.Detail = stringer.PrettyDeclaration(n, d.am, 0, 0)
+		}
+		items = append(items, item)
+	}
+	for _, k := range ks {
+		items = append(items, completionItem{Label: k, Kind: completionKeyword})
+	}
+	return items
+}
+
+// format returns the edits that replace the document with its canonical form produced by the type stringer. No
+// edits are returned when the document already is in that form, when it declares aliases inside other types, or
+// when it contains comments, since such declarations and comments cannot be preserved.
+func (d *document) format() ([]textEdit, error) {
+	if d.tree == nil {
+		return nil, errors.New(`a document with errors cannot be formatted`)
+	}
+	if d.hasComments() {
+		return []textEdit{}, nil
+	}
+	s, ok := d.formatted()
+	if !ok || s == d.text {
+		return []textEdit{}, nil
+	}
+	return []textEdit{{Range: d.fullRange(), NewText: s}}, nil
+}
+
+func (d *document) formatted() (string, bool) {
+	root := d.tree
+	var s string
+	if a := aliasNode(root); a != nil {
+		s = stringer.PrettyDeclaration(aliasName(a), d.am, formatWidth, 0)
+	} else if root.Kind == parser.CollectionNode && len(root.Children) > 0 && allAliases(root.Children) {
+		sb := strings.Builder{}
+		sb.WriteString("{\n")
+		for i, c := range root.Children {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			sb.WriteString(`  `)
+			sb.WriteString(stringer.PrettyDeclaration(aliasName(aliasNode(c)), d.am, formatWidth, 1))
+		}
+		sb.WriteString("\n}")
+		s = sb.String()
+	} else if len(d.names) == 0 {
+		t, ok := root.Value.(dgo.Type)
+		if !ok {
+			t = root.Value.Type()
+		}
+		s = stringer.PrettyTypeString(t, d.am, formatWidth)
+	} else {
+		return ``, false
+	}
+	s += "\n"
+
+	// Only use the result when it is equivalent to the original
+	var v dgo.Value
+	err := util.Catch(func() {
+		tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) { v = tf.ParseFile(aa, d.uri, s) })
+	})
+	return s, err == nil && v.Equals(root.Value)
+}
+
+// hasComments returns true if the document contains a comment, i.e. a "//" or "/*" that isn't part of a string or
+// regexp literal
+func (d *document) hasComments() bool {
+	var literals [][2]int
+	d.tree.Walk(func(n *parser.Node) bool {
+		if n.Kind == parser.LiteralNode {
+			literals = append(literals, [2]int{n.Start.Offset, n.End.Offset})
+		}
+		return true
+	})
+	for i := strings.Index(d.text, `/`); i >= 0 && i+1 < len(d.text); {
+		if c := d.text[i+1]; c == '/' || c == '*' {
+			inLiteral := false
+			for _, l := range literals {
+				if i >= l[0] && i < l[1] {
+					inLiteral = true
+					break
+				}
+			}
+			if !inLiteral {
+				return true
+			}
+		}
+		n := strings.Index(d.text[i+1:], `/`)
+		if n < 0 {
+			break
+		}
+		i += n + 1
+	}
+	return false
+}
+
+// aliasNode returns the given node if it is an alias declaration, the alias declaration that it annotates, or nil
+func aliasNode(n *parser.Node) *parser.Node {
+	if n.Kind == parser.TypeNode && len(n.Children) == 2 && n.Children[0].Kind == parser.AnnotationsNode {
+		n = n.Children[1]
+	}
+	if n.Kind == parser.AliasNode {
+		return n
+	}
+	return nil
+}
+
+func aliasName(n *parser.Node) string {
+	return n.Children[0].Value.(dgo.String).GoString()
+}
+
+func allAliases(ns []*parser.Node) bool {
+	for _, n := range ns {
+		if aliasNode(n) == nil {
+			return false
+		}
+	}
+	return true
+}
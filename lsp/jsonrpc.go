@@ -0,0 +1,87 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC error codes
+const (
+	parseError     = -32700
+	methodNotFound = -32601
+	invalidParams  = -32602
+	requestFailed  = -32803
+)
+
+// message is a JSON-RPC request, response, or notification. A request has an id and a method, a notification has
+// a method but no id, and a response has an id and either a result or an error.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads one message that is preceded by a Content-Length header. It returns io.EOF when the stream
+// ends before a new message starts.
+func readMessage(r *bufio.Reader) (*message, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == `` && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == `` {
+			break
+		}
+		if i := strings.IndexByte(line, ':'); i > 0 && strings.EqualFold(line[:i], `Content-Length`) {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil {
+				return nil, fmt.Errorf(`invalid Content-Length: %s`, line[i+1:])
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf(`missing Content-Length header`)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	m := &message{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, &responseError{Code: parseError, Message: err.Error()}
+	}
+	return m, nil
+}
+
+// writeMessage writes the given message preceded by a Content-Length header
+func writeMessage(w io.Writer, m *message) error {
+	m.JSONRPC = `2.0`
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err == nil {
+		_, err = w.Write(body)
+	}
+	return err
+}
@@ -0,0 +1,2 @@
+// Package lsp implements a Language Server Protocol server for dgo type files
+package lsp
@@ -0,0 +1,189 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lyraproj/dgo/util"
+)
+
+// Server is a language server for dgo type files. It keeps the open documents in memory, analyzes them each time
+// they change, and publishes the errors that it finds as diagnostics. It also provides hover, go to definition,
+// completion, and formatting.
+//
+// Documents are synchronized in full on each change.
+type Server struct {
+	docs     map[string]*document
+	out      io.Writer
+	writeErr error
+	exit     bool
+}
+
+// NewServer creates a new Server
+func NewServer() *Server {
+	return &Server{docs: make(map[string]*document)}
+}
+
+// Serve reads JSON-RPC requests and notifications from the given reader and writes responses and notifications to
+// the given writer. It returns when an exit notification is received, when the reader is exhausted, or when
+// reading or writing fails.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for !s.exit && s.writeErr == nil {
+		m, err := readMessage(r)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if re, ok := err.(*responseError); ok {
+				s.respond(nil, nil, re)
+				continue
+			}
+			return err
+		}
+		var result interface{}
+		var derr error
+		if err = util.Catch(func() { result, derr = s.dispatch(m.Method, m.Params) }); err == nil {
+			err = derr
+		}
+		if m.ID != nil {
+			s.respond(m.ID, result, err)
+		}
+	}
+	return s.writeErr
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case `initialize`:
+		return map[string]interface{}{
+			`capabilities`: map[string]interface{}{
+				`textDocumentSync`:           syncFull,
+				`hoverProvider`:              true,
+				`definitionProvider`:         true,
+				`completionProvider`:         map[string]interface{}{},
+				`documentFormattingProvider`: true,
+			},
+			`serverInfo`: map[string]interface{}{`name`: `dgolsp`},
+		}, nil
+	case `initialized`, `shutdown`:
+		return nil, nil
+	case `exit`:
+		s.exit = true
+		return nil, nil
+	case `textDocument/didOpen`:
+		var p didOpenParams
+		return nil, decode(params, &p, func() { s.update(p.TextDocument.URI, p.TextDocument.Text) })
+	case `textDocument/didChange`:
+		var p didChangeParams
+		return nil, decode(params, &p, func() {
+			if n := len(p.ContentChanges); n > 0 {
+				s.update(p.TextDocument.URI, p.ContentChanges[n-1].Text)
+			}
+		})
+	case `textDocument/didClose`:
+		var p didCloseParams
+		return nil, decode(params, &p, func() {
+			delete(s.docs, p.TextDocument.URI)
+			s.publish(p.TextDocument.URI, []diagnostic{})
+		})
+	case `textDocument/hover`, `textDocument/definition`, `textDocument/completion`:
+		return s.atPosition(method, params)
+	case `textDocument/formatting`:
+		var p formattingParams
+		var edits []textEdit
+		err := decode(params, &p, nil)
+		if err == nil {
+			if d, ok := s.docs[p.TextDocument.URI]; ok {
+				edits, err = d.format()
+			}
+		}
+		return edits, err
+	}
+	return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf(`method not found: %s`, method)}
+}
+
+// atPosition handles the requests that concern a position in a document
+func (s *Server) atPosition(method string, params json.RawMessage) (result interface{}, err error) {
+	var p positionParams
+	err = decode(params, &p, func() {
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return
+		}
+		switch method {
+		case `textDocument/hover`:
+			if h := d.hover(p.Position); h != nil {
+				result = h
+			}
+		case `textDocument/definition`:
+			if l := d.definition(p.Position); l != nil {
+				result = l
+			}
+		default:
+			result = d.completion()
+		}
+	})
+	return
+}
+
+// update analyzes the given text of the document with the given URI and publishes its diagnostics
+func (s *Server) update(uri, text string) {
+	d := newDocument(uri, text)
+	s.docs[uri] = d
+	s.publish(uri, d.diagnostics())
+}
+
+func (s *Server) publish(uri string, ds []diagnostic) {
+	s.notify(`textDocument/publishDiagnostics`, publishDiagnosticsParams{URI: uri, Diagnostics: ds})
+}
+
+// decode unmarshals the given parameters into v and calls the given function when that succeeds
+func decode(params json.RawMessage, v interface{}, f func()) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	if f != nil {
+		f()
+	}
+	return nil
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	b, err := json.Marshal(params)
+	if err == nil {
+		s.write(&message{Method: method, Params: b})
+	}
+}
+
+func (s *Server) respond(id *json.RawMessage, result interface{}, err error) {
+	if id == nil {
+		null := json.RawMessage(`null`)
+		id = &null
+	}
+	m := &message{ID: id}
+	if err == nil {
+		var b []byte
+		if b, err = json.Marshal(result); err == nil {
+			r := json.RawMessage(b)
+			m.Result = &r
+		}
+	}
+	if err != nil {
+		re, ok := err.(*responseError)
+		if !ok {
+			re = &responseError{Code: requestFailed, Message: err.Error()}
+		}
+		m.Error = re
+	}
+	s.write(m)
+}
+
+func (s *Server) write(m *message) {
+	if s.writeErr == nil {
+		s.writeErr = writeMessage(s.out, m)
+	}
+}
@@ -0,0 +1,306 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/lsp"
+)
+
+// client is an in-process language client that talks to a Server through pipes
+type client struct {
+	t    *testing.T
+	w    io.WriteCloser
+	r    *bufio.Reader
+	id   int
+	done chan error
+
+	// notifications that were received while waiting for a response
+	notifications []map[string]interface{}
+}
+
+func newClient(t *testing.T) *client {
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{t: t, w: cw, r: bufio.NewReader(cr), done: make(chan error, 1)}
+	go func() {
+		err := lsp.NewServer().Serve(sr, sw)
+		_ = sw.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *client) send(m map[string]interface{}) {
+	m[`jsonrpc`] = `2.0`
+	b, err := json.Marshal(m)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) read() map[string]interface{} {
+	length := 0
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		line = strings.TrimSpace(line)
+		if line == `` {
+			break
+		}
+		if strings.HasPrefix(line, `Content-Length:`) {
+			length, _ = strconv.Atoi(strings.TrimSpace(line[15:]))
+		}
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		c.t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// request sends a request and returns its response. Notifications that arrive before the response are stored.
+func (c *client) request(method string, params interface{}) map[string]interface{} {
+	c.id++
+	c.send(map[string]interface{}{`id`: c.id, `method`: method, `params`: params})
+	for {
+		m := c.read()
+		if _, ok := m[`id`]; ok {
+			require.Equal(c.t, float64(c.id), m[`id`])
+			return m
+		}
+		c.notifications = append(c.notifications, m)
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(map[string]interface{}{`method`: method, `params`: params})
+}
+
+// diagnostics sends the given notification and returns the diagnostics that the server publishes as a result
+func (c *client) diagnostics(method string, params interface{}) []interface{} {
+	c.notify(method, params)
+	m := c.read()
+	require.Equal(c.t, `textDocument/publishDiagnostics`, m[`method`])
+	return m[`params`].(map[string]interface{})[`diagnostics`].([]interface{})
+}
+
+func (c *client) open(uri, text string) []interface{} {
+	return c.diagnostics(`textDocument/didOpen`, map[string]interface{}{
+		`textDocument`: map[string]interface{}{`uri`: uri, `languageId`: `dgo`, `version`: 1, `text`: text}})
+}
+
+func (c *client) close() {
+	require.Equal(c.t, nil, c.request(`shutdown`, nil)[`result`])
+	c.notify(`exit`, nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+	_ = c.w.Close()
+}
+
+func at(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		`textDocument`: map[string]interface{}{`uri`: uri},
+		`position`:     map[string]interface{}{`line`: line, `character`: character}}
+}
+
+func rng(sl, sc, el, ec int) map[string]interface{} {
+	return map[string]interface{}{
+		`start`: map[string]interface{}{`line`: float64(sl), `character`: float64(sc)},
+		`end`:   map[string]interface{}{`line`: float64(el), `character`: float64(ec)}}
+}
+
+const types = `{
+  // a lower case name
+  lspSlug=/^[a-z]+$/,
+  lspNode={
+    name: lspSlug,
+    kids?: []lspNode
+  }
+}`
+
+func TestServer_initialize(t *testing.T) {
+	c := newClient(t)
+	r := c.request(`initialize`, map[string]interface{}{`capabilities`: map[string]interface{}{}})
+	caps := r[`result`].(map[string]interface{})[`capabilities`].(map[string]interface{})
+	require.Equal(t, float64(1), caps[`textDocumentSync`])
+	require.Equal(t, true, caps[`hoverProvider`])
+	require.Equal(t, true, caps[`definitionProvider`])
+	require.Equal(t, true, caps[`documentFormattingProvider`])
+	c.notify(`initialized`, map[string]interface{}{})
+	c.close()
+}
+
+func TestServer_diagnostics(t *testing.T) {
+	c := newClient(t)
+	require.Equal(t, 0, len(c.open(`file:///types.dgo`, types)))
+
+	ds := c.diagnostics(`textDocument/didChange`, map[string]interface{}{
+		`textDocument`:   map[string]interface{}{`uri`: `file:///types.dgo`, `version`: 2},
+		`contentChanges`: []interface{}{map[string]interface{}{`text`: "{\n  a: int,\n  b: 1 2,\n  c: 3 4\n}"}}})
+	require.Equal(t, 2, len(ds))
+	d := ds[0].(map[string]interface{})
+	require.Equal(t, `expected one of ',' or '}', got 2`, d[`message`])
+	require.Equal(t, rng(2, 7, 2, 8), d[`range`])
+	require.Equal(t, float64(1), d[`severity`])
+	require.Equal(t, `dgo`, d[`source`])
+
+	ds = c.diagnostics(`textDocument/didClose`, map[string]interface{}{
+		`textDocument`: map[string]interface{}{`uri`: `file:///types.dgo`}})
+	require.Equal(t, 0, len(ds))
+	c.close()
+}
+
+func TestServer_diagnostics_unresolved(t *testing.T) {
+	c := newClient(t)
+	ds := c.open(`file:///types.dgo`, "{\n  a: int,\n  b: lspMissing\n}")
+	require.Equal(t, 1, len(ds))
+	d := ds[0].(map[string]interface{})
+	require.Equal(t, `reference to unresolved type 'lspMissing'`, d[`message`])
+	require.Equal(t, rng(2, 5, 2, 15), d[`range`])
+	c.close()
+}
+
+func TestServer_hover(t *testing.T) {
+	c := newClient(t)
+	c.open(`file:///types.dgo`, types)
+	r := c.request(`textDocument/hover`, at(`file:///types.dgo`, 4, 12))[`result`].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{
+		`kind`:  `markdown`,
+		`value`: "```dgo\n@description(\"a lower case name\") lspSlug=/^[a-z]+$/\n```\n\na lower case name"}, r[`contents`])
+	require.Equal(t, rng(4, 10, 4, 17), r[`range`])
+
+	r = c.request(`textDocument/hover`, at(`file:///types.dgo`, 5, 15))[`result`].(map[string]interface{})
+	require.Equal(t, "```dgo\nlspNode={\"name\":lspSlug,\"kids\"?:[]lspNode}\n```", r[`contents`].(map[string]interface{})[`value`])
+
+	// no identifier at position
+	require.Equal(t, nil, c.request(`textDocument/hover`, at(`file:///types.dgo`, 0, 0))[`result`])
+
+	// unknown document
+	require.Equal(t, nil, c.request(`textDocument/hover`, at(`file:///other.dgo`, 0, 0))[`result`])
+	c.close()
+}
+
+func TestServer_definition(t *testing.T) {
+	c := newClient(t)
+	c.open(`file:///types.dgo`, types)
+	r := c.request(`textDocument/definition`, at(`file:///types.dgo`, 5, 14))[`result`].(map[string]interface{})
+	require.Equal(t, `file:///types.dgo`, r[`uri`])
+	require.Equal(t, rng(3, 2, 3, 9), r[`range`])
+
+	// built-in type has no definition
+	c.open(`file:///int.dgo`, `{a:int}`)
+	require.Equal(t, nil, c.request(`textDocument/definition`, at(`file:///int.dgo`, 0, 4))[`result`])
+	c.close()
+}
+
+func TestServer_completion(t *testing.T) {
+	c := newClient(t)
+	c.open(`file:///types.dgo`, types)
+	items := c.request(`textDocument/completion`, at(`file:///types.dgo`, 4, 10))[`result`].([]interface{})
+	first := items[0].(map[string]interface{})
+	require.Equal(t, `lspSlug`, first[`label`])
+	require.Equal(t, float64(25), first[`kind`])
+	require.Equal(t, `@description("a lower case name") lspSlug=/^[a-z]+$/`, first[`detail`])
+	require.Equal(t, `lspNode`, items[1].(map[string]interface{})[`label`])
+	labels := make([]string, 0, len(items))
+	for _, item := range items[2:] {
+		require.Equal(t, float64(14), item.(map[string]interface{})[`kind`])
+		labels = append(labels, item.(map[string]interface{})[`label`].(string))
+	}
+	require.Equal(t, `any binary bool dgo false float func int map nil sensitive string true type`, strings.Join(labels, ` `))
+
+	// aliases are completed also when the document has errors
+	c.open(`file:///broken.dgo`, `{lspBroken=int, x: }`)
+	items = c.request(`textDocument/completion`, at(`file:///broken.dgo`, 0, 1))[`result`].([]interface{})
+	require.Equal(t, `lspBroken`, items[0].(map[string]interface{})[`label`])
+	c.close()
+}
+
+func TestServer_formatting(t *testing.T) {
+	c := newClient(t)
+	c.open(`file:///types.dgo`, "{lspSlug=/^[a-z]+$/,\n\n  lspNode={name:lspSlug,kids?:[]lspNode}}")
+	params := map[string]interface{}{
+		`textDocument`: map[string]interface{}{`uri`: `file:///types.dgo`},
+		`options`:      map[string]interface{}{`tabSize`: 2, `insertSpaces`: true}}
+	edits := c.request(`textDocument/formatting`, params)[`result`].([]interface{})
+	require.Equal(t, 1, len(edits))
+	e := edits[0].(map[string]interface{})
+	require.Equal(t, rng(0, 0, 2, 41), e[`range`])
+	formatted := e[`newText`].(string)
+	require.Equal(t, "{\n  lspSlug=/^[a-z]+$/,\n  lspNode={\"name\":lspSlug,\"kids\"?:[]lspNode}\n}\n", formatted)
+
+	// already formatted
+	c.open(`file:///types.dgo`, formatted)
+	require.Equal(t, 0, len(c.request(`textDocument/formatting`, params)[`result`].([]interface{})))
+
+	// type without aliases
+	c.open(`file:///types.dgo`, `{a:int,  b:string}`)
+	e = c.request(`textDocument/formatting`, params)[`result`].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "{\"a\":int,\"b\":string}\n", e[`newText`])
+
+	// aliases declared inside other types are not formatted
+	c.open(`file:///types.dgo`, `{a:lspInner=int,b:lspInner}`)
+	require.Equal(t, 0, len(c.request(`textDocument/formatting`, params)[`result`].([]interface{})))
+
+	// comments are not formatted since they would be lost
+	for _, src := range []string{
+		"// not doc\n\nport=1..65535 // trailing",
+		"{\n  // the a\n  a: int}",
+		"{a:int, /* b */ b:string}",
+	} {
+		c.open(`file:///types.dgo`, src)
+		require.Equal(t, 0, len(c.request(`textDocument/formatting`, params)[`result`].([]interface{})))
+	}
+
+	// slashes in strings and regexps are not comments
+	c.open(`file:///types.dgo`, "{@description(\"a // b\") a:  /x\\/\\/y/, b: //, c: \"/*\"}")
+	e = c.request(`textDocument/formatting`, params)[`result`].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "{@description(\"a // b\") \"a\":/x\\/\\/y/,\"b\"://,\"c\":\"/*\"}\n", e[`newText`])
+
+	// errors
+	c.open(`file:///types.dgo`, `{a:int b:int}`)
+	r := c.request(`textDocument/formatting`, params)
+	require.Equal(t, `a document with errors cannot be formatted`, r[`error`].(map[string]interface{})[`message`])
+	c.close()
+}
+
+func TestServer_errors(t *testing.T) {
+	c := newClient(t)
+	r := c.request(`textDocument/unknown`, map[string]interface{}{})
+	require.Equal(t, float64(-32601), r[`error`].(map[string]interface{})[`code`])
+
+	r = c.request(`textDocument/hover`, []interface{}{1})
+	require.Equal(t, float64(-32602), r[`error`].(map[string]interface{})[`code`])
+
+	// unknown notifications are ignored
+	c.notify(`$/unknown`, nil)
+	c.close()
+}
+
+func TestServer_eof(t *testing.T) {
+	sr, cw := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- lsp.NewServer().Serve(sr, ioutil.Discard) }()
+	_ = cw.Close()
+	require.Nil(t, <-done)
+
+	require.NotNil(t, lsp.NewServer().Serve(strings.NewReader("Content-Type: x\r\n\r\n"), ioutil.Discard))
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/util"
+)
+
+// Error is an error that was detected at a specific position in the parsed content
+type Error struct {
+	// Message describes the error
+	Message string
+
+	// File is the name of the parsed file, or the empty string if the content didn't originate from a file
+	File string
+
+	// Line is the line of the error, starting at 1
+	Line int
+
+	// Column is the column of the error, starting at 1
+	Column int
+}
+
+// Errors is a list of errors in the order that they were detected
+type Errors []*Error
+
+func (e *Error) Error() string {
+	fn := ``
+	if e.File != `` {
+		fn = fmt.Sprintf(`file: %s, `, e.File)
+	}
+	ln := ``
+	if e.File != `` || e.Line > 1 {
+		ln = fmt.Sprintf(`line: %d, `, e.Line)
+	}
+	return fmt.Sprintf("%s: (%s%scolumn: %d)", e.Message, fn, ln, e.Column)
+}
+
+// Error returns the messages of all errors separated by newlines
+func (es Errors) Error() string {
+	s := make([]string, len(es))
+	for i := range es {
+		s[i] = es[i].Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// RecoverParseFile is like ParseFile but it doesn't stop at the first syntax error. Instead, it records the error,
+// skips to the next element or entry of the list where the error was detected, and continues. The returned value
+// is the result of the parse where all elements and entries that contained errors are left out. The value is
+// nil if an error was detected outside of all lists. The returned errors are nil when no errors were detected.
+func RecoverParseFile(am dgo.AliasAdder, fileName, content string) (result dgo.Value, errs Errors) {
+	p := &parser{Base: NewParserBase(am, nextToken, content), fileName: fileName, recovering: true}
+	err := util.Catch(func() { result = DoParse(p, fileName) })
+	errs = p.errs
+	if err != nil {
+		result = nil
+		if pe, ok := err.(*Error); ok {
+			errs = append(errs, pe)
+		} else {
+			errs = append(errs, &Error{Message: err.Error(), File: fileName, Line: 1, Column: 1})
+		}
+	}
+	return
+}
+
+// newError creates an Error with the given message that is positioned at the last token of the given parser
+func newError(p Parser, fileName string, r interface{}) *Error {
+	if pe, ok := r.(*Error); ok {
+		return pe
+	}
+	var msg string
+	if err, ok := r.(error); ok {
+		msg = err.Error()
+	} else {
+		msg = fmt.Sprint(r)
+	}
+	tl := 1
+	lt := p.LastToken()
+	if lt != nil {
+		if lt.End > lt.Pos {
+			tl = lt.End - lt.Pos
+		} else if lt.Value != `` {
+			tl = len(lt.Value)
+		}
+	}
+	sr := p.StringReader()
+	return &Error{Message: msg, File: fileName, Line: sr.Line(), Column: sr.Column() - tl}
+}
+
+// NextToken returns the next token and advances the token stream. The consumed token is tracked so that the
+// position of errors and syntax tree nodes is known. The number of open brackets is tracked when the parser is
+// recovering.
+func (p *parser) NextToken() *Token {
+	t := p.Base.NextToken()
+	p.consumed = t
+	if p.recovering {
+		switch t.Type {
+		case '{', '[', '(':
+			p.open++
+		case '}', ']', ')':
+			// A closing bracket always closes the innermost open bracket, even when they don't match
+			p.open--
+		}
+	}
+	return t
+}
+
+// recoverElement calls the given function, which parses one element of a list. If the function panics, the error
+// is recorded, the value stack is restored, and the tokens up to the next element of the list are skipped. The
+// function returns true when the end of the list has been reached.
+func (p *parser) recoverElement(endChar int, f func() bool) (done bool) {
+	if !p.recovering {
+		return f()
+	}
+	szp := p.Len()
+	open := p.open
+	defer func() {
+		if r := recover(); r != nil {
+			p.d = p.d[:szp]
+			if !p.atEnd {
+				p.errs = append(p.errs, newError(p, p.fileName, r))
+			}
+			// The last token of the base parser may have been peeked, so use the last consumed token
+			lt := p.consumed
+			depth := p.open - open
+			if depth < 0 && lt.Type != endChar {
+				// A stray closing bracket that doesn't close this list is skipped
+				p.open++
+				depth = 0
+			}
+			done = p.skipElement(lt, depth)
+		}
+	}()
+	return f()
+}
+
+// skipElement skips tokens until a comma is found outside of nested brackets, or the end of the list is found. The
+// given token is the last token that was consumed and depth is the number of brackets that are open in the current
+// element. The function returns true when the end of the list is found.
+func (p *parser) skipElement(t *Token, depth int) bool {
+	for {
+		switch {
+		case t.Type == end:
+			p.atEnd = true
+			return true
+		case depth < 0:
+			return true
+		case depth == 0 && t.Type == ',':
+			return false
+		}
+		t = p.nextTokenOrEnd()
+		switch t.Type {
+		case '{', '[', '(':
+			depth++
+		case '}', ']', ')':
+			depth--
+		}
+	}
+}
+
+// nextTokenOrEnd returns the next token. Errors from the lexer are recorded and the lexer is called again as long
+// as it makes progress. An end token is returned when it doesn't.
+func (p *parser) nextTokenOrEnd() *Token {
+	for {
+		pos := p.StringReader().Pos()
+		var t *Token
+		if err := util.Catch(func() { t = p.NextToken() }); err == nil {
+			return t
+		} else if !p.atEnd {
+			p.errs = append(p.errs, newError(p, p.fileName, err))
+		}
+		if p.StringReader().Pos() == pos {
+			return &Token{Type: end}
+		}
+	}
+}
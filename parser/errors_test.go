@@ -0,0 +1,105 @@
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+)
+
+func ExampleRecoverParseFile() {
+	src := `{
+  a: int,
+  b: strin g,
+  c: 1..,
+  d: "x" "y",
+  e: bool
+}`
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		v, errs := parser.RecoverParseFile(aa, `example.dgo`, src)
+		fmt.Println(v)
+		for _, e := range errs {
+			fmt.Println(e)
+		}
+	})
+	// Output:
+	// {"a":int,"c":1..,"e":bool}
+	// expected one of ',' or '}', got g: (file: example.dgo, line: 3, column: 12)
+	// expected one of ',' or '}', got "y": (file: example.dgo, line: 5, column: 10)
+}
+
+func recoverParse(src string) (v dgo.Value, errs parser.Errors) {
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		v, errs = parser.RecoverParseFile(aa, ``, src)
+	})
+	return
+}
+
+func TestRecoverParseFile(t *testing.T) {
+	v, errs := recoverParse(`{a:int,b:{x:1 2,y:3},c:[1,2}int,d:bool}`)
+	require.Equal(t, tf.ParseType(`{a:int,b:{y:3},d:bool}`), v)
+	require.Equal(t, 2, len(errs))
+	require.Equal(t, `expected one of ',' or '}', got 2`, errs[0].Message)
+	require.Equal(t, 1, errs[0].Line)
+	require.Equal(t, 15, errs[0].Column)
+	require.Equal(t, `expected one of ',' or ']', got '}'`, errs[1].Message)
+}
+
+func TestRecoverParseFile_noErrors(t *testing.T) {
+	v, errs := recoverParse(`{a:int,b:[]string}`)
+	require.Equal(t, tf.ParseType(`{a:int,b:[]string}`), v)
+	require.True(t, errs == nil)
+}
+
+func TestRecoverParseFile_tuple(t *testing.T) {
+	v, errs := recoverParse(`{int,map[},string,]bool}`)
+	require.Equal(t, tf.Tuple(typ.Integer, typ.String), v)
+	require.Equal(t, 2, len(errs))
+}
+
+func TestRecoverParseFile_unterminated(t *testing.T) {
+	v, errs := recoverParse(`{a:int,b:{x:string`)
+	require.Equal(t, tf.ParseType(`{a:int}`), v)
+	require.Equal(t, 1, len(errs))
+	require.Equal(t, `expected one of ',' or '}', got EOT`, errs[0].Message)
+
+	v, errs = recoverParse(`{a:int,b:"abc}`)
+	require.Equal(t, tf.ParseType(`{a:int}`), v)
+	require.Equal(t, 1, len(errs))
+	require.Equal(t, `unterminated string`, errs[0].Message)
+}
+
+func TestRecoverParseFile_lexerError(t *testing.T) {
+	v, errs := recoverParse(`{a:1.x,b:bool}`)
+	require.Equal(t, tf.ParseType(`{b:bool}`), v)
+	require.Equal(t, 1, len(errs))
+}
+
+func TestRecoverParseFile_topLevel(t *testing.T) {
+	v, errs := recoverParse(`int|`)
+	require.Nil(t, v)
+	require.Equal(t, 1, len(errs))
+	require.Equal(t, `expected a type expression, got EOT: (column: 5)`, errs.Error())
+}
+
+func TestRecoverParseFile_multiLine(t *testing.T) {
+	_, errs := recoverParse("{\n  a: int,\n  b: 1 2,\n  c: 3 4\n}")
+	require.Equal(t, 2, len(errs))
+	require.Equal(t, "expected one of ',' or '}', got 2: (line: 3, column: 8)\n"+
+		"expected one of ',' or '}', got 4: (line: 4, column: 8)", errs.Error())
+}
+
+func TestParseFile_error(t *testing.T) {
+	err := util.Catch(func() { tf.ParseFile(nil, `x.dgo`, "{\n  a: 1 2\n}") })
+	pe, ok := err.(*parser.Error)
+	require.True(t, ok)
+	require.Equal(t, `x.dgo`, pe.File)
+	require.Equal(t, 2, pe.Line)
+	require.Equal(t, 8, pe.Column)
+	require.Equal(t, `expected one of ',' or '}', got 2: (file: x.dgo, line: 2, column: 8)`, pe.Error())
+}
@@ -67,6 +67,13 @@ func init() {
 type Token struct {
 	Value string
 	Type  int
+
+	// Doc is the text of the comments that were found on the lines directly above the token
+	Doc string
+
+	// Pos is the offset of the first byte of the token in the parsed content and End is the offset of the byte
+	// that follows the token
+	Pos, End int
 }
 
 func tokenString(t *Token) (s string) {
@@ -96,28 +103,53 @@ func badToken(r rune) error {
 	return fmt.Errorf("unexpected character '%c'", r)
 }
 
+// nextToken returns the next token. Line comments start with // and block comments are enclosed in /* and */.
+// Comments that start on a line of their own and are directly followed by the token, without an empty line in
+// between, become the Doc of the token.
 func nextToken(sr *util.StringReader) (t *Token) {
+	doc := ``
+	newLine := sr.Pos() == 0
 	for {
+		pos := sr.Pos()
 		r := sr.Next()
 		switch r {
 		case 0:
-			return &Token{``, end}
-		case ' ', '\t', '\n':
+			return &Token{Type: end, Pos: pos, End: pos}
+		case ' ', '\t':
+			continue
+		case '\n':
+			if newLine {
+				// empty line
+				doc = ``
+			}
+			newLine = true
 			continue
 		case '`':
-			t = &Token{consumeRawString(sr), stringLiteral}
+			t = &Token{Value: consumeRawString(sr), Type: stringLiteral}
 		case '"':
-			t = &Token{ConsumeString(sr, r), stringLiteral}
+			t = &Token{Value: ConsumeString(sr, r), Type: stringLiteral}
 		case '/':
-			t = &Token{ConsumeRegexp(sr), regexpLiteral}
+			if isComment(sr) {
+				c := consumeComment(sr)
+				if newLine {
+					doc = joinDoc(doc, c)
+				}
+				newLine = false
+				if sr.Peek() == '\n' {
+					sr.Next()
+					newLine = true
+				}
+				continue
+			}
+			t = &Token{Value: ConsumeRegexp(sr), Type: regexpLiteral}
 		case '.':
 			if sr.Peek() == '.' {
 				sr.Next()
 				if sr.Peek() == '.' {
 					sr.Next()
-					t = &Token{`...`, dotdotdot}
+					t = &Token{Value: `...`, Type: dotdotdot}
 				} else {
-					t = &Token{`..`, dotdot}
+					t = &Token{Value: `..`, Type: dotdot}
 				}
 			} else {
 				t = &Token{Type: int(r)}
@@ -132,13 +164,70 @@ func nextToken(sr *util.StringReader) (t *Token) {
 				util.WriteRune(buf, r)
 			}
 			tkn := ConsumeNumber(sr, n, buf, integer)
-			return &Token{buf.String(), tkn}
+			t = &Token{Value: buf.String(), Type: tkn}
 		default:
 			t = buildToken(r, sr)
 		}
-		break
+		t.Doc, t.Pos, t.End = doc, pos, sr.Pos()
+		return t
 	}
-	return t
+}
+
+// isComment returns true if the '/' that was just read starts a comment rather than a regexp. A regexp cannot
+// start with '*', and the empty regexp // is recognized by being followed by a character that ends an expression,
+// possibly after white space and line breaks, so that //, // followed by a newline and a '}', and // at the end of
+// the content are all empty regexps.
+func isComment(sr *util.StringReader) bool {
+	switch sr.Peek() {
+	case '*':
+		return true
+	case '/':
+		saved := *sr
+		sr.Next()
+		r := sr.Next()
+		for r == ' ' || r == '\t' || r == '\n' {
+			r = sr.Next()
+		}
+		*sr = saved
+		return !isExpressionEnd(r)
+	}
+	return false
+}
+
+// consumeComment consumes a comment, starting after the initial '/', and returns its text. The text of a line
+// comment ends before the newline. The leading '*' of the lines in a block comment is stripped.
+func consumeComment(sr *util.StringReader) string {
+	buf := bytes.NewBufferString(``)
+	if sr.Next() == '/' {
+		for r := sr.Peek(); r != 0 && r != '\n'; r = sr.Peek() {
+			util.WriteRune(buf, sr.Next())
+		}
+		return strings.TrimSpace(buf.String())
+	}
+	for {
+		r := sr.Next()
+		if r == 0 {
+			panic(errors.New("unterminated comment"))
+		}
+		if r == '*' && sr.Peek() == '/' {
+			sr.Next()
+			break
+		}
+		util.WriteRune(buf, r)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), `*`))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// joinDoc joins the text of two consecutive comments
+func joinDoc(doc, c string) string {
+	if doc == `` {
+		return c
+	}
+	return doc + "\n" + c
 }
 
 func buildToken(r rune, sr *util.StringReader) *Token {
@@ -146,11 +235,11 @@ func buildToken(r rune, sr *util.StringReader) *Token {
 	case IsDigit(r):
 		buf := bytes.NewBufferString(``)
 		tkn := ConsumeNumber(sr, r, buf, integer)
-		return &Token{buf.String(), tkn}
+		return &Token{Value: buf.String(), Type: tkn}
 	case IsIdentifierStart(r):
 		buf := bytes.NewBufferString(``)
 		consumeIdentifier(sr, r, buf)
-		return &Token{buf.String(), identifier}
+		return &Token{Value: buf.String(), Type: identifier}
 	default:
 		return &Token{Type: int(r)}
 	}
@@ -321,7 +410,7 @@ func ConsumeRegexp(sr *util.StringReader) string {
 }
 
 // ConsumeString consumes the current string up to the given end character while taking
-// escaped nl, cr, tab, escape, and end character into account.
+// escaped end character and the escapes produced by strconv.Quote into account.
 func ConsumeString(sr *util.StringReader, end rune) string {
 	buf := bytes.NewBufferString(``)
 	for {
@@ -333,7 +422,7 @@ func ConsumeString(sr *util.StringReader, end rune) string {
 		case 0:
 			panic(errors.New("unterminated string"))
 		case '\\':
-			consumeEscape(sr.Next(), buf, end)
+			consumeEscape(sr, buf, end)
 		case '\n':
 			panic(errors.New("unterminated string"))
 		default:
@@ -342,23 +431,44 @@ func ConsumeString(sr *util.StringReader, end rune) string {
 	}
 }
 
-func consumeEscape(r rune, buf io.Writer, end rune) {
+var simpleEscapes = map[rune]rune{'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v', '\\': '\\'}
+
+func consumeEscape(sr *util.StringReader, buf io.Writer, end rune) {
+	r := sr.Next()
+	if e, ok := simpleEscapes[r]; ok {
+		util.WriteRune(buf, e)
+		return
+	}
 	switch r {
 	case 0:
 		panic(errors.New("unterminated string"))
-	case 'n':
-		r = '\n'
-	case 'r':
-		r = '\r'
-	case 't':
-		r = '\t'
-	case '\\':
+	case 'x':
+		// A hex escape denotes a byte, not a rune
+		_, _ = buf.Write([]byte{byte(consumeHexEscape(sr, 2))})
+	case 'u':
+		util.WriteRune(buf, consumeHexEscape(sr, 4))
+	case 'U':
+		util.WriteRune(buf, consumeHexEscape(sr, 8))
 	default:
 		if r != end {
 			panic(fmt.Errorf("illegal escape '\\%c'", r))
 		}
+		util.WriteRune(buf, r)
+	}
+}
+
+// consumeHexEscape consumes n hexadecimal digits and returns the rune that they denote
+func consumeHexEscape(sr *util.StringReader, n int) rune {
+	v := rune(0)
+	for i := 0; i < n; i++ {
+		r := sr.Next()
+		if !IsHex(r) {
+			panic(errors.New("illegal hex escape"))
+		}
+		d, _ := strconv.ParseInt(string(r), 16, 32)
+		v = v<<4 | rune(d)
 	}
-	util.WriteRune(buf, r)
+	return v
 }
 
 func consumeRawString(sr *util.StringReader) string {
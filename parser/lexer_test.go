@@ -120,3 +120,43 @@ func Example_nextToken() {
 	//'>'
 	//'}'
 }
+
+func Test_nextToken_comments(t *testing.T) {
+	sr := util.NewStringReader(`// first line
+// second line
+a // trailing
+/* block */ b
+
+// detached
+
+/*
+ * multi
+ * line
+ */
+c //`)
+	var ts []*Token
+	for tk := nextToken(sr); tk.Type != end; tk = nextToken(sr) {
+		ts = append(ts, tk)
+	}
+	if len(ts) != 4 {
+		t.Fatalf(`expected 4 tokens, got %d`, len(ts))
+	}
+	for i, doc := range []string{"first line\nsecond line", `block`, "multi\nline", ``} {
+		if ts[i].Doc != doc {
+			t.Errorf(`expected doc %q, got %q`, doc, ts[i].Doc)
+		}
+	}
+	if ts[3].Type != regexpLiteral || ts[3].Value != `` {
+		t.Errorf(`expected an empty regexp, got %s`, tokenString(ts[3]))
+	}
+}
+
+func Test_nextToken_unterminatedComment(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		if !(ok && err.Error() == `unterminated comment`) {
+			t.Error(`expected panic did no occur`)
+		}
+	}()
+	nextToken(util.NewStringReader(`/* x`))
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/lyraproj/dgo/dgo"
 	"github.com/lyraproj/dgo/internal"
@@ -17,6 +19,7 @@ const (
 	exListComma = iota
 	exListEnd
 	exParamsComma
+	exArgsComma
 	exLeftBracket
 	exLeftParen
 	exRightBracket
@@ -25,6 +28,7 @@ const (
 	exIntOrFloat
 	exStringLiteral
 	exTypeExpression
+	exValue
 	exAliasRef
 	exEnd
 )
@@ -33,8 +37,12 @@ type unknownIdentifier struct {
 	dgo.Value
 }
 
-type optionalValue struct {
+// entryValue is the value of a parsed map entry that is optional, has a default value, or has annotations
+type entryValue struct {
 	dgo.Value
+	optional    bool
+	dflt        dgo.Value
+	annotations dgo.Map
 }
 
 // LexFunction returns the next Token from the given StringReader
@@ -50,6 +58,8 @@ func expect(state int) (s string) {
 	switch state {
 	case exParamsComma:
 		s = `one of ',' or ']'`
+	case exArgsComma:
+		s = `one of ',' or ')'`
 	case exLeftBracket:
 		s = `'['`
 	case exLeftParen:
@@ -70,6 +80,8 @@ func expect(state int) (s string) {
 		s = `a literal string`
 	case exTypeExpression:
 		s = `a type expression`
+	case exValue:
+		s = `a value literal`
 	case exAliasRef:
 		s = `an identifier`
 	case exEnd:
@@ -120,6 +132,35 @@ type (
 
 	parser struct {
 		Base
+
+		// inEntryValue is true while parsing the value of a map entry. A known identifier followed by '=' then
+		// starts a default value rather than an alias declaration.
+		inEntryValue bool
+
+		// recovering is true when errors in list elements are recorded in errs rather than stopping the parse.
+		// The fileName is used in the recorded errors, consumed is the last consumed token, open is the number
+		// of open brackets, and atEnd is set when the end of the content is reached while skipping tokens.
+		recovering bool
+		fileName   string
+		errs       Errors
+		consumed   *Token
+		open       int
+		atEnd      bool
+
+		// tree is set when the parser builds a syntax tree, see ParseTree
+		tree *treeBuilder
+
+		// importer resolves the import declarations of a module, see ParseModule. The imported namespaces are
+		// stored in imports, keyed by their qualifiers.
+		importer dgo.Importer
+		imports  map[string]dgo.Loader
+
+		// aliasTokens maps the name token of each alias declaration, and the first token of its value, to the
+		// name of the alias. It is used to find the alias that annotations belong to. Annotations that start the
+		// value of a map entry, i.e. the token valueStart, belong to the entry and are stored in valueAnnotations.
+		aliasTokens      map[*Token]dgo.String
+		valueStart       *Token
+		valueAnnotations dgo.Map
 	}
 )
 
@@ -139,7 +180,21 @@ func Parse(content string) (result dgo.Value) {
 // ParseFile parses the given content into a dgo.Type. Aliases are added to the given AliasAdder. The filename
 // is used in error messages.
 func ParseFile(am dgo.AliasAdder, fileName, content string) dgo.Value {
-	p := &parser{NewParserBase(am, nextToken, content)}
+	p := &parser{Base: NewParserBase(am, nextToken, content)}
+	return DoParse(p, fileName)
+}
+
+// ParseModule is like ParseFile but the content may start with import declarations. An import declaration is the
+// keyword import followed by an optional qualifier and the path of the imported namespace in quotes, e.g.
+//
+//	import "common/net"
+//	import n "common/net"
+//
+// The qualifier defaults to the last segment of the path. The namespace is obtained from the given importer, and
+// the names in it can then be referenced by the qualifier followed by a '.' and the name, e.g. net.port. Aliases
+// declared in the content are added to the given AliasAdder and never to the imported namespaces.
+func ParseModule(am dgo.AliasAdder, importer dgo.Importer, fileName, content string) dgo.Value {
+	p := &parser{Base: NewParserBase(am, nextToken, content), importer: importer}
 	return DoParse(p, fileName)
 }
 
@@ -147,25 +202,7 @@ func ParseFile(am dgo.AliasAdder, fileName, content string) dgo.Value {
 func DoParse(p Parser, fileName string) dgo.Value {
 	defer func() {
 		if r := recover(); r != nil {
-			es := r
-			if err, ok := r.(error); ok {
-				es = err.Error()
-			}
-			tl := 1
-			lt := p.LastToken()
-			if lt != nil && lt.Value != `` {
-				tl = len(lt.Value)
-			}
-			fn := ``
-			if fileName != `` {
-				fn = fmt.Sprintf(`file: %s, `, fileName)
-			}
-			ln := ``
-			sr := p.StringReader()
-			if fileName != `` || sr.Line() > 1 {
-				ln = fmt.Sprintf(`line: %d, `, sr.Line())
-			}
-			panic(fmt.Errorf("%s: (%s%scolumn: %d)", es, fn, ln, sr.Column()-tl))
+			panic(newError(p, fileName, r))
 		}
 	}()
 	p.Parse(p.NextToken())
@@ -253,6 +290,14 @@ func (p *Base) StringReader() *util.StringReader {
 
 // Parse performs the actual parsing, starting at the given token
 func (p *parser) Parse(t *Token) {
+	for t.Type == identifier && t.Value == `import` {
+		n := p.PeekToken().Type
+		if n != stringLiteral && n != identifier {
+			break
+		}
+		p.importDeclaration()
+		t = p.NextToken()
+	}
 	p.anyOf(t)
 	tk := p.NextToken()
 	if tk.Type != end {
@@ -260,6 +305,63 @@ func (p *parser) Parse(t *Token) {
 	}
 }
 
+// importDeclaration parses the qualifier and the path of an import declaration and imports the namespace
+func (p *parser) importDeclaration() {
+	t := p.NextToken()
+	q := ``
+	if t.Type == identifier {
+		q = t.Value
+		t = p.NextToken()
+	}
+	if t.Type != stringLiteral {
+		panic(badSyntax(t, exStringLiteral))
+	}
+	path := t.Value
+	if q == `` {
+		q = path[strings.LastIndexByte(path, '/')+1:]
+		if !validIdentifier(q) {
+			panic(fmt.Errorf(`import of %q requires a qualifier`, path))
+		}
+	}
+	if _, ok := p.imports[q]; ok {
+		panic(fmt.Errorf(`attempt to redeclare import qualifier '%s'`, q))
+	}
+	var ns dgo.Loader
+	if p.importer != nil {
+		ns = p.importer(path)
+	}
+	if ns == nil {
+		panic(fmt.Errorf(`unable to import %q`, path))
+	}
+	if p.imports == nil {
+		p.imports = make(map[string]dgo.Loader)
+	}
+	p.imports[q] = ns
+}
+
+// qualifiedReference returns the type that the given qualifier and the identifier that follows it references
+func (p *parser) qualifiedReference(q *Token) dgo.Type {
+	p.NextToken() // skip '.'
+	t := p.NextToken()
+	if t.Type != identifier {
+		panic(badSyntax(t, exAliasRef))
+	}
+	if tp, ok := p.imports[q.Value].Get(t.Value).(dgo.Type); ok {
+		return tp
+	}
+	panic(fmt.Errorf(`reference to unresolved type '%s.%s'`, q.Value, t.Value))
+}
+
+// validIdentifier returns true if the given string is a valid identifier
+func validIdentifier(s string) bool {
+	for i, r := range s {
+		if !(IsIdentifierStart(r) || i > 0 && IsIdentifier(r)) {
+			return false
+		}
+	}
+	return s != ``
+}
+
 func (p *parser) list(endChar int) {
 	szp := p.Len()
 	ellipsis := false
@@ -267,36 +369,39 @@ func (p *parser) list(endChar int) {
 	if endChar == '}' {
 		expectEntry = 1
 	}
-	for {
-		t := p.NextToken()
-		if t.Type == dotdotdot {
-			ellipsis = true
-			t = p.NextToken()
-			if t.Type == endChar && expectEntry != 0 {
-				break
+	for done := false; !done; {
+		done = p.recoverElement(endChar, func() bool {
+			t := p.NextToken()
+			if t.Type == dotdotdot {
+				ellipsis = true
+				t = p.NextToken()
+				if t.Type == endChar && expectEntry != 0 {
+					return true
+				}
+				if expectEntry == 2 {
+					panic(badSyntax(t, exListEnd))
+				}
+				expectEntry = 0
+				p.anyOf(t)
+				t = p.NextToken()
+				et := internal.ArrayType([]interface{}{p.PopLastType(), 0, math.MaxInt64})
+				p.Append(et)
 			}
-			if expectEntry == 2 {
-				panic(badSyntax(t, exListEnd))
+
+			if t.Type == endChar {
+				// Right bracket instead of element indicates an empty array or an extraneous comma. Both are OK
+				return true
 			}
-			expectEntry = 0
-			p.anyOf(t)
+			expectEntry = p.arrayElement(t, expectEntry)
 			t = p.NextToken()
-			et := internal.ArrayType([]interface{}{p.PopLastType(), 0, math.MaxInt64})
-			p.Append(et)
-		}
-
-		if t.Type == endChar {
-			// Right bracket instead of element indicates an empty array or an extraneous comma. Both are OK
-			break
-		}
-		expectEntry = p.arrayElement(t, expectEntry)
-		t = p.NextToken()
-		if t.Type == endChar {
-			break
-		}
-		if t.Type != ',' {
-			panic(badSyntax(t, exListComma))
-		}
+			if t.Type == endChar {
+				return true
+			}
+			if t.Type != ',' {
+				panic(badSyntax(t, exListComma))
+			}
+			return false
+		})
 	}
 
 	as := p.From(szp)
@@ -350,15 +455,26 @@ func makeStructType(as []dgo.Value, ellipsis bool) dgo.MapType {
 		if !isType {
 			kt = k.Type()
 		}
-		ov, optional := v.(*optionalValue)
-		if optional {
-			v = ov.Value
+		ev, ok := v.(*entryValue)
+		if ok {
+			v = ev.Value
+		} else {
+			ev = &entryValue{}
 		}
 		vt, isType := v.(dgo.Type)
 		if !isType {
 			vt = v.Type()
 		}
-		entries[i] = internal.StructMapEntry(kt, vt, !optional)
+		var e dgo.StructMapEntry
+		if ev.dflt != nil {
+			e = internal.StructMapEntryWithDefault(kt, vt, ev.dflt)
+		} else {
+			e = internal.StructMapEntry(kt, vt, !ev.optional)
+		}
+		if ev.annotations != nil {
+			e = internal.AnnotatedStructMapEntry(e, ev.annotations)
+		}
+		entries[i] = e
 	}
 	return internal.StructMapTypeUnresolved(ellipsis, entries)
 }
@@ -386,6 +502,13 @@ func (p *parser) params() {
 }
 
 func (p *parser) arrayElement(t *Token, expectEntry int) int {
+	var annotations dgo.Map
+	start := t
+	mark := p.nodeMark()
+	if t.Type == '@' {
+		annotations = p.annotations()
+		t = p.NextToken()
+	}
 	var key dgo.Value
 	nt := p.PeekToken()
 	if t.Type == identifier && nt.Type == ':' || nt.Type == '?' {
@@ -393,6 +516,7 @@ func (p *parser) arrayElement(t *Token, expectEntry int) int {
 			panic(errors.New(`mix of elements and map entries`))
 		}
 		key = internal.String(t.Value)
+		p.leaf(LiteralNode, t, key)
 	} else {
 		p.anyOf(t)
 		nt = p.PeekToken()
@@ -412,23 +536,168 @@ func (p *parser) arrayElement(t *Token, expectEntry int) int {
 		if key == nil {
 			key = p.PopLast()
 		}
-		p.anyOf(p.NextToken())
+		ev := p.inEntryValue
+		p.inEntryValue = true
+		p.valueStart = p.NextToken()
+		p.anyOf(p.valueStart)
+		p.inEntryValue = ev
 		val := p.PopLast()
-		if optional {
-			val = &optionalValue{val}
+		if va := p.valueAnnotations; va != nil {
+			if annotations != nil {
+				va = va.Merge(annotations)
+			}
+			annotations = va
+			p.valueAnnotations = nil
+		}
+		var dflt dgo.Value
+		if p.PeekToken().Type == '=' {
+			if !optional {
+				panic(errors.New(`default value is only allowed for optional entries`))
+			}
+			p.NextToken()
+			p.anyOf(p.NextToken())
+			dflt = literalValue(p.PopLast())
+		}
+		annotations = withDoc(annotations, start.Doc)
+		if optional || annotations != nil {
+			val = &entryValue{Value: val, optional: optional, dflt: dflt, annotations: annotations}
 		}
 		p.Append(internal.NewMapEntry(key, val))
+		p.reduceLast(EntryNode, mark, start)
 		expectEntry = 2
 	} else {
 		if expectEntry == 2 {
 			panic(errors.New(`mix of elements and map entries`))
 		}
 		expectEntry = 0
+		if annotations != nil {
+			p.annotate(withDoc(annotations, start.Doc), start, t, false)
+			p.reduceLast(TypeNode, mark, start)
+		}
 	}
 	return expectEntry
 }
 
+// annotations parses a sequence of annotations in the form @<identifier>[(<value>[,<value>...])], starting after
+// the first '@', and returns them as a frozen map. An annotation without arguments has the value true, an annotation
+// with one argument has that argument as its value, and an annotation with several arguments has an array value. The
+// value of the "examples" annotation is always an array.
+func (p *parser) annotations() dgo.Map {
+	start := p.consumed
+	mark := p.nodeMark()
+	m := internal.MapWithCapacity(2)
+	for {
+		t := p.NextToken()
+		if t.Type != identifier {
+			panic(badSyntax(t, exAliasRef))
+		}
+		var v dgo.Value = internal.True
+		if p.PeekToken().Type == '(' {
+			p.NextToken()
+			args := p.annotationArgs()
+			if len(args) > 0 || t.Value == `examples` {
+				if len(args) == 1 && t.Value != `examples` {
+					v = args[0]
+				} else {
+					v = internal.WrapSlice(args).FrozenCopy()
+				}
+			}
+		}
+		m.Put(t.Value, v)
+		if p.PeekToken().Type != '@' {
+			break
+		}
+		p.NextToken()
+	}
+	internal.CheckAnnotations(m)
+	m = m.FrozenCopy().(dgo.Map)
+	p.reduce(AnnotationsNode, mark, start, m)
+	return m
+}
+
+func (p *parser) annotationArgs() []dgo.Value {
+	szp := p.Len()
+	for {
+		t := p.NextToken()
+		if t.Type == ')' {
+			break
+		}
+		p.anyOf(t)
+		t = p.NextToken()
+		if t.Type == ')' {
+			break
+		}
+		if t.Type != ',' {
+			panic(badSyntax(t, exArgsComma))
+		}
+	}
+	args := p.From(szp)
+	vs := make([]dgo.Value, len(args))
+	for i := range args {
+		vs[i] = literalValue(args[i])
+	}
+	p.d = p.d[:szp]
+	return vs
+}
+
+// annotate associates the given annotations with the alias that is declared by the expression that starts with
+// the token first, or that has the expression that starts with the annotations token at as its value. When there
+// is no such alias, the annotations are stored in valueAnnotations if entryValue is true and the annotations start
+// the value of a map entry. Otherwise, they are annotations of the anonymous type that was parsed last.
+func (p *parser) annotate(annotations dgo.Map, at, first *Token, entryValue bool) {
+	n, ok := p.aliasTokens[first]
+	if !ok {
+		n, ok = p.aliasTokens[at]
+	}
+	switch {
+	case entryValue && !ok && at == p.valueStart:
+		p.valueAnnotations = annotations
+	case p.sc == nil:
+		internal.CheckAnnotations(annotations)
+	case ok:
+		p.sc.Annotate(n, annotations)
+	default:
+		v := p.From(p.Len() - 1)[0]
+		t, ok := v.(dgo.Type)
+		if !ok {
+			t = v.Type()
+		}
+		p.sc.AnnotateType(t, annotations)
+	}
+}
+
+// withDoc returns the given annotations with a description annotation that contains the given documentation. The
+// annotations are returned unchanged when the documentation is empty or when they already contain a description.
+func withDoc(annotations dgo.Map, doc string) dgo.Map {
+	if doc == `` || annotations != nil && annotations.ContainsKey(`description`) {
+		return annotations
+	}
+	m := internal.MapWithCapacity(1)
+	if annotations != nil {
+		m.PutAll(annotations)
+	}
+	m.Put(`description`, doc)
+	return m.FrozenCopy().(dgo.Map)
+}
+
+// literalValue returns the value that a parsed literal value expression represents
+func literalValue(v dgo.Value) dgo.Value {
+	if et, ok := v.(dgo.ExactType); ok && dgo.IsExact(et) {
+		return et.ExactValue()
+	}
+	return v
+}
+
 func (p *parser) anyOf(t *Token) {
+	mark := p.nodeMark()
+	if t.Type == '@' {
+		annotations := withDoc(p.annotations(), t.Doc)
+		first := p.NextToken()
+		p.anyOf(first)
+		p.annotate(annotations, t, first, true)
+		p.reduceLast(TypeNode, mark, t)
+		return
+	}
 	p.oneOf(t)
 	if p.PeekToken().Type == '|' {
 		szp := p.Len() - 1
@@ -437,6 +706,7 @@ func (p *parser) anyOf(t *Token) {
 			p.oneOf(p.NextToken())
 			if p.PeekToken().Type != '|' {
 				p.AppendFrom(szp, internal.AnyOfType(allTypes(p.From(szp))))
+				p.reduceLast(UnionNode, mark, nil)
 				break
 			}
 		}
@@ -444,6 +714,7 @@ func (p *parser) anyOf(t *Token) {
 }
 
 func (p *parser) oneOf(t *Token) {
+	mark := p.nodeMark()
 	p.allOf(t)
 	if p.PeekToken().Type == '^' {
 		szp := p.Len() - 1
@@ -452,6 +723,7 @@ func (p *parser) oneOf(t *Token) {
 			p.allOf(p.NextToken())
 			if p.PeekToken().Type != '^' {
 				p.AppendFrom(szp, internal.OneOfType(allTypes(p.From(szp))))
+				p.reduceLast(OneOfNode, mark, nil)
 				break
 			}
 		}
@@ -459,6 +731,7 @@ func (p *parser) oneOf(t *Token) {
 }
 
 func (p *parser) allOf(t *Token) {
+	mark := p.nodeMark()
 	p.unary(t)
 	if p.PeekToken().Type == '&' {
 		szp := p.Len() - 1
@@ -467,6 +740,7 @@ func (p *parser) allOf(t *Token) {
 			p.unary(p.NextToken())
 			if p.PeekToken().Type != '&' {
 				p.AppendFrom(szp, internal.AllOfType(allTypes(p.From(szp))))
+				p.reduceLast(AllOfNode, mark, nil)
 				break
 			}
 		}
@@ -474,6 +748,8 @@ func (p *parser) allOf(t *Token) {
 }
 
 func (p *parser) unary(t *Token) {
+	start := t
+	mark := p.nodeMark()
 	negate := false
 	ciString := false
 	if t.Type == '!' {
@@ -498,6 +774,9 @@ func (p *parser) unary(t *Token) {
 		nt := internal.NotType(p.PopLastType())
 		p.Append(nt)
 	}
+	if start != t {
+		p.reduceLast(TypeNode, mark, start)
+	}
 }
 
 func (p *parser) mapExpression() dgo.Value {
@@ -606,6 +885,17 @@ var identifierToTypeMap = map[string]dgo.Value{
 	`nil`:    internal.Nil,
 }
 
+// Keywords returns the sorted identifiers that the parser recognizes as built-in types, type constructors, or
+// literals
+func Keywords() []string {
+	ks := []string{`map`, `type`, `string`, `sensitive`, `func`}
+	for k := range identifierToTypeMap {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
 func (p *parser) identifier(t *Token, returnUnknown bool) dgo.Value {
 	tp, ok := identifierToTypeMap[t.Value]
 	if ok {
@@ -727,17 +1017,43 @@ func (p *parser) aliasReference(t *Token) dgo.Value {
 	return NewAlias(vn)
 }
 
+// declaresAlias returns true if the given identifier, which is followed by '=', starts an alias declaration. In the
+// value of a map entry, the '=' instead starts a default value unless the identifier is unknown.
+func (p *parser) declaresAlias(t *Token) bool {
+	if !p.inEntryValue {
+		return true
+	}
+	if _, ok := identifierToTypeMap[t.Value]; ok {
+		return false
+	}
+	switch t.Value {
+	case `map`, `type`, `string`, `sensitive`, `func`:
+		return false
+	}
+	return internal.NamedType(t.Value) == nil && (p.sc == nil || p.sc.GetType(internal.String(t.Value)) == nil)
+}
+
 func (p *parser) aliasDeclaration(t *Token) dgo.Value {
 	// Should result in an unknown identifier or name is reserved
 	tp := p.identifier(t, true)
 	if un, ok := tp.(*unknownIdentifier); ok {
 		s := un.Value.(dgo.String)
 		if internal.NamedType(s.GoString()) == nil && p.sc.GetType(s) == nil {
+			p.leaf(IdentifierNode, t, s)
 			p.NextToken() // skip '='
 			p.sc.Add(NewAlias(s), s)
-			p.anyOf(p.NextToken())
+			vt := p.NextToken()
+			if p.aliasTokens == nil {
+				p.aliasTokens = make(map[*Token]dgo.String)
+			}
+			p.aliasTokens[t] = s
+			p.aliasTokens[vt] = s
+			p.anyOf(vt)
 			tp = p.PopLastType()
 			p.sc.Add(tp.(dgo.Type), s)
+			if t.Doc != `` {
+				p.sc.Annotate(s, withDoc(nil, t.Doc))
+			}
 			return tp
 		}
 	}
@@ -747,12 +1063,28 @@ func (p *parser) aliasDeclaration(t *Token) dgo.Value {
 func (p *parser) typeExpression(t *Token) {
 	var tp dgo.Value
 
+	mark := p.nodeMark()
+	kind := TypeNode
 	switch t.Type {
 	case '{':
+		ev := p.inEntryValue
+		p.inEntryValue = false
 		p.list('}')
+		p.inEntryValue = ev
+		p.reduceLast(CollectionNode, mark, t)
+		return
+	case '@':
+		annotations := withDoc(p.annotations(), t.Doc)
+		first := p.NextToken()
+		p.typeExpression(first)
+		p.annotate(annotations, t, first, false)
+		p.reduceLast(TypeNode, mark, t)
 		return
 	case '(':
+		ev := p.inEntryValue
+		p.inEntryValue = false
 		p.anyOf(p.NextToken())
+		p.inEntryValue = ev
 		n := p.NextToken()
 		if n.Type != ')' {
 			panic(badSyntax(n, exRightParen))
@@ -766,26 +1098,49 @@ func (p *parser) typeExpression(t *Token) {
 		if n.Type != '>' {
 			panic(badSyntax(n, exRightAngle))
 		}
+		kind = IdentifierNode
 	case integer:
 		tp = p.integer(t)
+		kind = literalOrRange(t, p.consumed)
 	case float:
 		tp = p.float(t)
+		kind = literalOrRange(t, p.consumed)
 	case dotdot, dotdotdot: // Unbounded at lower end
 		tp = p.dotRange(t)
+		kind = RangeNode
 	case identifier:
-		if p.PeekToken().Type == '=' {
+		if _, ok := p.imports[t.Value]; ok && p.PeekToken().Type == '.' {
+			tp = p.qualifiedReference(t)
+			kind = IdentifierNode
+		} else if p.PeekToken().Type == '=' && p.declaresAlias(t) {
 			tp = p.aliasDeclaration(t)
+			kind = AliasNode
 		} else {
 			tp = p.identifier(t, false)
+			if p.consumed == t {
+				kind = IdentifierNode
+			}
 		}
 	case stringLiteral:
 		tp = internal.String(t.Value)
+		kind = LiteralNode
 	case regexpLiteral:
 		tp = internal.PatternType(regexp.MustCompile(t.Value))
+		kind = LiteralNode
 	default:
 		panic(badSyntax(t, exTypeExpression))
 	}
 	p.Append(tp)
+	p.reduceLast(kind, mark, t)
+}
+
+// literalOrRange returns LiteralNode if the number expression that started with the given token ended with it
+// and RangeNode otherwise
+func literalOrRange(start, last *Token) NodeKind {
+	if start == last {
+		return LiteralNode
+	}
+	return RangeNode
 }
 
 func tokenInt(t *Token) int64 {
@@ -12,6 +12,7 @@ import (
 	"github.com/lyraproj/dgo/dgo"
 
 	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/loader"
 	"github.com/lyraproj/dgo/tf"
 	"github.com/lyraproj/dgo/typ"
 	"github.com/lyraproj/dgo/vf"
@@ -199,6 +200,7 @@ func TestParse_string(t *testing.T) {
 	require.Panic(t, func() { tf.ParseType(`"\`) }, `unterminated string`)
 	require.Panic(t, func() { tf.ParseType(`"\"`) }, `unterminated string`)
 	require.Panic(t, func() { tf.ParseType(`"\y"`) }, `illegal escape`)
+	require.Panic(t, func() { tf.ParseType(`"\u12"`) }, `illegal hex escape`)
 	require.Panic(t, func() {
 		tf.ParseType(`["
 "`)
@@ -207,6 +209,12 @@ func TestParse_string(t *testing.T) {
 	require.Panic(t, func() { tf.ParseType("`x") }, `unterminated string`)
 }
 
+func TestParse_stringEscapes(t *testing.T) {
+	require.Equal(t, vf.String("\a\b\f\v\\").Type(), tf.ParseType(`"\a\b\f\v\\"`))
+	require.Equal(t, vf.String("\x00\xff").Type(), tf.ParseType(`"\x00\xff"`))
+	require.Equal(t, vf.String("\u00e5\U0001F600").Type(), tf.ParseType(`"\u00e5\U0001F600"`))
+}
+
 func TestParse_multiAliases(t *testing.T) {
 	var tp dgo.StructMapType
 	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
@@ -229,6 +237,136 @@ func TestParse_mapKeyAliases(t *testing.T) {
 	require.Equal(t, `{tp,{tp:2}}`, stringer.TypeString(tp))
 }
 
+func TestParse_entryDefault(t *testing.T) {
+	tp := tf.ParseType(`{a?:int=1,b?:string|int="x",c?:[]int={1,2},d?:type=int,e?:(defaultAlias=1..3)=2}`)
+	require.Equal(t, `{"a"?:int=1,"b"?:string|int="x","c"?:[]int={1,2},"d"?:type=int,"e"?:defaultAlias=2}`, stringer.TypeString(tp))
+	require.Equal(t, vf.Map(`a`, 1, `b`, `x`, `c`, vf.Values(1, 2), `d`, typ.Integer, `e`, 2), tp.(dgo.StructMapType).ApplyDefaults(vf.Map()))
+	require.Equal(t, tp, tf.ParseType(stringer.TypeString(tp)))
+
+	require.Panic(t, func() { tf.ParseType(`{a:int=1}`) }, `default value is only allowed for optional entries`)
+	require.Panic(t, func() { tf.ParseType(`{a?:1..5=0}`) }, `cannot be assigned`)
+	require.Panic(t, func() { tf.ParseType(`defaultRec={a?:defaultRec=1}`) }, `cannot be assigned`)
+}
+
+func TestParse_entryDefaultAlias(t *testing.T) {
+	tp := tf.ParseType(`{a?:defaultRange=1..5=3,b:defaultRange}`).(dgo.StructMapType)
+	require.Equal(t, 3, tp.Get(`a`).Default())
+	require.Equal(t, tf.Integer(1, 5, true), tp.Get(`b`).Value())
+}
+
+func TestParse_annotations(t *testing.T) {
+	tp := tf.ParseType(`{
+    @description("the host") host:string,
+    @description("the port") @examples(80,443) @deprecated("use url") port?:1..65535=8080,
+    @deprecated @owner({"team":"a"}) "mode"?:"tcp"|"udp"
+  }`).(dgo.StructMapType)
+	require.Equal(t, vf.Map(`description`, `the host`), tp.Get(`host`).Annotations())
+	require.Equal(t, vf.Map(`description`, `the port`, `examples`, vf.Values(80, 443), `deprecated`, `use url`), tp.Get(`port`).Annotations())
+	require.Equal(t, vf.Map(`deprecated`, true, `owner`, vf.Map(`team`, `a`)), tp.Get(`mode`).Annotations())
+	require.Equal(t, 8080, tp.Get(`port`).Default())
+
+	s := stringer.TypeString(tp)
+	require.Equal(t, `{@description("the host") "host":string,`+
+		`@description("the port") @examples(80,443) @deprecated("use url") "port"?:1..65535=8080,`+
+		`@deprecated @owner({"team":"a"}) "mode"?:"tcp"|"udp"}`, s)
+	require.Equal(t, tp.Get(`port`).Annotations(), tf.ParseType(s).(dgo.StructMapType).Get(`port`).Annotations())
+
+	// Annotations are not considered by Equals or Assignable
+	ut := tf.ParseType(`{host:string,port?:1..65535=8080,"mode"?:"tcp"|"udp"}`)
+	require.Equal(t, ut, tp)
+	require.Assignable(t, ut, tp)
+	require.Assignable(t, tp, ut)
+}
+
+func TestParse_annotatedTypes(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `@description("positive") @examples(1) posOrName=1..|string[1]`)
+	})
+	require.Equal(t, vf.Map(`description`, `positive`, `examples`, vf.Values(1)), am.Annotations(vf.String(`posOrName`)))
+
+	am = tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `{@examples() oneOrTwo=1..2, three=@deprecated 3}`)
+	})
+	require.Equal(t, vf.Map(`examples`, vf.Values()), am.Annotations(vf.String(`oneOrTwo`)))
+	require.Equal(t, vf.Map(`deprecated`, true), am.Annotations(vf.String(`three`)))
+	require.Nil(t, am.Annotations(vf.String(`int`)))
+
+	// annotations in front of the value of a map entry belong to the entry
+	st := tf.ParseType(`{@deprecated name: @description("the name") string[1]}`).(dgo.StructMapType)
+	require.Equal(t, vf.Map(`description`, `the name`, `deprecated`, true), st.Get(`name`).Annotations())
+
+	// annotations of anonymous types are kept by the alias map
+	am = tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `{@deprecated 3, x=[]@description("names") string[1], (@examples("b") "b"|"c")}`)
+	})
+	require.Equal(t, vf.Map(`deprecated`, true), am.TypeAnnotations(tf.ParseType(`3`)))
+	require.Equal(t, vf.Map(`description`, `names`), am.TypeAnnotations(tf.String(1)))
+	require.Equal(t, vf.Map(`examples`, vf.Values(`b`)), am.TypeAnnotations(tf.ParseType(`"b"|"c"`)))
+	require.Equal(t, tf.Array(tf.String(1)), am.GetType(vf.String(`x`)))
+	require.Nil(t, am.Annotations(vf.String(`x`)))
+	require.Nil(t, am.TypeAnnotations(typ.String))
+	require.Equal(t, `{@deprecated 3,x}`, stringer.TypeStringWithAliasMap(tf.Tuple(vf.Integer(3).Type(), am.GetType(vf.String(`x`))), am))
+	require.Equal(t, `@description("names") string[1]`, stringer.TypeStringWithAliasMap(tf.String(1), am))
+
+	// the default alias map doesn't keep annotations of anonymous types
+	require.Equal(t, typ.Integer, tf.ParseType(`@deprecated("nope") int`))
+	require.Nil(t, tf.DefaultAliases().TypeAnnotations(typ.Integer))
+	require.Equal(t, typ.Integer, tf.ParseFile(nil, ``, `@description("x") int`))
+	require.Nil(t, typ.Annotations(`int`))
+}
+
+func TestParse_comments(t *testing.T) {
+	tp := tf.ParseType(`/* a struct */ {
+    // the host
+    host: string, // not documentation
+
+    /*
+     * the port
+     */
+    @examples(80,443) port?: 1..65535=8080,
+    // explicit description wins
+    @description("the mode") mode?: "tcp"|"udp",
+
+    // detached comment
+
+    path: /\//,
+    empty: //, opt?: int
+  }`).(dgo.StructMapType)
+	require.Equal(t, vf.Map(`description`, `the host`), tp.Get(`host`).Annotations())
+	require.Equal(t, vf.Map(`examples`, vf.Values(80, 443), `description`, `the port`), tp.Get(`port`).Annotations())
+	require.Equal(t, vf.Map(`description`, `the mode`), tp.Get(`mode`).Annotations())
+	require.Nil(t, tp.Get(`path`).Annotations())
+	require.Equal(t, tf.Pattern(regexp.MustCompile(`/`)), tp.Get(`path`).Value())
+	require.Equal(t, tf.Pattern(regexp.MustCompile(``)), tp.Get(`empty`).Value())
+	require.Equal(t, 6, tp.Len())
+
+	// the empty regexp may be followed by a line break
+	require.Equal(t, tf.StructMap(false, tf.StructMapEntry(`x`, tf.Pattern(regexp.MustCompile(``)), true)), tf.ParseType("{\n x://\n}"))
+	require.Equal(t, tf.Array(tf.Pattern(regexp.MustCompile(``))), tf.ParseType("[]//\n"))
+	require.Equal(t, tf.StructMap(false, tf.StructMapEntry(`x`, typ.String, true)), tf.ParseType("{\n x: //\n // the x\n string\n}"))
+}
+
+func TestParse_aliasComments(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `{
+  // a commented slug
+  cmSlug=/^[a-z0-9-]+$/,
+
+  // a commented port
+  @examples(80) cmPort=1..65535
+}`)
+	})
+	require.Equal(t, vf.Map(`description`, `a commented slug`), am.Annotations(vf.String(`cmSlug`)))
+	require.Equal(t, vf.Map(`examples`, vf.Values(80), `description`, `a commented port`), am.Annotations(vf.String(`cmPort`)))
+}
+
+func TestParse_annotationErrors(t *testing.T) {
+	require.Panic(t, func() { tf.ParseType(`@3 int`) }, `expected an identifier, got 3`)
+	require.Panic(t, func() { tf.ParseType(`@x(1 2) int`) }, `expected one of ',' or '\)', got 2`)
+	require.Panic(t, func() { tf.ParseType(`@description(1) int`) }, `cannot be assigned`)
+	require.Panic(t, func() { tf.ParseType(`{@deprecated(1) a:int}`) }, `cannot be assigned`)
+}
+
 func TestParse_errors(t *testing.T) {
 	require.Panic(t, func() { tf.ParseType(`[1 23]`) }, `expected one of ',' or '\]', got 23: \(column: 4\)`)
 	require.Panic(t, func() { tf.ParseType(`map{}`) }, `expected '\[', got '\{': \(column: 4\)`)
@@ -271,3 +409,57 @@ func TestParseFile_errors(t *testing.T) {
 func TestParse_value(t *testing.T) {
 	require.Equal(t, vf.Map(), tf.Parse(`{}`))
 }
+
+func TestParseModule(t *testing.T) {
+	net := loader.New(nil, `net`, vf.Map(`port`, tf.Integer(1, 65535, true), `host`, typ.String), nil, nil)
+	importer := func(path string) dgo.Loader {
+		if path == `common/net` {
+			return net
+		}
+		return nil
+	}
+	var tp dgo.Value
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tp = tf.ParseModule(aa, importer, `server.dgo`, `
+import "common/net"
+import n "common/net"
+{
+  host: n.host,
+  port: modPort=net.port,
+  ports: []modPort
+}`)
+	})
+	require.Equal(t, tf.StructMap(false,
+		tf.StructMapEntry(`host`, typ.String, true),
+		tf.StructMapEntry(`port`, tf.Integer(1, 65535, true), true),
+		tf.StructMapEntry(`ports`, tf.Array(tf.Integer(1, 65535, true)), true)), tp)
+
+	// the declared alias is not added to the imported namespace
+	require.True(t, net.Get(`modPort`) == nil)
+}
+
+func TestParseModule_errors(t *testing.T) {
+	net := loader.New(nil, `net`, vf.Map(`port`, tf.Integer(1, 65535, true), `data`, `not a type`), nil, nil)
+	importer := func(path string) dgo.Loader {
+		if path == `net` || path == `my-net` {
+			return net
+		}
+		return nil
+	}
+	parse := func(content string) func() {
+		return func() { tf.ParseModule(nil, importer, `x.dgo`, content) }
+	}
+	require.Panic(t, parse(`import "other" int`), `unable to import "other": \(file: x\.dgo, line: 1, column: 8\)`)
+	require.Panic(t, parse(`import "my-net" int`), `import of "my-net" requires a qualifier`)
+	require.Panic(t, parse(`import "net" import "net" int`), `attempt to redeclare import qualifier 'net'`)
+	require.Panic(t, parse(`import n 3 int`), `expected a literal string, got 3`)
+	require.Panic(t, parse(`import "net" net.3`), `expected an identifier, got 3`)
+	require.Panic(t, parse(`import "net" net.host`), `reference to unresolved type 'net\.host'`)
+	require.Panic(t, parse(`import "net" net.data`), `reference to unresolved type 'net\.data'`)
+	require.Panic(t, func() { tf.ParseFile(nil, `x.dgo`, `import "net" int`) }, `unable to import "net"`)
+
+	// import is an alias unless it is followed by a string or an identifier
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		require.Equal(t, typ.Integer, tf.ParseModule(aa, importer, `x.dgo`, `import=int`))
+	})
+}
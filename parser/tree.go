@@ -0,0 +1,314 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+)
+
+// NodeKind tells what kind of syntax a Node represents
+type NodeKind int
+
+const (
+	// LiteralNode is a string, number, or regexp literal, or the identifier key of a struct map entry
+	LiteralNode NodeKind = iota
+
+	// IdentifierNode is the name of a built-in type or a reference to an alias
+	IdentifierNode
+
+	// AliasNode is an alias declaration. Its children are the name and the declared type
+	AliasNode
+
+	// EntryNode is a struct map entry. Its children are the annotations, if any, the key, the value, and the
+	// default value, if any
+	EntryNode
+
+	// CollectionNode is a struct map or a tuple enclosed in curly braces. Its children are the entries or elements
+	CollectionNode
+
+	// UnionNode is a list of types separated by '|'
+	UnionNode
+
+	// OneOfNode is a list of types separated by '^'
+	OneOfNode
+
+	// AllOfNode is a list of types separated by '&'
+	AllOfNode
+
+	// RangeNode is an integer or float range
+	RangeNode
+
+	// AnnotationsNode is a sequence of annotations. Its children are the annotation arguments
+	AnnotationsNode
+
+	// TypeNode is any other type expression, e.g. an array, a map, a parameterized type, a negated type, or an
+	// annotated type
+	TypeNode
+)
+
+var nodeKindNames = []string{
+	`literal`, `identifier`, `alias`, `entry`, `collection`, `union`, `oneOf`, `allOf`, `range`, `annotations`, `type`}
+
+func (k NodeKind) String() string {
+	if k >= 0 && int(k) < len(nodeKindNames) {
+		return nodeKindNames[k]
+	}
+	return fmt.Sprintf(`NodeKind(%d)`, int(k))
+}
+
+// Position is a position in the parsed content
+type Position struct {
+	// File is the name of the parsed file, or the empty string if the content didn't originate from a file
+	File string
+
+	// Offset is the byte offset, starting at 0
+	Offset int
+
+	// Line is the line, starting at 1
+	Line int
+
+	// Column is the column, counted in runes and starting at 1
+	Column int
+}
+
+func (p Position) String() string {
+	if p.File == `` {
+		return fmt.Sprintf(`%d:%d`, p.Line, p.Column)
+	}
+	return fmt.Sprintf(`%s:%d:%d`, p.File, p.Line, p.Column)
+}
+
+// Node is a node in the syntax tree that is produced by ParseTree
+type Node struct {
+	// Kind is the kind of syntax that the node represents
+	Kind NodeKind
+
+	// Start is the position of the first character of the node and End is the position that follows its last
+	// character
+	Start, End Position
+
+	// Doc is the text of the comments that document an entry or an alias declaration
+	Doc string
+
+	// Value is the value that the node evaluates to. The value of an EntryNode is a dgo.StructMapEntry and the
+	// value of an AnnotationsNode is a dgo.Map
+	Value dgo.Value
+
+	// Children are the nodes that this node is composed of, in source order
+	Children []*Node
+
+	// index maps each value that the parser produced to the node that it was produced for. It is shared by all
+	// nodes of a tree.
+	index map[dgo.Value]*Node
+}
+
+// Walk calls the given function with this node and then with each of its descendants in source order. The children
+// of a node are skipped when the function returns false for that node.
+func (n *Node) Walk(f func(*Node) bool) {
+	if f(n) {
+		for _, c := range n.Children {
+			c.Walk(f)
+		}
+	}
+}
+
+// At returns the innermost node that contains the given line and column, or nil if no such node exists
+func (n *Node) At(line, column int) *Node {
+	p := Position{Line: line, Column: column}
+	if before(p, n.Start) || !before(p, n.End) {
+		return nil
+	}
+	for _, c := range n.Children {
+		if f := c.At(line, column); f != nil {
+			return f
+		}
+	}
+	return n
+}
+
+// Find returns the node that the given value was produced for, or nil if the value wasn't produced for this node
+// or one of its descendants. It is typically used to find the source location of a type that failed a validation.
+//
+// Nodes are found by the identity of the value, so a type that is produced at several positions is found at the
+// right one. Types that have no identity of their own, e.g. the built-in string type, are found at the first
+// position that produced them. Entry finds the entry of a struct map regardless of its value.
+func (n *Node) Find(v dgo.Value) (found *Node) {
+	if v == nil || !reflect.TypeOf(v).Comparable() {
+		return nil
+	}
+	if f := n.index[v]; f != nil && !before(f.Start, n.Start) && !before(n.End, f.End) {
+		return f
+	}
+	n.Walk(func(c *Node) bool {
+		if found == nil && c.Value == v {
+			found = c
+		}
+		return found == nil
+	})
+	return
+}
+
+// Entry returns the EntryNode of the entry that has the given key when this node is a CollectionNode for a struct
+// map, or nil if there is no such entry.
+func (n *Node) Entry(key interface{}) *Node {
+	if n.Kind != CollectionNode {
+		return nil
+	}
+	kv := internal.Value(key)
+	for _, c := range n.Children {
+		if e, ok := c.Value.(dgo.StructMapEntry); ok && c.Kind == EntryNode {
+			k := e.Key()
+			if et, ok := k.(dgo.ExactType); ok {
+				k = et.ExactValue()
+			}
+			if k.Equals(kv) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func (n *Node) String() string {
+	return fmt.Sprintf(`%s[%s-%s]`, n.Kind, n.Start, n.End)
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// ParseTree parses the given content into a syntax tree. The nodes of the tree carry their source positions, the
+// documentation comments of entries and aliases, and the value that the parser evaluated them to. The value of the
+// returned root node is the same value that ParseFile returns for the content. Aliases are added to the given
+// AliasAdder. The filename is used in the positions and in error messages.
+//
+// The tree is recorded while the parser evaluates the content, together with an index of the values that the parser
+// produced for each node. Find uses that index to map a value back to its source.
+func ParseTree(am dgo.AliasAdder, fileName, content string) *Node {
+	p := &parser{Base: NewParserBase(am, nextToken, content), tree: newTreeBuilder(fileName, content)}
+	DoParse(p, fileName)
+	root := p.tree.nodes[len(p.tree.nodes)-1]
+	root.Walk(func(n *Node) bool {
+		if a, ok := n.Value.(dgo.Alias); ok && am != nil {
+			n.Value = am.Replace(a)
+			p.tree.record(n)
+		}
+		if st, ok := n.Value.(dgo.StructMapType); ok && n.Kind == CollectionNode {
+			assignEntries(n, st)
+		}
+		return true
+	})
+	root.Walk(func(n *Node) bool {
+		n.index = p.tree.index
+		return true
+	})
+	return root
+}
+
+// assignEntries assigns the entries of the given struct map type to the entry nodes of the given collection
+func assignEntries(n *Node, st dgo.StructMapType) {
+	i := 0
+	st.Each(func(e dgo.StructMapEntry) {
+		for ; i < len(n.Children); i++ {
+			if c := n.Children[i]; c.Kind == EntryNode {
+				c.Value = e
+				i++
+				break
+			}
+		}
+	})
+}
+
+// treeBuilder maintains a stack of completed nodes in the same way as the parser maintains its value stack
+type treeBuilder struct {
+	fileName   string
+	content    string
+	lineStarts []int
+	nodes      []*Node
+	index      map[dgo.Value]*Node
+}
+
+func newTreeBuilder(fileName, content string) *treeBuilder {
+	ls := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			ls = append(ls, i+1)
+		}
+	}
+	return &treeBuilder{fileName: fileName, content: content, lineStarts: ls, index: make(map[dgo.Value]*Node)}
+}
+
+// record adds the value of the given node to the index unless it has been recorded for a node that was completed
+// earlier, i.e. for a descendant of the node or for a node that precedes it. Values that cannot be map keys are not
+// recorded.
+func (tb *treeBuilder) record(n *Node) {
+	v := n.Value
+	if v == nil || !reflect.TypeOf(v).Comparable() {
+		return
+	}
+	if _, ok := tb.index[v]; !ok {
+		tb.index[v] = n
+	}
+}
+
+// position returns the Position of the given offset
+func (tb *treeBuilder) position(offset int) Position {
+	if offset > len(tb.content) {
+		offset = len(tb.content)
+	}
+	l := sort.Search(len(tb.lineStarts), func(i int) bool { return tb.lineStarts[i] > offset }) - 1
+	c := utf8.RuneCountInString(tb.content[tb.lineStarts[l]:offset]) + 1
+	return Position{File: tb.fileName, Offset: offset, Line: l + 1, Column: c}
+}
+
+// nodeMark returns the current length of the node stack
+func (p *parser) nodeMark() int {
+	if p.tree == nil {
+		return 0
+	}
+	return len(p.tree.nodes)
+}
+
+// leaf pushes a node that represents the given token onto the node stack
+func (p *parser) leaf(kind NodeKind, t *Token, v dgo.Value) {
+	if p.tree != nil {
+		tb := p.tree
+		n := &Node{Kind: kind, Start: tb.position(t.Pos), End: tb.position(t.End), Value: v}
+		tb.record(n)
+		tb.nodes = append(tb.nodes, n)
+	}
+}
+
+// reduce replaces the nodes on the node stack from the given mark with one node of the given kind that has those
+// nodes as its children. The node starts at the given token, or at its first child when the token is nil, and it
+// ends at the last consumed token.
+func (p *parser) reduce(kind NodeKind, mark int, start *Token, v dgo.Value) {
+	if p.tree == nil {
+		return
+	}
+	tb := p.tree
+	cs := make([]*Node, len(tb.nodes)-mark)
+	copy(cs, tb.nodes[mark:])
+	n := &Node{Kind: kind, End: tb.position(p.consumed.End), Value: v, Children: cs}
+	if start == nil {
+		n.Start = cs[0].Start
+	} else {
+		n.Start = tb.position(start.Pos)
+		if kind == EntryNode || kind == AliasNode || start.Type == '@' {
+			n.Doc = start.Doc
+		}
+	}
+	tb.record(n)
+	tb.nodes = append(tb.nodes[:mark], n)
+}
+
+// reduceLast calls reduce with the last value of the value stack
+func (p *parser) reduceLast(kind NodeKind, mark int, start *Token) {
+	if p.tree != nil {
+		p.reduce(kind, mark, start, p.d[len(p.d)-1])
+	}
+}
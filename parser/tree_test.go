@@ -0,0 +1,187 @@
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+const treeSource = `{
+  // the slug
+  treeSlug=/^[a-z]+$/,
+  treeNode={
+    // the name
+    name: treeSlug,
+    @deprecated port?: 1..65535=80,
+    kids?: []treeNode,
+    kind: "a"|"b"|!string[3]&~"x"
+  }
+}`
+
+func parseTree(src string) (n *parser.Node) {
+	tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		n = parser.ParseTree(aa, `tree.dgo`, src)
+	})
+	return
+}
+
+func ExampleParseTree() {
+	n := parseTree(`{a: int, b?: 0..7|string}`)
+	n.Walk(func(c *parser.Node) bool {
+		fmt.Println(c.Kind, c.Start.Column, c.End.Column, c.Value)
+		return true
+	})
+	// Output:
+	// collection 1 26 {"a":int,"b"?:0..7|string}
+	// entry 2 8 "a":int
+	// literal 2 3 a
+	// identifier 5 8 int
+	// entry 10 25 "b":0..7|string
+	// literal 10 11 b
+	// union 14 25 0..7|string
+	// range 14 18 0..7
+	// identifier 19 25 string
+}
+
+func TestParseTree(t *testing.T) {
+	n := parseTree(treeSource)
+	require.Equal(t, parser.CollectionNode, n.Kind)
+	require.Equal(t, parser.Position{File: `tree.dgo`, Offset: 0, Line: 1, Column: 1}, n.Start)
+	require.Equal(t, parser.Position{File: `tree.dgo`, Offset: len(treeSource), Line: 11, Column: 2}, n.End)
+	require.Equal(t, 2, len(n.Children))
+
+	slug := n.Children[0]
+	require.Equal(t, parser.AliasNode, slug.Kind)
+	require.Equal(t, `the slug`, slug.Doc)
+	require.Equal(t, `tree.dgo:3:3`, slug.Start.String())
+	require.Equal(t, `tree.dgo:3:22`, slug.End.String())
+	require.Equal(t, vf.String(`treeSlug`), slug.Children[0].Value)
+	require.Equal(t, parser.LiteralNode, slug.Children[1].Kind)
+
+	entries := n.Children[1].Children[1].Children
+	require.Equal(t, 4, len(entries))
+	name := entries[0]
+	require.Equal(t, parser.EntryNode, name.Kind)
+	require.Equal(t, `the name`, name.Doc)
+	require.Equal(t, slug.Value, name.Value.(dgo.StructMapEntry).Value())
+	require.Equal(t, parser.IdentifierNode, name.Children[1].Kind)
+	require.Equal(t, slug.Value, name.Children[1].Value)
+
+	port := entries[1]
+	require.Equal(t, `annotations literal range literal`, kinds(port.Children))
+	require.Equal(t, vf.Map(`deprecated`, true), port.Children[0].Value)
+	require.Equal(t, 80, port.Value.(dgo.StructMapEntry).Default())
+
+	// the recursive reference is resolved
+	kids := entries[2].Children[1]
+	require.Equal(t, parser.TypeNode, kids.Kind)
+	require.Equal(t, n.Children[1].Value, kids.Children[0].Value)
+
+	kind := entries[3].Children[1]
+	require.Equal(t, parser.UnionNode, kind.Kind)
+	require.Equal(t, `literal literal allOf`, kinds(kind.Children))
+	require.Equal(t, `type type`, kinds(kind.Children[2].Children))
+}
+
+func kinds(ns []*parser.Node) string {
+	ks := make([]string, len(ns))
+	for i, n := range ns {
+		ks[i] = n.Kind.String()
+	}
+	return strings.Join(ks, ` `)
+}
+
+func TestParseTree_kinds(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind parser.NodeKind
+	}{
+		{`int`, parser.IdentifierNode},
+		{`3`, parser.LiteralNode},
+		{`3.0`, parser.LiteralNode},
+		{`"x"`, parser.LiteralNode},
+		{`/x/`, parser.LiteralNode},
+		{`3..`, parser.RangeNode},
+		{`..3.0`, parser.RangeNode},
+		{`1|2`, parser.UnionNode},
+		{`1^2`, parser.OneOfNode},
+		{`int&1`, parser.AllOfNode},
+		{`{int,string}`, parser.CollectionNode},
+		{`map[string]int`, parser.TypeNode},
+		{`[]int`, parser.TypeNode},
+		{`!int`, parser.TypeNode},
+		{`@description("x") treeKind=int`, parser.TypeNode},
+		{`(int)`, parser.IdentifierNode},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.kind, parseTree(tt.src).Kind)
+	}
+}
+
+func TestNode_At(t *testing.T) {
+	n := parseTree(treeSource)
+	f := n.At(9, 22)
+	require.Equal(t, parser.TypeNode, f.Kind)
+	require.Equal(t, tf.String(3), f.Value)
+	require.Equal(t, parser.CollectionNode, n.At(11, 1).Kind)
+	require.True(t, n.At(11, 2) == nil)
+	require.True(t, n.At(12, 1) == nil)
+	require.Equal(t, parser.CollectionNode, n.At(1, 1).Kind)
+}
+
+func TestNode_Find(t *testing.T) {
+	n := parseTree(treeSource)
+	f := n.Find(n.Children[1].Value.(dgo.StructMapType).Get(`port`).Value())
+	require.Equal(t, parser.RangeNode, f.Kind)
+	require.Equal(t, `tree.dgo:7:24`, f.Start.String())
+	require.True(t, n.Find(typ.Float) == nil)
+	require.True(t, n.Find(tf.Integer(1, 65535, true)) == nil)
+	require.True(t, n.Find(nil) == nil)
+	require.True(t, n.Children[0].Find(n.Value) == nil)
+}
+
+func TestNode_Find_equalTypes(t *testing.T) {
+	n := parseTree(`{a: 1..3, b: map[string]1..3, c: 1..3}`)
+	st := n.Value.(dgo.StructMapType)
+
+	// equal types are found at the position that produced them
+	require.Equal(t, `tree.dgo:1:5`, n.Find(st.Get(`a`).Value()).Start.String())
+	require.Equal(t, `tree.dgo:1:25`, n.Find(st.Get(`b`).Value().(dgo.MapType).ValueType()).Start.String())
+	require.Equal(t, `tree.dgo:1:34`, n.Find(st.Get(`c`).Value()).Start.String())
+
+	// a subtree only finds its own values
+	e := n.Entry(`b`)
+	require.Equal(t, `tree.dgo:1:25`, e.Find(st.Get(`b`).Value().(dgo.MapType).ValueType()).Start.String())
+	require.True(t, e.Find(st.Get(`c`).Value()) == nil)
+}
+
+func TestNode_Entry(t *testing.T) {
+	n := parseTree(`{a: string, "b": string, 3: string}`)
+	require.Equal(t, `tree.dgo:1:13`, n.Entry(`b`).Start.String())
+	require.Equal(t, `tree.dgo:1:18`, n.Entry(`b`).Children[1].Start.String())
+	require.Equal(t, `tree.dgo:1:2`, n.Entry(`a`).Start.String())
+	require.Equal(t, `tree.dgo:1:26`, n.Entry(3).Start.String())
+	require.True(t, n.Entry(`c`) == nil)
+	require.True(t, n.Entry(`a`).Entry(`a`) == nil)
+
+	// the built-in string type has no identity of its own so it is found at its first position
+	require.Equal(t, `tree.dgo:1:5`, n.Find(typ.String).Start.String())
+}
+
+func TestNodeKind_String(t *testing.T) {
+	require.Equal(t, `alias`, parser.AliasNode.String())
+	require.Equal(t, `NodeKind(42)`, parser.NodeKind(42).String())
+	require.Equal(t, `1:1`, parser.Position{Line: 1, Column: 1}.String())
+	require.Equal(t, `collection[tree.dgo:1:1-tree.dgo:1:6]`, parseTree(`{int}`).String())
+}
+
+func TestParseTree_error(t *testing.T) {
+	require.Panic(t, func() { parseTree(`{a: 1 2}`) }, `expected one of ',' or '\}', got 2: \(file: tree.dgo, line: 1, column: 7\)`)
+}
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+)
+
+// valueParser parses the canonical value literal syntax. Types, written as type[<type>], are parsed by the
+// type parser.
+type valueParser struct {
+	parser
+}
+
+// ParseValue parses the given content, written using the canonical value literal syntax, into a dgo.Value. The
+// syntax is the one produced by stringer.ValueString:
+//
+// nil, true, false, integers, floats, and double quoted strings are written the same way as in the type syntax.
+//
+// Arrays are written as {<value>,...} and maps as {<key>:<value>,...}. The empty array is {} and the empty map is {:}.
+//
+// Binaries are written as binary "<strict base64>", times as time["<RFC3339 with nanoseconds>"], regular
+// expressions as regexp["<expression>"], errors as error["<message>"], and non-finite floats as float["+Inf"],
+// float["-Inf"], or float["NaN"].
+//
+// Sensitive values are written as sensitive <value>, types as type[<type>], and instances of named types as
+// <type name> <initializer argument>.
+//
+// Aliases that are used in types are resolved using the default alias map. Aliases that are declared in types are
+// only known within the parsed content and are never added to the default alias map.
+func ParseValue(content string) (result dgo.Value) {
+	internal.DefaultAliases().Collect(func(a dgo.AliasAdder) {
+		p := &valueParser{parser{Base: NewParserBase(a, nextToken, content)}}
+		result = DoParse(p, ``)
+	})
+	return
+}
+
+func (p *valueParser) Parse(t *Token) {
+	p.value(t)
+	tk := p.NextToken()
+	if tk.Type != end {
+		panic(badSyntax(tk, exEnd))
+	}
+}
+
+func (p *valueParser) value(t *Token) {
+	var v dgo.Value
+	switch t.Type {
+	case '{':
+		v = p.collection()
+	case integer:
+		v = internal.Integer(tokenInt(t))
+	case float:
+		v = internal.Float(tokenFloat(t))
+	case stringLiteral:
+		v = internal.String(t.Value)
+	case identifier:
+		v = p.identifierValue(t)
+	default:
+		panic(badSyntax(t, exValue))
+	}
+	p.Append(v)
+}
+
+// collection parses an array or a map, starting after the initial '{'
+func (p *valueParser) collection() dgo.Value {
+	t := p.NextToken()
+	if t.Type == '}' {
+		return internal.WrapSlice([]dgo.Value{})
+	}
+	if t.Type == ':' {
+		p.expect('}', exListEnd)
+		return internal.MapWithCapacity(0)
+	}
+	var es []dgo.Value
+	var m dgo.Map
+	for {
+		e := p.element(t)
+		if p.PeekToken().Type == ':' {
+			if es != nil {
+				panic(errors.New(`mix of elements and map entries`))
+			}
+			p.NextToken()
+			if m == nil {
+				m = internal.MapWithCapacity(0)
+			}
+			m.Put(e, p.element(p.NextToken()))
+		} else {
+			if m != nil {
+				panic(errors.New(`mix of elements and map entries`))
+			}
+			es = append(es, e)
+		}
+		if !p.more() {
+			break
+		}
+		t = p.NextToken()
+	}
+	if m != nil {
+		return m
+	}
+	return internal.WrapSlice(es)
+}
+
+// element parses a value starting at the given token and returns it
+func (p *valueParser) element(t *Token) dgo.Value {
+	p.value(t)
+	return p.PopLast()
+}
+
+// more consumes the token that follows an element and returns true if it is a comma and false if it ends the
+// collection.
+func (p *valueParser) more() bool {
+	t := p.NextToken()
+	switch t.Type {
+	case ',':
+		return true
+	case '}':
+		return false
+	}
+	panic(badSyntax(t, exListComma))
+}
+
+func (p *valueParser) identifierValue(t *Token) dgo.Value {
+	switch t.Value {
+	case `nil`:
+		return internal.Nil
+	case `true`:
+		return internal.True
+	case `false`:
+		return internal.False
+	case `binary`:
+		return internal.BinaryFromEncoded(p.stringArg(false), `%B`)
+	case `time`:
+		return internal.TimeFromString(p.stringArg(true))
+	case `regexp`:
+		return internal.Regexp(regexp.MustCompile(p.stringArg(true)))
+	case `error`:
+		return internal.Value(errors.New(p.stringArg(true)))
+	case `float`:
+		f, err := strconv.ParseFloat(p.stringArg(true), 64)
+		if err != nil {
+			panic(err)
+		}
+		return internal.Float(f)
+	case `sensitive`:
+		return internal.Sensitive(p.element(p.NextToken()))
+	case `type`:
+		p.expect('[', exLeftBracket)
+		p.anyOf(p.NextToken())
+		tp := p.PopLastType()
+		p.expect(']', exRightBracket)
+		return tp
+	}
+	nt := internal.NamedType(t.Value)
+	if nt == nil {
+		panic(fmt.Errorf(`reference to unknown named type '%s'`, t.Value))
+	}
+	return nt.New(p.element(p.NextToken()))
+}
+
+// stringArg parses a string literal, optionally enclosed in brackets
+func (p *valueParser) stringArg(inBrackets bool) string {
+	if inBrackets {
+		p.expect('[', exLeftBracket)
+	}
+	t := p.NextToken()
+	if t.Type != stringLiteral {
+		panic(badSyntax(t, exStringLiteral))
+	}
+	if inBrackets {
+		p.expect(']', exRightBracket)
+	}
+	return t.Value
+}
+
+func (p *valueParser) expect(tt int, state int) {
+	if t := p.NextToken(); t.Type != tt {
+		panic(badSyntax(t, state))
+	}
+}
@@ -0,0 +1,52 @@
+package parser_test
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func TestParseValue(t *testing.T) {
+	require.Equal(t, vf.Values(1, -2, 3.5, `a`, nil, true, false), parser.ParseValue(`{1,-2,3.5,"a",nil,true,false}`))
+	require.Equal(t, vf.Values(), parser.ParseValue(`{}`))
+	require.Equal(t, vf.Map(), parser.ParseValue(`{:}`))
+	require.Equal(t, vf.Map(`a`, vf.Values(), 1, vf.Map()), parser.ParseValue(`{"a":{},1:{:}}`))
+	require.Equal(t, vf.Binary([]byte{1, 2, 3}, false), parser.ParseValue(`binary "AQID"`))
+	require.Equal(t, vf.Time(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), parser.ParseValue(`time["2020-01-02T03:04:05Z"]`))
+	require.Equal(t, vf.Value(regexp.MustCompile(`a\d`)), parser.ParseValue(`regexp["a\\d"]`))
+	require.Equal(t, vf.Value(errors.New(`boom`)), parser.ParseValue(`error["boom"]`))
+	require.Equal(t, vf.Float(math.Inf(-1)), parser.ParseValue(`float["-Inf"]`))
+	require.Equal(t, vf.Sensitive(vf.Values(1)), parser.ParseValue(`sensitive {1}`))
+	require.Equal(t, tf.Array(typ.String), parser.ParseValue(`type[[]string]`))
+	require.Equal(t, tf.AnyOf(typ.Integer, typ.String), parser.ParseValue(`type[int|string]`))
+}
+
+func TestParseValue_aliasesDontLeak(t *testing.T) {
+	require.Equal(t, vf.Values(tf.Integer(1, 3, true), tf.Array(tf.Integer(1, 3, true))),
+		parser.ParseValue(`{type[leaked=1..3],type[[]leaked]}`))
+	require.Nil(t, tf.DefaultAliases().GetType(vf.String(`leaked`)))
+	require.Panic(t, func() { parser.ParseValue(`type[leaked]`) }, `reference to unresolved type 'leaked'`)
+}
+
+func TestParseValue_fail(t *testing.T) {
+	require.Panic(t, func() { parser.ParseValue(`{1,"a":2}`) }, `mix of elements and map entries`)
+	require.Panic(t, func() { parser.ParseValue(`{"a":2,1}`) }, `mix of elements and map entries`)
+	require.Panic(t, func() { parser.ParseValue(`{1 2}`) }, `expected one of ',' or '}', got 2`)
+	require.Panic(t, func() { parser.ParseValue(`{:1}`) }, `expected '}', got 1`)
+	require.Panic(t, func() { parser.ParseValue(`1 2`) }, `expected end of expression, got 2`)
+	require.Panic(t, func() { parser.ParseValue(`[1]`) }, `expected a value literal, got '\['`)
+	require.Panic(t, func() { parser.ParseValue(`time "x"`) }, `expected '\[', got "x"`)
+	require.Panic(t, func() { parser.ParseValue(`time[1]`) }, `expected a literal string, got 1`)
+	require.Panic(t, func() { parser.ParseValue(`time["x"]`) }, `cannot parse "x"`)
+	require.Panic(t, func() { parser.ParseValue(`float["x"]`) }, `invalid syntax`)
+	require.Panic(t, func() { parser.ParseValue(`type[int}`) }, `expected '\]', got '}'`)
+	require.Panic(t, func() { parser.ParseValue(`unknownThing 3`) }, `unknown named type 'unknownThing'`)
+}
@@ -74,6 +74,9 @@ func (d *dataDecoder) decode(ts dgo.String, m dgo.Map) dgo.Value {
 		v = dl.ParseType(nil, ad.Get(1).(dgo.String))
 		if d.aliasMap != nil {
 			d.aliasMap.Add(v.(dgo.Type), ad.Get(0).(dgo.String))
+			if ad.Len() > 2 {
+				d.aliasMap.Annotate(ad.Get(0).(dgo.String), ad.Get(2).(dgo.Map))
+			}
 		}
 	default:
 		tp := tf.Named(ts.GoString())
@@ -84,3 +84,23 @@ func TestDataDecoder_alias(t *testing.T) {
 	})
 	require.Equal(t, `ne`, aliasMap.GetName(tf.String(1)))
 }
+
+func TestDataDecoder_aliasAnnotations(t *testing.T) {
+	aliasMap := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `@description("not empty") ane=string[1]`)
+	})
+	a := vf.Values(tf.String(1))
+
+	c := streamer.DataCollector()
+	streamer.New(aliasMap, nil).Stream(a, c)
+	require.Equal(t,
+		vf.Values(vf.Map(`__type`, `alias`, `__value`, vf.Values(`ane`, `string[1]`, vf.Map(`description`, `not empty`)))),
+		c.Value())
+
+	aliasMap = tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		d := streamer.DataDecoder(aa, nil)
+		streamer.New(nil, nil).Stream(c.Value(), d)
+	})
+	require.Equal(t, `ane`, aliasMap.GetName(tf.String(1)))
+	require.Equal(t, vf.Map(`description`, `not empty`), aliasMap.Annotations(vf.String(`ane`)))
+}
@@ -2,12 +2,17 @@ package streamer
 
 import (
 	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/stringer"
 	"github.com/lyraproj/dgo/tf"
 	"github.com/lyraproj/dgo/typ"
 	"github.com/lyraproj/dgo/vf"
 )
 
-// A Dialect determines how dgo values are serialized
+// A Dialect determines how dgo values are serialized.
+//
+// A Dialect that uses its own syntax for types can also implement the method TypeFormatter() dgo.TypeFormatter. It
+// returns the formatter that produces the string form of types, which must be readable by the ParseType method. Types
+// are written using dgo syntax when a Dialect has no such method.
 type Dialect interface {
 	// TypeKey returns the string that is used as a special hash key to denote a type. The default string is "__type"
 	TypeKey() dgo.String
@@ -38,6 +43,11 @@ type Dialect interface {
 	ParseType(aliasMap dgo.AliasAdder, typeString dgo.String) dgo.Type
 }
 
+// typeFormatting is implemented by dialects that provide their own TypeFormatter
+type typeFormatting interface {
+	TypeFormatter() dgo.TypeFormatter
+}
+
 // DgoDialect returns the default dialect which is dgo
 func DgoDialect() Dialect {
 	return dgoDialectSingleton
@@ -91,3 +101,17 @@ func (d dgoDialect) TimeTypeName() dgo.String {
 func (d dgoDialect) ParseType(aliasMap dgo.AliasAdder, typeString dgo.String) dgo.Type {
 	return typ.AsType(tf.ParseFile(aliasMap, ``, typeString.GoString()))
 }
+
+// TypeFormatter returns the formatter that produces the string form of types using dgo syntax
+func (d dgoDialect) TypeFormatter() dgo.TypeFormatter {
+	return stringer.TypeFormatter()
+}
+
+// typeFormatter returns the TypeFormatter of the given dialect, or the dgo TypeFormatter when the dialect doesn't
+// provide one
+func typeFormatter(d Dialect) dgo.TypeFormatter {
+	if f, ok := d.(typeFormatting); ok {
+		return f.TypeFormatter()
+	}
+	return stringer.TypeFormatter()
+}
@@ -1,6 +1,8 @@
 package streamer_test
 
 import (
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/lyraproj/dgo/vf"
@@ -9,6 +11,7 @@ import (
 	"github.com/lyraproj/dgo/typ"
 
 	"github.com/lyraproj/dgo/streamer"
+	"github.com/lyraproj/dgo/stringer"
 
 	require "github.com/lyraproj/dgo/dgo_test"
 
@@ -33,3 +36,71 @@ func TestDgoDialect_names(t *testing.T) {
 func TestDgoDialect_ParseType(t *testing.T) {
 	require.Equal(t, tf.Array(typ.String, 3, 8), streamer.DgoDialect().ParseType(nil, vf.String(`[3,8]string`)))
 }
+
+func TestDgoDialect_TypeFormatter(t *testing.T) {
+	s := strings.Builder{}
+	streamer.DgoDialect().(interface{ TypeFormatter() dgo.TypeFormatter }).TypeFormatter().FormatType(&s, tf.Array(typ.String, 3, 8), nil, 0)
+	require.Equal(t, `[3,8]string`, s.String())
+}
+
+// prefixDialect is a dialect that uses its own syntax for types. The syntax is dgo syntax prefixed with "t:"
+type prefixDialect struct {
+	streamer.Dialect
+}
+
+type prefixFormatter struct {
+	dgo.TypeFormatter
+}
+
+func (d prefixDialect) ParseType(aliasMap dgo.AliasAdder, typeString dgo.String) dgo.Type {
+	return d.Dialect.ParseType(aliasMap, vf.String(strings.TrimPrefix(typeString.GoString(), `t:`)))
+}
+
+func (d prefixDialect) TypeFormatter() dgo.TypeFormatter {
+	return prefixFormatter{stringer.TypeFormatter()}
+}
+
+func (f prefixFormatter) FormatType(w io.Writer, t dgo.Type, aliasMap dgo.AliasMap, width int) {
+	_, _ = io.WriteString(w, `t:`)
+	f.TypeFormatter.FormatType(w, t, aliasMap, width)
+}
+
+func TestDialect_custom(t *testing.T) {
+	d := prefixDialect{streamer.DgoDialect()}
+	o := streamer.DefaultOptions()
+	o.Dialect = d
+	tp := tf.Map(typ.String, tf.Integer(1, 10, true))
+	c := streamer.DataCollector()
+	streamer.New(nil, o).Stream(tp, c)
+	require.Equal(t, vf.Map(`__type`, `t:map[string]1..10`), c.Value())
+
+	dc := streamer.DataDecoder(nil, d)
+	streamer.New(nil, o).Stream(c.Value(), dc)
+	require.Equal(t, tp, dc.Value())
+}
+
+// plainDialect is a dialect that has no TypeFormatter method
+type plainDialect struct {
+	streamer.Dialect
+}
+
+func TestDialect_withoutTypeFormatter(t *testing.T) {
+	o := streamer.DefaultOptions()
+	o.Dialect = plainDialect{streamer.DgoDialect()}
+	c := streamer.DataCollector()
+	streamer.New(nil, o).Stream(tf.Map(typ.String, tf.Integer(1, 10, true)), c)
+	require.Equal(t, vf.Map(`__type`, `map[string]1..10`), c.Value())
+}
+
+func TestDialect_aliasMap(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, ``, `{dlPort=1..65535, dlHost={name:string,port:dlPort}}`)
+	})
+	c := streamer.DataCollector()
+	streamer.New(am, nil).Stream(tf.Array(am.GetType(vf.String(`dlPort`))), c)
+	require.Equal(t, vf.Map(`__type`, `[]dlPort`), c.Value())
+
+	c = streamer.DataCollector()
+	streamer.New(am, nil).Stream(am.GetType(vf.String(`dlHost`)), c)
+	require.Equal(t, vf.Map(`__type`, `alias`, `__value`, vf.Values(`dlHost`, `{"name":string,"port":dlPort}`)), c.Value())
+}
@@ -2,6 +2,7 @@ package pcore
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 
@@ -68,6 +69,10 @@ func nextToken(sr *util.StringReader) (t *parser.Token) {
 		case '\'', '"':
 			t = &parser.Token{Value: parser.ConsumeString(sr, r), Type: stringLiteral}
 		case '/':
+			if sr.Peek() == '*' {
+				consumeBlockComment(sr)
+				continue
+			}
 			t = &parser.Token{Value: parser.ConsumeRegexp(sr), Type: regexpLiteral}
 		case '=':
 			if sr.Peek() == '>' {
@@ -120,6 +125,22 @@ func consumeLineComment(sr *util.StringReader) {
 	}
 }
 
+// consumeBlockComment consumes a comment that starts with /* and ends with */, starting after the initial '/'
+func consumeBlockComment(sr *util.StringReader) {
+	sr.Next()
+	for {
+		switch sr.Next() {
+		case 0:
+			panic(errors.New("unterminated comment"))
+		case '*':
+			if sr.Peek() == '/' {
+				sr.Next()
+				return
+			}
+		}
+	}
+}
+
 func consumeIdentifier(sr *util.StringReader, start rune, buf io.Writer) {
 	util.WriteRune(buf, start)
 	for {
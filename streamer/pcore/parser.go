@@ -578,6 +578,9 @@ func stringType(p *pcoreParser) dgo.Value {
 		p.array()
 		args := vf.ArgumentsFromArray(p.PopLast().(dgo.Array))
 		args.AssertSize(`String`, 1, 2)
+		if args.Len() == 2 {
+			return tf.String(getInt(`String`, args, 0, 0), getInt(`String`, args, 1, math.MaxInt64))
+		}
 		return tf.String(args.InterfaceSlice()...)
 	}
 	return typ.String
@@ -434,3 +434,15 @@ func TestParseFile_errors(t *testing.T) {
 		func() { pcore.ParseFile(nil, `foo.dgo`, `[1 2]`) },
 		`expected one of ',' or '\]', got 2: \(file: foo\.dgo, line: 1, column: 4\)`)
 }
+
+func TestParse_comments(t *testing.T) {
+	tp := pcore.Parse(`# leading comment
+Struct[{
+  /* block
+     comment */
+  a => Integer, # trailing comment
+  b => Pattern[/#/]
+}] # last`)
+	require.Equal(t, tf.ParseType(`{a:int,b:/#/}`), tp)
+	require.Panic(t, func() { pcore.Parse(`Integer /* x`) }, `unterminated comment`)
+}
@@ -60,3 +60,7 @@ func (d pcoreDialect) TimeTypeName() dgo.String {
 func (d pcoreDialect) ParseType(aliasMap dgo.AliasAdder, typeString dgo.String) (dt dgo.Type) {
 	return typ.AsType(Parse(typeString.GoString()))
 }
+
+func (d pcoreDialect) TypeFormatter() dgo.TypeFormatter {
+	return TypeFormatter()
+}
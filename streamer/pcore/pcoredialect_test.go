@@ -1,6 +1,7 @@
 package pcore_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/lyraproj/dgo/dgo"
@@ -30,3 +31,24 @@ func TestPcoreDialect_names(t *testing.T) {
 func TestPcoreDialect_ParseType(t *testing.T) {
 	require.Equal(t, tf.Array(typ.String, 3, 8), pcore.Dialect().ParseType(nil, vf.String(`Array[String,3,8]`)))
 }
+
+func TestPcoreDialect_TypeFormatter(t *testing.T) {
+	s := strings.Builder{}
+	pcore.Dialect().(interface{ TypeFormatter() dgo.TypeFormatter }).TypeFormatter().FormatType(&s, tf.Array(typ.String, 3, 8), nil, 0)
+	require.Equal(t, `Array[String,3,8]`, s.String())
+}
+
+func TestPcoreDialect_streamTypes(t *testing.T) {
+	tp := tf.ParseType(`{name:string[1],port?:1..65535,mode:"r"|"rw"}`)
+	o := streamer.DefaultOptions()
+	o.Dialect = pcore.Dialect()
+	c := streamer.DataCollector()
+	streamer.New(nil, o).Stream(vf.Values(tp, typ.Integer), c)
+	require.Equal(t, vf.Values(
+		vf.Map(`__ptype`, `Struct[{"name"=>String[1,default],Optional["port"]=>Integer[1,65535],"mode"=>Enum["r","rw"]}]`),
+		vf.Map(`__ptype`, `Integer`)), c.Value())
+
+	d := streamer.DataDecoder(nil, pcore.Dialect())
+	streamer.New(nil, o).Stream(c.Value(), d)
+	require.Equal(t, vf.Values(tp, typ.Integer), d.Value())
+}
@@ -0,0 +1,499 @@
+package pcore
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/util"
+	"github.com/lyraproj/dgo/vf"
+)
+
+type typeBuilder struct {
+	io.Writer
+	aliasMap dgo.AliasMap
+	seen     []dgo.Value
+}
+
+// TypeString produces a string with the pcore syntax for the given type. Aliases in the default alias map are
+// written using their pcore names.
+//
+// The function panics when the type cannot be expressed using pcore syntax.
+func TypeString(t dgo.Type) string {
+	return TypeStringWithAliasMap(t, tf.DefaultAliases())
+}
+
+// TypeStringWithAliasMap produces a string with the pcore syntax for the given type. Aliases in the given alias map
+// are written using their pcore names.
+//
+// The function panics when the type cannot be expressed using pcore syntax.
+func TypeStringWithAliasMap(t dgo.Type, am dgo.AliasMap) string {
+	s := strings.Builder{}
+	sb := &typeBuilder{Writer: &s, aliasMap: am}
+	sb.buildTypeString(t)
+	return s.String()
+}
+
+type formatter int
+
+// TypeFormatter returns the dgo.TypeFormatter that writes types using pcore syntax. The width is currently ignored.
+func TypeFormatter() dgo.TypeFormatter {
+	return formatter(0)
+}
+
+func (formatter) FormatType(w io.Writer, t dgo.Type, am dgo.AliasMap, _ int) {
+	(&typeBuilder{Writer: w, aliasMap: am}).buildTypeString(t)
+}
+
+var simpleTypeNames = map[dgo.TypeIdentifier]string{
+	dgo.TiAny:     `Any`,
+	dgo.TiNil:     `Undef`,
+	dgo.TiBoolean: `Boolean`,
+	dgo.TiInteger: `Integer`,
+	dgo.TiFloat:   `Float`,
+	dgo.TiString:  `String`,
+	dgo.TiBinary:  `Binary`,
+	dgo.TiRegexp:  `Regexp`,
+	dgo.TiTime:    `Timestamp`,
+}
+
+var typeWriters map[dgo.TypeIdentifier]func(sb *typeBuilder, t dgo.Type)
+
+func init() {
+	typeWriters = map[dgo.TypeIdentifier]func(sb *typeBuilder, t dgo.Type){
+		dgo.TiAllOf:         (*typeBuilder).allOf,
+		dgo.TiAnyOf:         (*typeBuilder).anyOf,
+		dgo.TiArray:         (*typeBuilder).array,
+		dgo.TiArrayExact:    (*typeBuilder).arrayExact,
+		dgo.TiBinary:        (*typeBuilder).binary,
+		dgo.TiBooleanExact:  (*typeBuilder).booleanExact,
+		dgo.TiCiString:      (*typeBuilder).ciString,
+		dgo.TiFloatExact:    (*typeBuilder).floatExact,
+		dgo.TiFloatRange:    (*typeBuilder).floatRange,
+		dgo.TiFunction:      (*typeBuilder).function,
+		dgo.TiIntegerExact:  (*typeBuilder).integerExact,
+		dgo.TiIntegerRange:  (*typeBuilder).integerRange,
+		dgo.TiMap:           (*typeBuilder).hash,
+		dgo.TiMapExact:      (*typeBuilder).structMap,
+		dgo.TiMeta:          (*typeBuilder).meta,
+		dgo.TiNamed:         (*typeBuilder).named,
+		dgo.TiNot:           (*typeBuilder).not,
+		dgo.TiRegexpExact:   (*typeBuilder).regexpExact,
+		dgo.TiSensitive:     (*typeBuilder).sensitive,
+		dgo.TiStringExact:   (*typeBuilder).stringExact,
+		dgo.TiStringPattern: (*typeBuilder).stringPattern,
+		dgo.TiStringSized:   (*typeBuilder).stringSized,
+		dgo.TiStruct:        (*typeBuilder).structMap,
+		dgo.TiTuple:         (*typeBuilder).tuple,
+	}
+}
+
+func (sb *typeBuilder) buildTypeString(t dgo.Type) {
+	if sb.aliasMap != nil {
+		if tn := sb.aliasMap.GetName(t); tn != nil {
+			util.WriteString(sb, toPcoreName(tn.GoString()))
+			return
+		}
+		if sb.aliasMap.TypeAnnotations(t) != nil {
+			panic(fmt.Errorf(`the annotations of the type %s cannot be expressed in pcore syntax`, t))
+		}
+	}
+	ti := t.TypeIdentifier()
+	if f, ok := typeWriters[ti]; ok {
+		if util.RecursionHit(sb.seen, t) {
+			panic(fmt.Errorf(`the recursive type %s must be named in the alias map to be expressed in pcore syntax`, t))
+		}
+		os := sb.seen
+		sb.seen = append(sb.seen, t)
+		f(sb, t)
+		sb.seen = os
+		return
+	}
+	if n, ok := simpleTypeNames[ti]; ok {
+		util.WriteString(sb, n)
+		return
+	}
+	panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+}
+
+func (sb *typeBuilder) allOf(t dgo.Type) {
+	// NotUndef[T] is parsed into the conjunction of !nil and T
+	ops := t.(dgo.TernaryType).Operands()
+	if ops.Len() == 2 && tf.Not(typ.Nil).Equals(ops.Get(0)) {
+		util.WriteString(sb, `NotUndef[`)
+		sb.buildTypeString(ops.Get(1).(dgo.Type))
+		util.WriteByte(sb, ']')
+		return
+	}
+	panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+}
+
+func (sb *typeBuilder) anyOf(t dgo.Type) {
+	ops := t.(dgo.TernaryType).Operands()
+	switch {
+	case ops.Len() == 2 && ops.Get(0).(dgo.Type).TypeIdentifier() == dgo.TiNil:
+		util.WriteString(sb, `Optional[`)
+		sb.buildTypeString(ops.Get(1).(dgo.Type))
+		util.WriteByte(sb, ']')
+	case ops.Len() > 1 && ops.All(func(v dgo.Value) bool { return v.(dgo.Type).TypeIdentifier() == dgo.TiStringExact }):
+		sb.enum(ops, false)
+	case ops.Len() > 1 && ops.All(func(v dgo.Value) bool { return v.(dgo.Type).TypeIdentifier() == dgo.TiCiString }):
+		sb.enum(ops, true)
+	default:
+		util.WriteString(sb, `Variant`)
+		sb.writeTypes(ops)
+	}
+}
+
+func (sb *typeBuilder) enum(ops dgo.Array, ci bool) {
+	util.WriteString(sb, `Enum[`)
+	ops.EachWithIndex(func(v dgo.Value, i int) {
+		if i > 0 {
+			util.WriteByte(sb, ',')
+		}
+		writeQuoted(sb, v.(dgo.ExactType).ExactValue().String())
+	})
+	if ci {
+		util.WriteString(sb, `,true`)
+	}
+	util.WriteByte(sb, ']')
+}
+
+func (sb *typeBuilder) array(t dgo.Type) {
+	at := t.(dgo.ArrayType)
+	util.WriteString(sb, `Array`)
+	et := at.ElementType()
+	if !at.Unbounded() {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(et)
+		util.WriteByte(sb, ',')
+		writeSize(sb, int64(at.Min()), int64(at.Max()))
+		util.WriteByte(sb, ']')
+	} else if et.TypeIdentifier() != dgo.TiAny {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(et)
+		util.WriteByte(sb, ']')
+	}
+}
+
+func (sb *typeBuilder) arrayExact(t dgo.Type) {
+	util.WriteString(sb, `Tuple`)
+	a := t.(dgo.ExactType).ExactValue().(dgo.Array)
+	sb.writeTypes(a.Map(func(v dgo.Value) interface{} { return v.Type() }))
+}
+
+func (sb *typeBuilder) binary(t dgo.Type) {
+	bt := t.(dgo.BinaryType)
+	util.WriteString(sb, `Binary`)
+	if !bt.Unbounded() {
+		util.WriteByte(sb, '[')
+		writeSize(sb, int64(bt.Min()), int64(bt.Max()))
+		util.WriteByte(sb, ']')
+	}
+}
+
+func (sb *typeBuilder) booleanExact(t dgo.Type) {
+	if t.(dgo.ExactType).ExactValue().(dgo.Boolean).GoBool() {
+		util.WriteString(sb, `True`)
+	} else {
+		util.WriteString(sb, `False`)
+	}
+}
+
+func (sb *typeBuilder) ciString(t dgo.Type) {
+	sb.enum(vf.Values(t), true)
+}
+
+func (sb *typeBuilder) floatExact(t dgo.Type) {
+	f := t.(dgo.ExactType).ExactValue().(dgo.Float).GoFloat()
+	writeFloatRange(sb, f, f)
+}
+
+func (sb *typeBuilder) floatRange(t dgo.Type) {
+	ft := t.(dgo.FloatType)
+	if !ft.Inclusive() {
+		panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+	}
+	writeFloatRange(sb, ft.Min(), ft.Max())
+}
+
+func (sb *typeBuilder) function(t dgo.Type) {
+	ft := t.(dgo.FunctionType)
+	out := ft.Out()
+	util.WriteString(sb, `Callable[`)
+	switch {
+	case out.Len() == 0 && !out.Variadic():
+		sb.writeTupleArgs(ft.In())
+	case out.Len() == 1 && !out.Variadic():
+		util.WriteByte(sb, '[')
+		sb.writeTupleArgs(ft.In())
+		util.WriteString(sb, `],`)
+		sb.buildTypeString(out.Element(0))
+	default:
+		panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+	}
+	util.WriteByte(sb, ']')
+}
+
+func (sb *typeBuilder) integerExact(t dgo.Type) {
+	i := t.(dgo.ExactType).ExactValue().(dgo.Integer).GoInt()
+	writeIntRange(sb, i, i)
+}
+
+func (sb *typeBuilder) integerRange(t dgo.Type) {
+	it := t.(dgo.IntegerType)
+	if !it.Inclusive() && it.Max() != math.MaxInt64 {
+		panic(fmt.Errorf(`the exclusive range %s cannot be expressed in pcore syntax`, t))
+	}
+	writeIntRange(sb, it.Min(), it.Max())
+}
+
+func (sb *typeBuilder) hash(t dgo.Type) {
+	mt := t.(dgo.MapType)
+	util.WriteString(sb, `Hash`)
+	kt := mt.KeyType()
+	vt := mt.ValueType()
+	if !mt.Unbounded() {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(kt)
+		util.WriteByte(sb, ',')
+		sb.buildTypeString(vt)
+		util.WriteByte(sb, ',')
+		writeSize(sb, int64(mt.Min()), int64(mt.Max()))
+		util.WriteByte(sb, ']')
+	} else if kt.TypeIdentifier() != dgo.TiAny || vt.TypeIdentifier() != dgo.TiAny {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(kt)
+		util.WriteByte(sb, ',')
+		sb.buildTypeString(vt)
+		util.WriteByte(sb, ']')
+	}
+}
+
+func (sb *typeBuilder) meta(t dgo.Type) {
+	util.WriteString(sb, `Type`)
+	if op := t.(dgo.UnaryType).Operand(); op != nil && op.TypeIdentifier() != dgo.TiAny {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(op)
+		util.WriteByte(sb, ']')
+	}
+}
+
+func (sb *typeBuilder) named(t dgo.Type) {
+	nt := t.(dgo.NamedType)
+	util.WriteString(sb, toPcoreName(nt.Name()))
+	if params := nt.Parameters(); params != nil {
+		sb.writeTypes(params.Map(func(v dgo.Value) interface{} { return v.Type() }))
+	}
+}
+
+func (sb *typeBuilder) not(t dgo.Type) {
+	if t.(dgo.UnaryType).Operand().TypeIdentifier() == dgo.TiNil {
+		util.WriteString(sb, `NotUndef`)
+		return
+	}
+	panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+}
+
+func (sb *typeBuilder) regexpExact(t dgo.Type) {
+	util.WriteString(sb, `Regexp[`)
+	writeRegexp(sb, t.(dgo.ExactType).ExactValue().String())
+	util.WriteByte(sb, ']')
+}
+
+func (sb *typeBuilder) sensitive(t dgo.Type) {
+	util.WriteString(sb, `Sensitive`)
+	if op := t.(dgo.UnaryType).Operand(); op.TypeIdentifier() != dgo.TiAny {
+		util.WriteByte(sb, '[')
+		sb.buildTypeString(op)
+		util.WriteByte(sb, ']')
+	}
+}
+
+func (sb *typeBuilder) stringExact(t dgo.Type) {
+	sb.enum(vf.Values(t), false)
+}
+
+func (sb *typeBuilder) stringPattern(t dgo.Type) {
+	util.WriteString(sb, `Pattern[`)
+	writeRegexp(sb, t.(dgo.ExactType).ExactValue().String())
+	util.WriteByte(sb, ']')
+}
+
+func (sb *typeBuilder) stringSized(t dgo.Type) {
+	st := t.(dgo.StringType)
+	util.WriteString(sb, `String[`)
+	writeSize(sb, int64(st.Min()), int64(st.Max()))
+	util.WriteByte(sb, ']')
+}
+
+func (sb *typeBuilder) structMap(t dgo.Type) {
+	st := t.(dgo.StructMapType)
+	if st.Additional() && st.Len() > 0 {
+		panic(fmt.Errorf(`the type %s cannot be expressed in pcore syntax`, t))
+	}
+	util.WriteString(sb, `Struct`)
+	if st.Additional() {
+		return
+	}
+	util.WriteString(sb, `[{`)
+	first := true
+	st.Each(func(e dgo.StructMapEntry) {
+		if first {
+			first = false
+		} else {
+			util.WriteByte(sb, ',')
+		}
+		k := e.Key().(dgo.ExactType).ExactValue()
+		ks, ok := k.(dgo.String)
+		if !ok {
+			panic(fmt.Errorf(`the struct map key %s cannot be expressed in pcore syntax`, k))
+		}
+		if e.Default() != nil {
+			panic(fmt.Errorf(`the default value of the struct map entry %s cannot be expressed in pcore syntax`, k))
+		}
+		if e.Annotations() != nil {
+			panic(fmt.Errorf(`the annotations of the struct map entry %s cannot be expressed in pcore syntax`, k))
+		}
+		if e.Required() {
+			writeQuoted(sb, ks.GoString())
+		} else {
+			util.WriteString(sb, `Optional[`)
+			writeQuoted(sb, ks.GoString())
+			util.WriteByte(sb, ']')
+		}
+		util.WriteString(sb, `=>`)
+		sb.buildTypeString(e.Value().(dgo.Type))
+	})
+	util.WriteString(sb, `}]`)
+}
+
+func (sb *typeBuilder) tuple(t dgo.Type) {
+	util.WriteString(sb, `Tuple[`)
+	sb.writeTupleArgs(t.(dgo.TupleType))
+	util.WriteByte(sb, ']')
+}
+
+// writeTupleArgs writes the element types of the given tuple followed by the size constraint that makes the last
+// element type repeat when the tuple is variadic.
+func (sb *typeBuilder) writeTupleArgs(tt dgo.TupleType) {
+	es := tt.ElementTypes()
+	n := es.Len()
+	es.EachWithIndex(func(v dgo.Value, i int) {
+		if i > 0 {
+			util.WriteByte(sb, ',')
+		}
+		sb.buildTypeString(v.(dgo.Type))
+	})
+	switch {
+	case tt.Variadic():
+		if n > 0 {
+			util.WriteByte(sb, ',')
+		}
+		util.WriteString(sb, strconv.Itoa(n-1))
+		util.WriteString(sb, `,default`)
+	case n == 0:
+		util.WriteString(sb, `0,0`)
+	}
+}
+
+func (sb *typeBuilder) writeTypes(ts dgo.Array) {
+	util.WriteByte(sb, '[')
+	ts.EachWithIndex(func(v dgo.Value, i int) {
+		if i > 0 {
+			util.WriteByte(sb, ',')
+		}
+		sb.buildTypeString(v.(dgo.Type))
+	})
+	util.WriteByte(sb, ']')
+}
+
+func writeIntRange(w io.Writer, min, max int64) {
+	util.WriteString(w, `Integer[`)
+	writeInt(w, min, math.MinInt64)
+	util.WriteByte(w, ',')
+	writeInt(w, max, math.MaxInt64)
+	util.WriteByte(w, ']')
+}
+
+func writeFloatRange(w io.Writer, min, max float64) {
+	util.WriteString(w, `Float[`)
+	if min == -math.MaxFloat64 {
+		util.WriteString(w, defaultLiteral)
+	} else {
+		util.WriteString(w, util.Ftoa(min))
+	}
+	util.WriteByte(w, ',')
+	if max == math.MaxFloat64 {
+		util.WriteString(w, defaultLiteral)
+	} else {
+		util.WriteString(w, util.Ftoa(max))
+	}
+	util.WriteByte(w, ']')
+}
+
+func writeSize(w io.Writer, min, max int64) {
+	writeInt(w, min, math.MinInt64)
+	util.WriteByte(w, ',')
+	writeInt(w, max, math.MaxInt64)
+}
+
+func writeInt(w io.Writer, i, dflt int64) {
+	if i == dflt {
+		util.WriteString(w, defaultLiteral)
+	} else {
+		util.WriteString(w, strconv.FormatInt(i, 10))
+	}
+}
+
+// writeQuoted writes the given string in double quotes using the escapes that the pcore lexer understands
+func writeQuoted(w io.Writer, s string) {
+	util.WriteByte(w, '"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			util.WriteByte(w, '\\')
+			util.WriteRune(w, r)
+		case '\n':
+			util.WriteString(w, `\n`)
+		case '\r':
+			util.WriteString(w, `\r`)
+		case '\t':
+			util.WriteString(w, `\t`)
+		default:
+			util.WriteRune(w, r)
+		}
+	}
+	util.WriteByte(w, '"')
+}
+
+// writeRegexp writes the given regular expression between slashes, escaping all slashes that it contains that
+// aren't already escaped
+func writeRegexp(w io.Writer, rx string) {
+	util.WriteByte(w, '/')
+	escaped := false
+	for _, r := range rx {
+		if r == '/' && !escaped {
+			util.WriteByte(w, '\\')
+		}
+		escaped = r == '\\' && !escaped
+		util.WriteRune(w, r)
+	}
+	util.WriteByte(w, '/')
+}
+
+// toPcoreName converts a dgo name such as "my.type" into a pcore name such as "My::Type"
+func toPcoreName(dgoName string) string {
+	ns := strings.Split(dgoName, `.`)
+	for i := range ns {
+		if n := ns[i]; n != `` {
+			ns[i] = strings.ToUpper(n[:1]) + n[1:]
+		}
+	}
+	return strings.Join(ns, `::`)
+}
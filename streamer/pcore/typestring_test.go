@@ -0,0 +1,170 @@
+package pcore_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/streamer/pcore"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleTypeString() {
+	fmt.Println(pcore.TypeString(tf.ParseType(`{name:string[1],port?:1..65535,mode:"r"|"rw"}`)))
+	// Output: Struct[{"name"=>String[1,default],Optional["port"]=>Integer[1,65535],"mode"=>Enum["r","rw"]}]
+}
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		t dgo.Type
+		s string
+	}{
+		{typ.Any, `Any`},
+		{typ.Nil, `Undef`},
+		{typ.Boolean, `Boolean`},
+		{typ.True, `True`},
+		{typ.False, `False`},
+		{typ.Integer, `Integer`},
+		{tf.Integer(1, 10, true), `Integer[1,10]`},
+		{tf.ParseType(`3..`), `Integer[3,default]`},
+		{tf.ParseType(`3`), `Integer[3,3]`},
+		{typ.Float, `Float`},
+		{tf.Float(0.5, 1.5, true), `Float[0.5,1.5]`},
+		{tf.ParseType(`..1.5`), `Float[default,1.5]`},
+		{tf.ParseType(`1.5`), `Float[1.5,1.5]`},
+		{typ.String, `String`},
+		{tf.String(1, 8), `String[1,8]`},
+		{tf.ParseType(`"a\"b"`), `Enum["a\"b"]`},
+		{tf.ParseType(`"a"|"b"`), `Enum["a","b"]`},
+		{tf.ParseType(`~"a"`), `Enum["a",true]`},
+		{tf.ParseType(`~"a"|~"b"`), `Enum["a","b",true]`},
+		{tf.ParseType(`/a\/b/`), `Pattern[/a\/b/]`},
+		{typ.Regexp, `Regexp`},
+		{vf.Value(`/x/`).Type(), `Enum["/x/"]`},
+		{typ.Time, `Timestamp`},
+		{typ.Binary, `Binary`},
+		{tf.Binary(1, 5), `Binary[1,5]`},
+		{typ.Array, `Array`},
+		{tf.Array(typ.String), `Array[String]`},
+		{tf.Array(typ.String, 1, 3), `Array[String,1,3]`},
+		{tf.Tuple(typ.String, typ.Integer), `Tuple[String,Integer]`},
+		{tf.VariadicTuple(typ.String, typ.Integer), `Tuple[String,Integer,1,default]`},
+		{vf.Values(1, `a`).Type(), `Tuple[Integer[1,1],Enum["a"]]`},
+		{typ.Map, `Hash`},
+		{tf.Map(typ.String, typ.Integer), `Hash[String,Integer]`},
+		{tf.Map(typ.String, typ.Integer, 1, 3), `Hash[String,Integer,1,3]`},
+		{tf.ParseType(`{a:int,b?:string}`), `Struct[{"a"=>Integer,Optional["b"]=>String}]`},
+		{tf.StructMap(true), `Struct`},
+		{tf.ParseType(`int|string`), `Variant[Integer,String]`},
+		{tf.AnyOf(typ.Nil, typ.String), `Optional[String]`},
+		{tf.Not(typ.Nil), `NotUndef`},
+		{tf.AllOf(tf.Not(typ.Nil), typ.Any), `NotUndef[Any]`},
+		{tf.Meta(typ.String), `Type[String]`},
+		{typ.Type, `Type`},
+		{tf.Sensitive(typ.String), `Sensitive[String]`},
+		{typ.Sensitive, `Sensitive`},
+		{tf.ParseType(`func(string,int) bool`), `Callable[[String,Integer],Boolean]`},
+		{tf.ParseType(`func(string,...int)`), `Callable[String,Integer,1,default]`},
+		{tf.ParseType(`func()`), `Callable[0,0]`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.s, pcore.TypeString(tt.t))
+	}
+}
+
+func TestTypeString_roundTrip(t *testing.T) {
+	for _, tp := range []dgo.Type{
+		typ.Any,
+		typ.Boolean,
+		typ.True,
+		tf.Integer(1, 10, true),
+		tf.ParseType(`3..`),
+		tf.ParseType(`3`),
+		tf.Float(0.5, 1.5, true),
+		tf.String(1, 8),
+		tf.String(1),
+		tf.ParseType(`"a\"b\n"`),
+		tf.ParseType(`"a"|"b"`),
+		tf.ParseType(`~"a"|~"b"`),
+		tf.ParseType(`/a\/b/`),
+		typ.Time,
+		tf.Binary(1, 5),
+		tf.Array(typ.String, 1, 3),
+		tf.Tuple(typ.String, typ.Integer),
+		tf.VariadicTuple(typ.String, typ.Integer),
+		tf.Map(typ.String, typ.Integer, 1, 3),
+		tf.ParseType(`{a:int,b?:string}`),
+		tf.ParseType(`int|string`),
+		tf.AnyOf(typ.Nil, typ.String),
+		tf.Not(typ.Nil),
+		tf.Meta(typ.String),
+		tf.Sensitive(typ.String),
+		tf.ParseType(`func(string,int) bool`),
+		tf.ParseType(`func(string,...int)`),
+	} {
+		require.Equal(t, tp, pcore.ParseType(pcore.TypeString(tp)))
+	}
+
+	// types that cannot round-trip are not written at all
+	for _, tp := range []dgo.Type{
+		tf.ParseType(`1...5`),
+		tf.Integer(0, 10, false),
+		tf.ParseType(`{a:int,b?:string="x"}`),
+		tf.ParseType(`{a:int,@deprecated b?:string}`),
+		tf.ParseType(`{a:int,b?:@description("the b") string}`),
+	} {
+		require.Panic(t, func() { pcore.TypeString(tp) }, `cannot be expressed in pcore syntax`)
+	}
+}
+
+func TestTypeString_aliases(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `tsNode={value:int,next:tsNode|nil}`)
+	})
+	tp := am.GetType(vf.String(`tsNode`))
+	require.Equal(t, `Array[TsNode]`, pcore.TypeStringWithAliasMap(tf.Array(tp), am))
+	require.Panic(t, func() { pcore.TypeStringWithAliasMap(tf.Array(tp), nil) }, `must be named in the alias map`)
+}
+
+func TestTypeString_fail(t *testing.T) {
+	for _, tp := range []dgo.Type{
+		typ.Error,
+		tf.ParseType(`!int`),
+		tf.ParseType(`int&string`),
+		tf.ParseType(`int^string`),
+		tf.ParseType(`0.0...1.0`),
+		tf.ParseType(`{a:int,...}`),
+		tf.ParseType(`func() (int,string)`),
+	} {
+		require.Panic(t, func() { pcore.TypeString(tp) }, `cannot be expressed in pcore syntax`)
+	}
+	require.Panic(t, func() { pcore.TypeString(tf.ParseType(`{1:int}`)) }, `key 1 cannot be expressed`)
+	require.Panic(t, func() { pcore.TypeString(tf.ParseType(`1...5`)) }, `exclusive range 1\.\.\.5 cannot be expressed`)
+	require.Panic(t, func() { pcore.TypeString(tf.ParseType(`{a?:int=1}`)) }, `default value of the struct map entry a cannot`)
+	require.Panic(t, func() { pcore.TypeString(tf.ParseType(`{@deprecated a:int}`)) }, `annotations of the struct map entry a cannot`)
+
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `{@deprecated 3}`)
+	})
+	require.Panic(t, func() { pcore.TypeStringWithAliasMap(tf.Array(tf.ParseType(`3`)), am) }, `annotations of the type 3 cannot`)
+}
+
+func TestTypeFormatter(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `fmtPcorePoint={x:int,y:int}`)
+	})
+	tp := tf.Array(am.GetType(vf.String(`fmtPcorePoint`)))
+	f := pcore.TypeFormatter()
+
+	s := strings.Builder{}
+	f.FormatType(&s, tp, am, 0)
+	require.Equal(t, `Array[FmtPcorePoint]`, s.String())
+
+	s.Reset()
+	f.FormatType(&s, tp, nil, 0)
+	require.Equal(t, `Array[Struct[{"x"=>Integer,"y"=>Integer}]]`, s.String())
+}
@@ -4,6 +4,7 @@ package streamer
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/lyraproj/dgo/dgo"
 	"github.com/lyraproj/dgo/vf"
@@ -186,15 +187,43 @@ func (sc *context) emitType(typ dgo.Type) {
 				if tn := am.GetName(typ); tn != nil {
 					sc.addData(d.AliasTypeName())
 					sc.addData(d.ValueKey())
-					sc.emitData(vf.Values(tn, typ.String()))
+					if an := am.Annotations(tn); an != nil {
+						sc.emitData(vf.Values(tn, sc.typeString(typ, &declaration{AliasMap: am, t: typ}), an))
+					} else {
+						sc.emitData(vf.Values(tn, sc.typeString(typ, &declaration{AliasMap: am, t: typ})))
+					}
+					return
 				}
-			} else {
-				sc.addData(vf.String(typ.String()))
 			}
+			sc.addData(vf.String(sc.typeString(typ, sc.config.aliasMap)))
 		})
 	})
 }
 
+// typeString returns the string form of the given type using the syntax of the dialect. Types that are named in
+// the given alias map are written using their names.
+func (sc *context) typeString(typ dgo.Type, am dgo.AliasMap) string {
+	s := strings.Builder{}
+	typeFormatter(sc.config.Dialect).FormatType(&s, typ, am, 0)
+	return s.String()
+}
+
+// declaration is an AliasMap that doesn't know the name of the declared type the first time it is asked for it, so
+// that a formatter writes the declared type rather than its name
+type declaration struct {
+	dgo.AliasMap
+	t     dgo.Type
+	named bool
+}
+
+func (d *declaration) GetName(t dgo.Type) dgo.String {
+	if !d.named && d.t.Equals(t) {
+		d.named = true
+		return nil
+	}
+	return d.AliasMap.GetName(t)
+}
+
 func (sc *context) addArray(len int, doer dgo.Doer) {
 	sc.refIndex++
 	sc.consumer.AddArray(len, doer)
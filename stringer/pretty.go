@@ -0,0 +1,119 @@
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+	"github.com/lyraproj/dgo/util"
+)
+
+// indentString is the string written once for each indent level when a type is broken into several lines
+const indentString = `  `
+
+// lineWriter keeps track of the column that the next write will start at
+type lineWriter struct {
+	io.Writer
+	col int
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if nl := bytes.LastIndexByte(p, '\n'); nl >= 0 {
+		w.col = utf8.RuneCount(p[nl+1:])
+	} else {
+		w.col += utf8.RuneCount(p)
+	}
+	return w.Writer.Write(p)
+}
+
+// PrettyTypeString produces a string with the go-like syntax for the given type. Struct maps, tuples, function
+// arguments and unions that don't fit within the given width are broken into several indented lines. Types that
+// have a name in the given alias map are written using that name. The built-in aliases are used when the alias
+// map is nil.
+//
+// The produced string can be parsed back into a type that is equal to the given type.
+func PrettyTypeString(typ dgo.Type, am dgo.AliasMap, width int) string {
+	s := strings.Builder{}
+	TypeFormatter().FormatType(&s, typ, am, width)
+	return s.String()
+}
+
+// PrettyDeclaration produces an alias declaration in the form <name>=<type> for the type that has the given name
+// in the given alias map. The annotations of the type are written in front of the declaration. The type is written
+// like PrettyTypeString writes it, except that the type itself is never replaced by its name. The level is the
+// indent level of the line that the declaration starts on.
+func PrettyDeclaration(name string, am dgo.AliasMap, width, level int) string {
+	t := am.GetType(internal.String(name))
+	if t == nil {
+		panic(fmt.Errorf(`no type is named %q in the alias map`, name))
+	}
+	s := strings.Builder{}
+	lw := &lineWriter{Writer: &s, col: level * len(indentString)}
+	sb := newTypeBuilder(lw, am)
+	sb.width = width
+	sb.lines = lw
+	sb.level = level
+	if a := am.Annotations(internal.String(name)); a != nil {
+		sb.writeAnnotations(a)
+	}
+	util.WriteString(sb, name)
+	util.WriteByte(sb, '=')
+	sb.buildUnnamedTypeString(t, 0)
+	return s.String()
+}
+
+// fits calls the given function so that it writes on one line and returns true when the result fits within the
+// width of the builder. The result is written to the builder when it fits. Nothing is written when it doesn't.
+func (sb *typeBuilder) fits(f func()) bool {
+	if sb.width <= 0 {
+		f()
+		return true
+	}
+	w, width := sb.Writer, sb.width
+	s := strings.Builder{}
+	sb.Writer, sb.width = &s, 0
+	f()
+	sb.Writer, sb.width = w, width
+	if sb.lines.col+utf8.RuneCountInString(s.String()) > width {
+		return false
+	}
+	util.WriteString(sb, s.String())
+	return true
+}
+
+// writeBlock writes n elements between the given delimiters. The content function must write the elements and
+// call the given sep function between each of them. The elements are written on one line when they fit, and on
+// one indented line each when they don't.
+func (sb *typeBuilder) writeBlock(left, right byte, n int, content func(sep func())) {
+	flat := func() {
+		util.WriteByte(sb, left)
+		content(func() { util.WriteByte(sb, ',') })
+		util.WriteByte(sb, right)
+	}
+	if n == 0 {
+		flat()
+		return
+	}
+	if sb.fits(flat) {
+		return
+	}
+	util.WriteByte(sb, left)
+	sb.level++
+	sb.newLine()
+	content(func() {
+		util.WriteByte(sb, ',')
+		sb.newLine()
+	})
+	sb.level--
+	sb.newLine()
+	util.WriteByte(sb, right)
+}
+
+func (sb *typeBuilder) newLine() {
+	util.WriteByte(sb, '\n')
+	util.WriteString(sb, strings.Repeat(indentString, sb.level))
+}
@@ -0,0 +1,100 @@
+package stringer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/stringer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+)
+
+func ExamplePrettyTypeString() {
+	tp := tf.ParseType(`{name:string[1],port?:1..65535=8080,mode:"read"|"write"|"append",tags:{string,...int}}`)
+	fmt.Println(stringer.PrettyTypeString(tp, nil, 30))
+	// Output:
+	// {
+	//   "name":string[1],
+	//   "port"?:1..65535=8080,
+	//   "mode":"read"|
+	//     "write"|
+	//     "append",
+	//   "tags":{string,...int}
+	// }
+}
+
+const prettySource = `{
+	@description("the name") name:string[1],
+	port?:1..65535=8080,
+	mode:"read"|"write"|"append"|"truncate"|"create",
+	tags:{string,...int},
+	both:[]("alpha"&"beta")|("gamma"^"delta"),
+	call:func(string,int,...bool) (int,string),
+	opts:{a:int,b:map[string]{x:float,y:float},...},
+	empty:{},
+	...}`
+
+func TestPrettyTypeString_roundTrip(t *testing.T) {
+	tp := tf.ParseType(prettySource)
+	for _, w := range []int{0, 200, 80, 40, 20, 1} {
+		s := stringer.PrettyTypeString(tp, nil, w)
+		require.Equal(t, tp, tf.ParseType(s))
+		if w > 0 {
+			require.True(t, strings.Contains(s, "\n"))
+		}
+	}
+	require.Equal(t, tp.String(), stringer.PrettyTypeString(tp, nil, 0))
+}
+
+func TestPrettyTypeString_width(t *testing.T) {
+	tp := tf.ParseType(prettySource)
+	for _, line := range strings.Split(stringer.PrettyTypeString(tp, nil, 60), "\n") {
+		require.True(t, len(line) <= 60)
+	}
+}
+
+func TestPrettyTypeString_fits(t *testing.T) {
+	tp := tf.Tuple(typ.String, typ.Integer)
+	require.Equal(t, `{string,int}`, stringer.PrettyTypeString(tp, nil, 12))
+	require.Equal(t, "{\n  string,\n  int\n}", stringer.PrettyTypeString(tp, nil, 11))
+	require.Equal(t, `{}`, stringer.PrettyTypeString(tf.Tuple(), nil, 1))
+	require.Equal(t, "[](\n  int|\n  string\n)",
+		stringer.PrettyTypeString(tf.Array(tf.AnyOf(typ.Integer, typ.String)), nil, 10))
+}
+
+func TestPrettyTypeString_aliases(t *testing.T) {
+	var tp dgo.Type
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `prettyPoint={x:float,y:float}`)
+		tp = tf.ParseFile(aa, ``, `{from:prettyPoint,to:prettyPoint,color:"red"|"green"|"blue"}`).(dgo.Type)
+	})
+	s := stringer.PrettyTypeString(tp, am, 30)
+	require.Equal(t, `{
+  "from":prettyPoint,
+  "to":prettyPoint,
+  "color":"red"|"green"|"blue"
+}`, s)
+	am.Collect(func(aa dgo.AliasAdder) {
+		require.Equal(t, tp, tf.ParseFile(aa, ``, s))
+	})
+}
+
+func TestPrettyDeclaration(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `{
+  declSlug=/^[a-z]+$/,
+  @description("a tree") declTree={name:declSlug,kids?:[]declTree}
+}`)
+	})
+	require.Equal(t, `declSlug=/^[a-z]+$/`, stringer.PrettyDeclaration(`declSlug`, am, 0, 0))
+	require.Equal(t, `@description("a tree") declTree={"name":declSlug,"kids"?:[]declTree}`,
+		stringer.PrettyDeclaration(`declTree`, am, 80, 1))
+	require.Equal(t, `@description("a tree") declTree={
+    "name":declSlug,
+    "kids"?:[]declTree
+  }`, stringer.PrettyDeclaration(`declTree`, am, 40, 1))
+	require.Panic(t, func() { stringer.PrettyDeclaration(`declNone`, am, 0, 0) }, `no type is named "declNone"`)
+}
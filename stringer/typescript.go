@@ -0,0 +1,319 @@
+package stringer
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+	"github.com/lyraproj/dgo/util"
+)
+
+type tsBuilder struct {
+	*typeBuilder
+	names    []string
+	included map[string]bool
+}
+
+var tsIdentifier = regexp.MustCompile(`\A[A-Za-z_$][A-Za-z0-9_$]*\z`)
+
+// TypeScript produces a TypeScript type expression for the given type. Types that have a name in the given alias
+// map, or among the built-in aliases when the map is nil, are referenced using that name. Constraints that cannot be
+// expressed in TypeScript, such as integer ranges or string patterns, are degraded to their closest TypeScript type
+// followed by a comment that contains the dgo type.
+func TypeScript(typ dgo.Type, am dgo.AliasMap) string {
+	s := strings.Builder{}
+	newTSBuilder(&s, am).buildTypeString(typ, 0)
+	return s.String()
+}
+
+// TypeScriptDeclarations produces a TypeScript declaration for each alias with the given names and for all aliases
+// that those aliases reference. Struct map types are declared as interfaces and all other types as type aliases, so
+// a union of exact strings becomes a union of string literal types.
+func TypeScriptDeclarations(am dgo.AliasMap, names ...string) string {
+	s := strings.Builder{}
+	sb := newTSBuilder(&s, am)
+	for _, n := range names {
+		sb.include(n)
+	}
+	for i := 0; i < len(sb.names); i++ {
+		if i > 0 {
+			util.WriteByte(sb, '\n')
+		}
+		sb.declaration(sb.names[i])
+	}
+	return s.String()
+}
+
+func newTSBuilder(w io.Writer, am dgo.AliasMap) *tsBuilder {
+	if am == nil {
+		am = internal.BuiltInAliases()
+	}
+	sb := &tsBuilder{typeBuilder: &typeBuilder{Writer: w, aliasMap: am}, included: make(map[string]bool)}
+	sb.aliasRef = func(name dgo.String) { sb.include(name.GoString()) }
+	sb.selfRef = func(dgo.Type) { util.WriteString(sb, `any`) }
+	sb.complexTypes = map[dgo.TypeIdentifier]typeToString{
+		dgo.TiAny:           sb.literal(`any`),
+		dgo.TiNil:           sb.literal(`null`),
+		dgo.TiBoolean:       sb.literal(`boolean`),
+		dgo.TiBooleanExact:  sb.exactValue,
+		dgo.TiInteger:       sb.literal(`number`),
+		dgo.TiIntegerExact:  sb.exactValue,
+		dgo.TiIntegerRange:  sb.degraded(`number`),
+		dgo.TiFloat:         sb.literal(`number`),
+		dgo.TiFloatExact:    sb.exactValue,
+		dgo.TiFloatRange:    sb.degraded(`number`),
+		dgo.TiString:        sb.literal(`string`),
+		dgo.TiDgoString:     sb.literal(`string`),
+		dgo.TiStringExact:   sb.stringExact,
+		dgo.TiStringSized:   sb.degraded(`string`),
+		dgo.TiStringPattern: sb.degraded(`string`),
+		dgo.TiCiString:      sb.degraded(`string`),
+		dgo.TiBinary:        sb.degraded(`string`),
+		dgo.TiBinaryExact:   sb.degraded(`string`),
+		dgo.TiTime:          sb.degraded(`string`),
+		dgo.TiTimeExact:     sb.degraded(`string`),
+		dgo.TiRegexp:        sb.degraded(`string`),
+		dgo.TiRegexpExact:   sb.degraded(`string`),
+		dgo.TiAnyOf:         sb.tsAnyOf,
+		dgo.TiOneOf:         sb.tsAnyOf,
+		dgo.TiAllOf:         sb.tsAllOf,
+		dgo.TiAllOfValue:    sb.tsAllOfValue,
+		dgo.TiArray:         sb.tsArray,
+		dgo.TiArrayExact:    sb.tsArrayExact,
+		dgo.TiTuple:         sb.tsTuple,
+		dgo.TiMap:           sb.tsMap,
+		dgo.TiMapExact:      sb.tsStruct,
+		dgo.TiStruct:        sb.tsStruct,
+		dgo.TiSensitive:     sb.tsSensitive,
+		dgo.TiNot:           sb.tsNot,
+		dgo.TiMeta:          sb.degraded(`any`),
+		dgo.TiNative:        sb.degraded(`any`),
+		dgo.TiFunction:      sb.degraded(`any`),
+		dgo.TiFunctionExact: sb.degraded(`any`),
+		dgo.TiError:         sb.degraded(`any`),
+		dgo.TiErrorExact:    sb.degraded(`any`),
+		dgo.TiNamed:         sb.degraded(`any`),
+		dgo.TiNamedExact:    sb.degraded(`any`),
+		dgo.TiMapEntryExact: sb.degraded(`any`),
+	}
+	return sb
+}
+
+func (sb *tsBuilder) include(name string) {
+	if !sb.included[name] {
+		sb.included[name] = true
+		sb.names = append(sb.names, name)
+	}
+}
+
+// declaration writes the declaration of the alias with the given name
+func (sb *tsBuilder) declaration(name string) {
+	t := sb.aliasMap.GetType(internal.String(name))
+	if t == nil {
+		panic(fmt.Errorf(`no type is named %q in the alias map`, name))
+	}
+	sb.writeDoc(sb.aliasMap.Annotations(internal.String(name)), nil, ``)
+	switch {
+	case t.TypeIdentifier() == dgo.TiStruct:
+		util.WriteString(sb, `export interface `)
+		util.WriteString(sb, name)
+		util.WriteString(sb, " {\n")
+		st := t.(dgo.StructMapType)
+		st.Each(func(e dgo.StructMapEntry) {
+			sb.writeDoc(e.Annotations(), e.Default(), `  `)
+			util.WriteString(sb, `  `)
+			sb.writeProperty(e)
+			util.WriteString(sb, ";\n")
+		})
+		if st.Additional() {
+			util.WriteString(sb, "  [key: string]: any;\n")
+		}
+		util.WriteString(sb, "}\n")
+	default:
+		util.WriteString(sb, `export type `)
+		util.WriteString(sb, name)
+		util.WriteString(sb, ` = `)
+		sb.buildUnnamedTypeString(t, 0)
+		util.WriteString(sb, ";\n")
+	}
+}
+
+// writeDoc writes a JSDoc comment with the description, deprecation, and default value of a declaration or
+// property. Nothing is written when there is nothing to document.
+func (sb *tsBuilder) writeDoc(annotations dgo.Map, dflt dgo.Value, indent string) {
+	var lines []string
+	if annotations != nil {
+		if d, ok := annotations.Get(`description`).(dgo.String); ok {
+			lines = append(lines, strings.Split(d.GoString(), "\n")...)
+		}
+		switch d := annotations.Get(`deprecated`).(type) {
+		case dgo.String:
+			lines = append(lines, `@deprecated `+d.GoString())
+		case dgo.Boolean:
+			if d.GoBool() {
+				lines = append(lines, `@deprecated`)
+			}
+		}
+	}
+	if dflt != nil {
+		dt, ok := dflt.(dgo.Type)
+		if !ok {
+			dt = dflt.Type()
+		}
+		lines = append(lines, `@default `+TypeStringWithAliasMap(dt, sb.aliasMap))
+	}
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		util.WriteString(sb, indent+`/** `+escapeComment(lines[0])+" */\n")
+	default:
+		util.WriteString(sb, indent+"/**\n")
+		for _, l := range lines {
+			util.WriteString(sb, indent+` * `+escapeComment(l)+"\n")
+		}
+		util.WriteString(sb, indent+" */\n")
+	}
+}
+
+func (sb *tsBuilder) writeProperty(e dgo.StructMapEntry) {
+	k := e.Key().(dgo.ExactType).ExactValue().String()
+	if tsIdentifier.MatchString(k) {
+		util.WriteString(sb, k)
+	} else {
+		util.WriteString(sb, strconv.Quote(k))
+	}
+	if !e.Required() {
+		util.WriteByte(sb, '?')
+	}
+	util.WriteString(sb, `: `)
+	sb.buildTypeString(e.Value().(dgo.Type), commaPrio)
+}
+
+func (sb *tsBuilder) literal(s string) typeToString {
+	return func(dgo.Type, int) {
+		util.WriteString(sb, s)
+	}
+}
+
+// degraded returns a function that writes the given TypeScript type followed by a comment that contains the
+// dgo type that it represents
+func (sb *tsBuilder) degraded(s string) typeToString {
+	return func(typ dgo.Type, _ int) {
+		util.WriteString(sb, s)
+		util.WriteString(sb, ` /* `)
+		c := strings.Builder{}
+		newTypeBuilder(&c, sb.aliasMap).buildUnnamedTypeString(typ, 0)
+		util.WriteString(sb, escapeComment(c.String()))
+		util.WriteString(sb, ` */`)
+	}
+}
+
+func (sb *tsBuilder) tsAnyOf(typ dgo.Type, prio int) {
+	if typ.(dgo.TernaryType).Operands().Len() == 0 {
+		util.WriteString(sb, `never`)
+		return
+	}
+	sb.writeTernary(typ, typeAsType, prio, ` | `, orPrio)
+}
+
+func (sb *tsBuilder) tsAllOf(typ dgo.Type, prio int) {
+	if typ.(dgo.TernaryType).Operands().Len() == 0 {
+		util.WriteString(sb, `any`)
+		return
+	}
+	sb.writeTernary(typ, typeAsType, prio, ` & `, andPrio)
+}
+
+func (sb *tsBuilder) tsAllOfValue(typ dgo.Type, prio int) {
+	sb.writeTernary(typ, valueAsType, prio, ` & `, andPrio)
+}
+
+func (sb *tsBuilder) tsArray(typ dgo.Type, _ int) {
+	sb.buildTypeString(typ.(dgo.ArrayType).ElementType(), typePrio)
+	util.WriteString(sb, `[]`)
+}
+
+func (sb *tsBuilder) tsArrayExact(typ dgo.Type, _ int) {
+	util.WriteByte(sb, '[')
+	sb.joinValueTypes(typ.(dgo.ExactType).ExactValue().(dgo.Iterable), `, `, commaPrio)
+	util.WriteByte(sb, ']')
+}
+
+func (sb *tsBuilder) tsTuple(typ dgo.Type, _ int) {
+	tt := typ.(dgo.TupleType)
+	es := tt.ElementTypes()
+	n := es.Len()
+	if tt.Variadic() {
+		n--
+	}
+	util.WriteByte(sb, '[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			util.WriteString(sb, `, `)
+		}
+		sb.buildTypeString(es.Get(i).(dgo.Type), commaPrio)
+	}
+	if tt.Variadic() {
+		if n > 0 {
+			util.WriteString(sb, `, `)
+		}
+		util.WriteString(sb, `...`)
+		sb.buildTypeString(es.Get(n).(dgo.Type), typePrio)
+		util.WriteString(sb, `[]`)
+	}
+	util.WriteByte(sb, ']')
+}
+
+func (sb *tsBuilder) tsMap(typ dgo.Type, _ int) {
+	mt := typ.(dgo.MapType)
+	switch mt.KeyType().TypeIdentifier() {
+	case dgo.TiAny, dgo.TiString, dgo.TiStringSized, dgo.TiStringPattern, dgo.TiCiString, dgo.TiDgoString:
+		util.WriteString(sb, `{ [key: string]: `)
+		sb.buildTypeString(mt.ValueType(), commaPrio)
+		util.WriteString(sb, ` }`)
+	default:
+		util.WriteString(sb, `Record<`)
+		sb.buildTypeString(mt.KeyType(), commaPrio)
+		util.WriteString(sb, `, `)
+		sb.buildTypeString(mt.ValueType(), commaPrio)
+		util.WriteByte(sb, '>')
+	}
+}
+
+func (sb *tsBuilder) tsStruct(typ dgo.Type, _ int) {
+	st := typ.(dgo.StructMapType)
+	if st.Len() == 0 && !st.Additional() {
+		util.WriteString(sb, `{}`)
+		return
+	}
+	util.WriteString(sb, `{ `)
+	st.Each(func(e dgo.StructMapEntry) {
+		sb.writeProperty(e)
+		util.WriteString(sb, `; `)
+	})
+	if st.Additional() {
+		util.WriteString(sb, `[key: string]: any; `)
+	}
+	util.WriteByte(sb, '}')
+}
+
+func (sb *tsBuilder) tsNot(typ dgo.Type, prio int) {
+	if typ.(dgo.UnaryType).Operand().TypeIdentifier() == dgo.TiAny {
+		util.WriteString(sb, `never`)
+		return
+	}
+	sb.degraded(`any`)(typ, prio)
+}
+
+func (sb *tsBuilder) tsSensitive(typ dgo.Type, prio int) {
+	sb.buildTypeString(typ.(dgo.UnaryType).Operand(), prio)
+}
+
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, `*/`, `*\/`)
+}
@@ -0,0 +1,113 @@
+package stringer_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/stringer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleTypeScriptDeclarations() {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `example.dgo`, `{
+  tsMode="read"|"read-write",
+  tsShare={
+    @description("the name of the share") name:string[1],
+    mode?:tsMode,
+    size:0..100
+  }
+}`)
+	})
+	fmt.Print(stringer.TypeScriptDeclarations(am, `tsShare`))
+	// Output:
+	// export interface tsShare {
+	//   /** the name of the share */
+	//   name: string /* string[1] */;
+	//   mode?: tsMode;
+	//   size: number /* 0..100 */;
+	// }
+	//
+	// export type tsMode = "read" | "read-write";
+}
+
+func TestTypeScript(t *testing.T) {
+	tests := []struct {
+		t dgo.Type
+		s string
+	}{
+		{tf.ParseType(`any`), `any`},
+		{tf.ParseType(`nil`), `null`},
+		{tf.ParseType(`bool`), `boolean`},
+		{tf.ParseType(`true`), `true`},
+		{tf.ParseType(`int`), `number`},
+		{tf.ParseType(`float`), `number`},
+		{tf.ParseType(`3`), `3`},
+		{tf.ParseType(`1.5`), `1.5`},
+		{tf.ParseType(`0..10`), `number /* 0..10 */`},
+		{tf.ParseType(`0.0...1.0`), `number /* 0.0...1.0 */`},
+		{tf.ParseType(`string`), `string`},
+		{tf.ParseType(`"a"`), `"a"`},
+		{tf.ParseType(`/a*/`), `string /* /a*\/ */`},
+		{tf.ParseType(`~"a"`), `string /* ~"a" */`},
+		{typ.Binary, `string /* binary */`},
+		{typ.Time, `string /* time */`},
+		{tf.ParseType(`"a"|"b"`), `"a" | "b"`},
+		{tf.ParseType(`int^string`), `number | string`},
+		{tf.ParseType(`{a:int}&{b:string}`), `{ a: number; } & { b: string; }`},
+		{tf.ParseType(`[]int`), `number[]`},
+		{tf.ParseType(`[]("a"|int)`), `("a" | number)[]`},
+		{tf.ParseType(`{string,int}`), `[string, number]`},
+		{tf.ParseType(`{string,...int}`), `[string, ...number[]]`},
+		{vf.Values(1, `x`).Type(), `[1, "x"]`},
+		{tf.ParseType(`map[string]int`), `{ [key: string]: number }`},
+		{tf.ParseType(`map["a"|"b"]int`), `Record<"a" | "b", number>`},
+		{tf.ParseType(`{a:int,"b-c"?:string|nil,...}`), `{ a: number; "b-c"?: string | null; [key: string]: any; }`},
+		{tf.ParseType(`{}`), `{}`},
+		{tf.ParseType(`sensitive[int]`), `number`},
+		{tf.AnyOf(), `never`},
+		{tf.ParseType(`!int`), `any /* !int */`},
+		{tf.ParseType(`type`), `any /* type */`},
+		{typ.Error, `any /* error */`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.s, stringer.TypeScript(tt.t, nil))
+	}
+}
+
+func TestTypeScriptDeclarations(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `{
+  tsPort=@description("a TCP port") 1..65535,
+  tsNode={
+    @description("the node value") @deprecated("use data") value:int,
+    port?:tsPort=8080,
+    children?:[]tsNode,
+    ...
+  }
+}`)
+	})
+	require.Equal(t, `export interface tsNode {
+  /**
+   * the node value
+   * @deprecated use data
+   */
+  value: number;
+  /** @default 8080 */
+  port?: tsPort;
+  children?: tsNode[];
+  [key: string]: any;
+}
+
+/** a TCP port */
+export type tsPort = number /* 1..65535 */;
+`, stringer.TypeScriptDeclarations(am, `tsNode`))
+
+	require.Equal(t, "export type tsPort = number /* 1..65535 */;\n", stringer.TypeScriptDeclarations(am, `tsPort`)[18:])
+	require.Equal(t, `tsNode[]`, stringer.TypeScript(tf.Array(am.GetType(vf.String(`tsNode`))), am))
+	require.Panic(t, func() { stringer.TypeScriptDeclarations(am, `tsMissing`) }, `no type is named "tsMissing"`)
+}
@@ -27,6 +27,18 @@ type typeBuilder struct {
 	complexTypes map[dgo.TypeIdentifier]typeToString
 	aliasMap     dgo.AliasMap
 	seen         []dgo.Value
+
+	// aliasRef, when set, is called each time a type is written using its alias name
+	aliasRef func(name dgo.String)
+
+	// selfRef, when set, writes an unnamed recursive reference to the given type
+	selfRef func(typ dgo.Type)
+
+	// width, when greater than zero, is the width that the builder breaks lines to stay within. The lines
+	// writer and the indent level are only used when it is set.
+	width int
+	lines *lineWriter
+	level int
 }
 
 // TypeString produces a string with the go-like syntax for the given type.
@@ -41,6 +53,29 @@ func TypeStringWithAliasMap(typ dgo.Type, am dgo.AliasMap) string {
 	return s.String()
 }
 
+type formatter int
+
+// TypeFormatter returns the dgo.TypeFormatter that writes types using dgo syntax. The built-in aliases are used
+// when the formatter is called with a nil alias map. See PrettyTypeString for how the width is used.
+func TypeFormatter() dgo.TypeFormatter {
+	return formatter(0)
+}
+
+func (formatter) FormatType(w io.Writer, typ dgo.Type, am dgo.AliasMap, width int) {
+	if am == nil {
+		am = internal.BuiltInAliases()
+	}
+	if width > 0 {
+		lw := &lineWriter{Writer: w}
+		sb := newTypeBuilder(lw, am)
+		sb.width = width
+		sb.lines = lw
+		sb.buildTypeString(typ, 0)
+	} else {
+		newTypeBuilder(w, am).buildTypeString(typ, 0)
+	}
+}
+
 func (sb *typeBuilder) anyOf(typ dgo.Type, prio int) {
 	sb.writeTernary(typ, typeAsType, prio, `|`, orPrio)
 }
@@ -112,22 +147,25 @@ func (sb *typeBuilder) _map(typ dgo.Type, _ int) {
 }
 
 func (sb *typeBuilder) mapExact(typ dgo.Type, _ int) {
-	util.WriteByte(sb, '{')
-	sb.joinValueTypes(typ.(dgo.ExactType).ExactValue().(dgo.Map), `,`, commaPrio)
-	util.WriteByte(sb, '}')
+	st := typ.(dgo.StructMapType)
+	sb.writeBlock('{', '}', st.Len(), func(sep func()) { sb.joinStructMapEntries(st, sep) })
 }
 
 func (sb *typeBuilder) _struct(typ dgo.Type, _ int) {
-	util.WriteByte(sb, '{')
 	st := typ.(dgo.StructMapType)
-	sb.joinStructMapEntries(st)
+	n := st.Len()
 	if st.Additional() {
-		if st.Len() > 0 {
-			util.WriteByte(sb, ',')
-		}
-		util.WriteString(sb, `...`)
+		n++
 	}
-	util.WriteByte(sb, '}')
+	sb.writeBlock('{', '}', n, func(sep func()) {
+		sb.joinStructMapEntries(st, sep)
+		if st.Additional() {
+			if st.Len() > 0 {
+				sep()
+			}
+			util.WriteString(sb, `...`)
+		}
+	})
 }
 
 func (sb *typeBuilder) mapEntryExact(typ dgo.Type, _ int) {
@@ -313,13 +351,16 @@ func (sb *typeBuilder) joinX(v dgo.Iterable, tc func(dgo.Value) dgo.Type, s stri
 	})
 }
 
-func (sb *typeBuilder) joinStructMapEntries(v dgo.StructMapType) {
+func (sb *typeBuilder) joinStructMapEntries(v dgo.StructMapType, sep func()) {
 	first := true
 	v.Each(func(e dgo.StructMapEntry) {
 		if first {
 			first = false
 		} else {
-			util.WriteByte(sb, ',')
+			sep()
+		}
+		if a := e.Annotations(); a != nil {
+			sb.writeAnnotations(a)
 		}
 		sb.buildTypeString(e.Key().(dgo.Type), commaPrio)
 		if !e.Required() {
@@ -327,6 +368,34 @@ func (sb *typeBuilder) joinStructMapEntries(v dgo.StructMapType) {
 		}
 		util.WriteByte(sb, ':')
 		sb.buildTypeString(e.Value().(dgo.Type), commaPrio)
+		if d := e.Default(); d != nil {
+			util.WriteByte(sb, '=')
+			if dt, ok := d.(dgo.Type); ok {
+				sb.buildTypeString(dt, commaPrio)
+			} else {
+				sb.buildTypeString(d.Type(), commaPrio)
+			}
+		}
+	})
+}
+
+// writeAnnotations writes each annotation using the syntax @<key>(<value>) followed by a space. Examples are written
+// as multiple arguments and a true value is written without arguments.
+func (sb *typeBuilder) writeAnnotations(annotations dgo.Map) {
+	annotations.EachEntry(func(e dgo.MapEntry) {
+		util.WriteByte(sb, '@')
+		util.WriteString(sb, e.Key().String())
+		v := e.Value()
+		if a, ok := v.(dgo.Array); ok && e.Key().Equals(`examples`) {
+			util.WriteByte(sb, '(')
+			sb.joinValueTypes(a, `,`, commaPrio)
+			util.WriteByte(sb, ')')
+		} else if !v.Equals(true) {
+			util.WriteByte(sb, '(')
+			sb.buildTypeString(valueAsType(v), commaPrio)
+			util.WriteByte(sb, ')')
+		}
+		util.WriteByte(sb, ' ')
 	})
 }
 
@@ -368,44 +437,83 @@ func (sb *typeBuilder) writeFloatRange(min, max float64, inclusive bool) {
 
 func (sb *typeBuilder) writeTupleArgs(tt dgo.TupleType, leftSep, rightSep byte) {
 	es := tt.ElementTypes()
-	if tt.Variadic() {
-		n := es.Len() - 1
-		sep := leftSep
+	n := es.Len()
+	sb.writeBlock(leftSep, rightSep, n, func(sep func()) {
 		for i := 0; i < n; i++ {
-			util.WriteByte(sb, sep)
-			sep = ','
+			if i > 0 {
+				sep()
+			}
+			if tt.Variadic() && i == n-1 {
+				util.WriteString(sb, `...`)
+			}
 			sb.buildTypeString(es.Get(i).(dgo.Type), commaPrio)
 		}
-		util.WriteByte(sb, sep)
-		util.WriteString(sb, `...`)
-		sb.buildTypeString(es.Get(n).(dgo.Type), commaPrio)
-		util.WriteByte(sb, rightSep)
-	} else {
-		util.WriteByte(sb, leftSep)
-		sb.joinTypes(es, `,`, commaPrio)
-		util.WriteByte(sb, rightSep)
-	}
+	})
 }
 
 func (sb *typeBuilder) writeTernary(typ dgo.Type, tc func(dgo.Value) dgo.Type, prio int, op string, opPrio int) {
-	if prio >= orPrio {
+	ops := typ.(dgo.TernaryType).Operands()
+	parens := prio >= orPrio
+	if sb.fits(func() {
+		if parens {
+			util.WriteByte(sb, '(')
+		}
+		sb.joinX(ops, tc, op, opPrio)
+		if parens {
+			util.WriteByte(sb, ')')
+		}
+	}) {
+		return
+	}
+
+	// Break after each operator and indent the operands
+	if parens {
 		util.WriteByte(sb, '(')
 	}
-	sb.joinX(typ.(dgo.TernaryType).Operands(), tc, op, opPrio)
-	if prio >= orPrio {
+	sb.level++
+	if parens {
+		sb.newLine()
+	}
+	first := true
+	ops.Each(func(v dgo.Value) {
+		if first {
+			first = false
+		} else {
+			util.WriteString(sb, op)
+			sb.newLine()
+		}
+		sb.buildTypeString(tc(v), opPrio)
+	})
+	sb.level--
+	if parens {
+		sb.newLine()
 		util.WriteByte(sb, ')')
 	}
 }
 
 func (sb *typeBuilder) buildTypeString(typ dgo.Type, prio int) {
 	if tn := sb.aliasMap.GetName(typ); tn != nil {
+		if sb.aliasRef != nil {
+			sb.aliasRef(tn)
+		}
 		util.WriteString(sb, tn.GoString())
 		return
 	}
+	if a := sb.aliasMap.TypeAnnotations(typ); a != nil {
+		sb.writeAnnotations(a)
+	}
+	sb.buildUnnamedTypeString(typ, prio)
+}
 
+// buildUnnamedTypeString writes the given type without considering its alias name, if any.
+func (sb *typeBuilder) buildUnnamedTypeString(typ dgo.Type, prio int) {
 	ti := typ.TypeIdentifier()
 	if f, ok := sb.complexTypes[ti]; ok {
 		if util.RecursionHit(sb.seen, typ) {
+			if sb.selfRef != nil {
+				sb.selfRef(typ)
+				return
+			}
 			util.WriteString(sb, `<recursive self reference to `)
 			util.WriteString(sb, ti.String())
 			util.WriteString(sb, ` type>`)
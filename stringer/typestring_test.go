@@ -2,10 +2,12 @@ package stringer_test
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/lyraproj/dgo/dgo"
 	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/stringer"
 	"github.com/lyraproj/dgo/tf"
 	"github.com/lyraproj/dgo/typ"
 	"github.com/lyraproj/dgo/vf"
@@ -94,3 +96,19 @@ func TestTypeIdentifier_String(t *testing.T) {
 
 	require.Panic(t, func() { _ = dgo.TypeIdentifier(0x1000).String() }, `unhandled TypeIdentifier 4096`)
 }
+
+func TestTypeFormatter(t *testing.T) {
+	am := tf.BuiltInAliases().Collect(func(aa dgo.AliasAdder) {
+		tf.ParseFile(aa, `test.dgo`, `fmtPoint={x:int,y:int}`)
+	})
+	tp := tf.Array(am.GetType(vf.String(`fmtPoint`)))
+	f := stringer.TypeFormatter()
+
+	s := strings.Builder{}
+	f.FormatType(&s, tp, am, 0)
+	require.Equal(t, `[]fmtPoint`, s.String())
+
+	s.Reset()
+	f.FormatType(&s, tp, nil, 0)
+	require.Equal(t, `[]{"x":int,"y":int}`, s.String())
+}
@@ -0,0 +1,122 @@
+package stringer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/util"
+)
+
+// ValueString produces a string with the canonical value literal syntax for the given value. The string can be
+// parsed back into a value that is equal to the given value using parser.ParseValue. See that function for a
+// description of the syntax.
+//
+// The float NaN is an exception. It is written as float["NaN"] and parsed back into NaN, but since NaN is never
+// equal to anything, not even to itself, the parsed value isn't equal to the given value. The same applies to values
+// that contain NaN.
+//
+// The function panics when the value cannot be expressed using the literal syntax, e.g. when it is a native value.
+func ValueString(v dgo.Value) string {
+	s := strings.Builder{}
+	writeValue(&s, v)
+	return s.String()
+}
+
+func writeValue(w io.Writer, v dgo.Value) {
+	if _, isType := v.(dgo.Type); !isType {
+		if nt, ok := v.Type().(dgo.NamedType); ok {
+			util.WriteString(w, nt.Name())
+			util.WriteByte(w, ' ')
+			writeValue(w, nt.ExtractInitArg(v))
+			return
+		}
+	}
+	switch v := v.(type) {
+	case dgo.Nil:
+		util.WriteString(w, `nil`)
+	case dgo.Boolean, dgo.Integer:
+		util.WriteString(w, v.String())
+	case dgo.Float:
+		writeFloat(w, v.GoFloat())
+	case dgo.String:
+		util.WriteString(w, strconv.Quote(v.GoString()))
+	case dgo.Binary:
+		util.WriteString(w, `binary `)
+		util.WriteString(w, strconv.Quote(v.Encode()))
+	case dgo.Time:
+		writeTyped(w, `time`, v.GoTime().Format(time.RFC3339Nano))
+	case dgo.Regexp:
+		writeTyped(w, `regexp`, v.GoRegexp().String())
+	case dgo.Sensitive:
+		util.WriteString(w, `sensitive `)
+		writeValue(w, v.Unwrap())
+	case dgo.Array:
+		writeArray(w, v)
+	case dgo.Map:
+		writeMap(w, v)
+	case dgo.Type:
+		util.WriteString(w, `type[`)
+		util.WriteString(w, TypeString(v))
+		util.WriteByte(w, ']')
+	case error:
+		writeTyped(w, `error`, v.Error())
+	default:
+		panic(fmt.Errorf(`a value of type %s cannot be expressed as a literal`, TypeString(v.Type())))
+	}
+}
+
+func writeFloat(w io.Writer, f float64) {
+	switch {
+	case math.IsInf(f, 1):
+		writeTyped(w, `float`, `+Inf`)
+	case math.IsInf(f, -1):
+		writeTyped(w, `float`, `-Inf`)
+	case math.IsNaN(f):
+		writeTyped(w, `float`, `NaN`)
+	default:
+		util.WriteString(w, util.Ftoa(f))
+	}
+}
+
+func writeArray(w io.Writer, a dgo.Array) {
+	util.WriteByte(w, '{')
+	a.EachWithIndex(func(e dgo.Value, i int) {
+		if i > 0 {
+			util.WriteByte(w, ',')
+		}
+		writeValue(w, e)
+	})
+	util.WriteByte(w, '}')
+}
+
+func writeMap(w io.Writer, m dgo.Map) {
+	util.WriteByte(w, '{')
+	if m.Len() == 0 {
+		util.WriteByte(w, ':')
+	}
+	first := true
+	m.EachEntry(func(e dgo.MapEntry) {
+		if first {
+			first = false
+		} else {
+			util.WriteByte(w, ',')
+		}
+		writeValue(w, e.Key())
+		util.WriteByte(w, ':')
+		writeValue(w, e.Value())
+	})
+	util.WriteByte(w, '}')
+}
+
+// writeTyped writes the given string quoted and enclosed in brackets after the given type name
+func writeTyped(w io.Writer, typeName, s string) {
+	util.WriteString(w, typeName)
+	util.WriteByte(w, '[')
+	util.WriteString(w, strconv.Quote(s))
+	util.WriteByte(w, ']')
+}
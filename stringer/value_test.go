@@ -0,0 +1,124 @@
+package stringer_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/parser"
+	"github.com/lyraproj/dgo/stringer"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+type valueNamed int
+
+func (a valueNamed) String() string {
+	return a.Type().(dgo.NamedType).ValueString(a)
+}
+
+func (a valueNamed) Type() dgo.Type {
+	return tf.ExactNamed(tf.Named(`valueNamed`), a)
+}
+
+func (a valueNamed) Equals(other interface{}) bool {
+	return a == other
+}
+
+func (a valueNamed) HashCode() int {
+	return int(a)
+}
+
+func ExampleValueString() {
+	v := vf.Map(
+		`name`, `x`,
+		`data`, vf.Binary([]byte{1, 2, 3}, false),
+		`at`, vf.Time(time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)),
+		1, vf.Values(1.5, nil, vf.Sensitive(`secret`)),
+		`kind`, typ.String)
+	fmt.Println(stringer.ValueString(v))
+	// Output: {"name":"x","data":binary "AQID","at":time["2020-01-02T03:04:05.000000006Z"],1:{1.5,nil,sensitive "secret"},"kind":type[string]}
+}
+
+func TestValueString(t *testing.T) {
+	tests := []struct {
+		v dgo.Value
+		s string
+	}{
+		{vf.Nil, `nil`},
+		{vf.True, `true`},
+		{vf.Integer(-3), `-3`},
+		{vf.Float(3), `3.0`},
+		{vf.Float(1e300), `1E+300`},
+		{vf.Float(math.Inf(1)), `float["+Inf"]`},
+		{vf.Float(math.Inf(-1)), `float["-Inf"]`},
+		{vf.String("a\"b\n\x00\u2028"), `"a\"b\n\x00\u2028"`},
+		{vf.Values(), `{}`},
+		{vf.Map(), `{:}`},
+		{vf.Values(vf.Values(), vf.Map()), `{{},{:}}`},
+		{vf.Map(vf.Values(1), 2), `{{1}:2}`},
+		{vf.Value(regexp.MustCompile(`a/"b`)), `regexp["a/\"b"]`},
+		{vf.Value(errors.New(`boom`)), `error["boom"]`},
+		{tf.Array(typ.Integer, 1, 3), `type[[1,3]int]`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.s, stringer.ValueString(tt.v))
+	}
+}
+
+func TestValueString_roundTrip(t *testing.T) {
+	defer tf.RemoveNamed(`valueNamed`)
+	tf.NewNamed(`valueNamed`,
+		func(arg dgo.Value) dgo.Value { return valueNamed(arg.(dgo.Integer).GoInt()) },
+		func(v dgo.Value) dgo.Value { return vf.Integer(int64(v.(valueNamed))) },
+		reflect.TypeOf(valueNamed(0)), nil, nil)
+
+	for _, v := range []dgo.Value{
+		vf.Nil,
+		vf.False,
+		vf.Integer(math.MinInt64),
+		vf.Float(0.1),
+		vf.Float(-1.5e-7),
+		vf.Float(math.Inf(1)),
+		vf.String("a\"b\\\n\r\t\a\x7f\xff ö \U0001F600"),
+		vf.Values(),
+		vf.Map(),
+		vf.Values(1, `a`, vf.Values(2.0, nil)),
+		vf.Map(`a`, 1, 2, `b`, vf.Values(1), vf.Map(true, false)),
+		vf.Binary([]byte{0, 1, 2, 254, 255}, false),
+		vf.Binary([]byte{}, false),
+		vf.Time(time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone(`x`, -3600))),
+		vf.Value(regexp.MustCompile(`^a/b\d+$`)),
+		vf.Sensitive(vf.Map(`password`, `secret`)),
+		vf.Value(errors.New("boom\n")),
+		typ.Any,
+		tf.ParseType(`{name:string[1],port?:1..65535,mode:"r"|"rw"}`),
+		valueNamed(42),
+		vf.Values(valueNamed(1), valueNamed(2)),
+	} {
+		require.Equal(t, v, parser.ParseValue(stringer.ValueString(v)))
+	}
+}
+
+func TestValueString_NaN(t *testing.T) {
+	v := vf.Float(math.NaN())
+	s := stringer.ValueString(v)
+	require.Equal(t, `float["NaN"]`, s)
+
+	// NaN is parsed back into NaN which isn't equal to itself
+	pv, ok := parser.ParseValue(s).(dgo.Float)
+	require.True(t, ok)
+	require.True(t, math.IsNaN(pv.GoFloat()))
+	require.False(t, v.Equals(pv))
+}
+
+func TestValueString_fail(t *testing.T) {
+	require.Panic(t, func() { stringer.ValueString(vf.Value(struct{ A int }{1})) }, `cannot be expressed as a literal`)
+}
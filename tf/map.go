@@ -15,13 +15,27 @@ func StructMapEntry(key interface{}, value interface{}, required bool) dgo.Struc
 	return internal.StructMapEntry(key, value, required)
 }
 
+// StructMapEntryWithDefault returns a new optional StructMapEntry with a default value that is used by
+// StructMapType.ApplyDefaults when the key is missing
+func StructMapEntryWithDefault(key interface{}, value interface{}, dflt interface{}) dgo.StructMapEntry {
+	return internal.StructMapEntryWithDefault(key, value, dflt)
+}
+
+// AnnotatedStructMapEntry returns a copy of the given StructMapEntry that has the given annotations. Annotations
+// are documentation and do not affect equality or assignability. Well known keys are "description" (string),
+// "examples" (array), and "deprecated" (bool or message string). Other keys are allowed and can hold any value.
+func AnnotatedStructMapEntry(entry dgo.StructMapEntry, annotations dgo.Map) dgo.StructMapEntry {
+	return internal.AnnotatedStructMapEntry(entry, annotations)
+}
+
 // StructMap returns a new StructMapType type built from the given MapEntryTypes. If
 // additional is true, the struct will allow additional unconstrained entries
 func StructMap(additional bool, entries ...dgo.StructMapEntry) dgo.StructMapType {
 	return internal.StructMapType(additional, entries)
 }
 
-// StructMapFromMap returns a new type built from a map[string](dgo|type|{type:dgo|type,required?:bool,...})
+// StructMapFromMap returns a new type built from a
+// map[string](dgo|type|{type:dgo|type,required?:bool,default?:any,annotations?:map[string]any,...})
 func StructMapFromMap(additional bool, entries dgo.Map) dgo.StructMapType {
 	return internal.StructMapTypeFromMap(additional, entries)
 }
@@ -35,6 +35,15 @@ func ParseFile(aliasMap dgo.AliasAdder, fileName, content string) dgo.Value {
 	return parser.ParseFile(aliasMap, fileName, content)
 }
 
+// ParseModule is like ParseFile but the content may also start with import declarations that are resolved using
+// the given importer. The names in an imported namespace are referenced using the qualifier of the import, e.g.
+//
+//	import "common/net"
+//	{host: string, port: net.port}
+func ParseModule(aliasMap dgo.AliasAdder, importer dgo.Importer, fileName, content string) dgo.Value {
+	return parser.ParseModule(aliasMap, importer, fileName, content)
+}
+
 // AddDefaultAliases adds the new aliases to the default alias map by passing an AliasAdder to the function
 // The function is safe from a concurrency perspective.
 func AddDefaultAliases(adderFunc func(aliasAdder dgo.AliasAdder)) {
@@ -50,6 +59,13 @@ func AddAliases(mapToReplace *dgo.AliasMap, lock sync.Locker, adder func(adder d
 	internal.AddAliases(mapToReplace, lock, adder)
 }
 
+// CollectDeclarations calls the Collect method of the given AliasMap with the given function and returns the result
+// together with the names of the aliases that the function added, in the order that they were added. Names that the
+// AliasMap already knows are not included. It is typically used to find the aliases that a parsed file declares.
+func CollectDeclarations(am dgo.AliasMap, adder func(adder dgo.AliasAdder)) (dgo.AliasMap, []string) {
+	return internal.CollectDeclarations(am, adder)
+}
+
 // BuiltInAliases returns the frozen built-in dgo.AliasMap
 func BuiltInAliases() dgo.AliasMap {
 	return internal.BuiltInAliases()
@@ -0,0 +1,59 @@
+package typ
+
+import (
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+)
+
+// Keys of the well known annotations. Annotations may also contain other keys with arbitrary values.
+const (
+	// DescriptionKey is the key of the "description" annotation. The value is a string.
+	DescriptionKey = `description`
+
+	// ExamplesKey is the key of the "examples" annotation. The value is an array.
+	ExamplesKey = `examples`
+
+	// DeprecatedKey is the key of the "deprecated" annotation. The value is true or a message string.
+	DeprecatedKey = `deprecated`
+)
+
+// Annotations returns the annotations that the default alias map holds for the alias with the given name, or nil
+// when the alias has no annotations. Annotations of struct map entries are available from the entry itself.
+func Annotations(name string) dgo.Map {
+	return internal.DefaultAliases().Annotations(internal.String(name))
+}
+
+// Description returns the description found in the given annotations or an empty string if no description exists
+func Description(annotations dgo.Map) string {
+	if s, ok := get(annotations, DescriptionKey).(dgo.String); ok {
+		return s.GoString()
+	}
+	return ``
+}
+
+// Examples returns the examples found in the given annotations or nil if no examples exist
+func Examples(annotations dgo.Map) dgo.Array {
+	if a, ok := get(annotations, ExamplesKey).(dgo.Array); ok {
+		return a
+	}
+	return nil
+}
+
+// Deprecated returns true if the given annotations declare deprecation together with the deprecation message, or an
+// empty string when no message was given.
+func Deprecated(annotations dgo.Map) (bool, string) {
+	switch d := get(annotations, DeprecatedKey).(type) {
+	case dgo.String:
+		return true, d.GoString()
+	case dgo.Boolean:
+		return d.GoBool(), ``
+	}
+	return false, ``
+}
+
+func get(annotations dgo.Map, key string) dgo.Value {
+	if annotations == nil {
+		return nil
+	}
+	return annotations.Get(key)
+}
@@ -0,0 +1,46 @@
+package typ_test
+
+import (
+	"fmt"
+	"testing"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleAnnotations() {
+	tf.ParseType(`@description("a registered TCP port") @examples(80,8080) tcpPortExample=1024..49151`)
+	a := typ.Annotations(`tcpPortExample`)
+	fmt.Println(typ.Description(a))
+	fmt.Println(typ.Examples(a))
+
+	// Output:
+	// a registered TCP port
+	// {80,8080}
+}
+
+func TestDescription(t *testing.T) {
+	require.Equal(t, `x`, typ.Description(vf.Map(`description`, `x`)))
+	require.Equal(t, ``, typ.Description(vf.Map()))
+	require.Equal(t, ``, typ.Description(nil))
+}
+
+func TestExamples(t *testing.T) {
+	require.Equal(t, vf.Values(1), typ.Examples(vf.Map(`examples`, vf.Values(1))))
+	require.Nil(t, typ.Examples(vf.Map()))
+}
+
+func TestDeprecated(t *testing.T) {
+	d, m := typ.Deprecated(vf.Map(`deprecated`, `use y`))
+	require.True(t, d)
+	require.Equal(t, `use y`, m)
+
+	d, m = typ.Deprecated(vf.Map(`deprecated`, true))
+	require.True(t, d)
+	require.Equal(t, ``, m)
+
+	d, _ = typ.Deprecated(nil)
+	require.False(t, d)
+}
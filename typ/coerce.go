@@ -0,0 +1,221 @@
+package typ
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+	"github.com/lyraproj/dgo/util"
+)
+
+// Coerce converts the given value into an instance of the given type. The value is returned unchanged when it
+// already is an instance. Otherwise, conversions are only made when they are lossless:
+//
+// Strings are converted to integers, floats, booleans ("true" or "false"), times (RFC3339), and binaries (base64).
+//
+// Integers, floats, and booleans are converted to strings, integers to floats, and floats without fraction to integers.
+//
+// A value that isn't an array is converted to a one element array when an array is expected.
+//
+// Arrays, maps, and struct maps are coerced element by element. The returned error contains the path to the
+// element that could not be coerced.
+func Coerce(t dgo.Type, v dgo.Value) (result dgo.Value, err error) {
+	err = util.Catch(func() { result = coerce(``, t, v) })
+	return
+}
+
+var identifierPattern = regexp.MustCompile(`\A[A-Za-z_]\w*\z`)
+
+func coerce(path string, t dgo.Type, v dgo.Value) dgo.Value {
+	if t.Instance(v) {
+		return v
+	}
+	var c dgo.Value
+	switch t.TypeIdentifier() {
+	case dgo.TiArray, dgo.TiTuple, dgo.TiArrayExact:
+		c = coerceArray(path, t, v)
+	case dgo.TiMap, dgo.TiStruct, dgo.TiMapExact:
+		c = coerceMap(path, t, v)
+	case dgo.TiAnyOf, dgo.TiOneOf, dgo.TiAllOf, dgo.TiAllOfValue:
+		c = coerceTernary(path, t, v)
+	case dgo.TiSensitive:
+		if s, ok := v.(dgo.Sensitive); ok {
+			v = s.Unwrap()
+		}
+		c = internal.Sensitive(coerce(path, t.(dgo.UnaryType).Operand(), v))
+	default:
+		c = coerceScalar(t, v)
+	}
+	if c == nil || !t.Instance(c) {
+		panic(coerceError(path, t, v))
+	}
+	return c
+}
+
+func coerceError(path string, t dgo.Type, v dgo.Value) error {
+	if path == `` {
+		return fmt.Errorf(`unable to coerce %s to %s`, internal.TypeString(v.Type()), t)
+	}
+	return fmt.Errorf(`unable to coerce %s to %s at %s`, internal.TypeString(v.Type()), t, path)
+}
+
+func indexPath(path string, i int) string {
+	return path + `[` + strconv.Itoa(i) + `]`
+}
+
+func keyPath(path string, key dgo.Value) string {
+	if s, ok := key.(dgo.String); ok && identifierPattern.MatchString(s.GoString()) {
+		if path == `` {
+			return s.GoString()
+		}
+		return path + `.` + s.GoString()
+	}
+	return path + `[` + internal.TypeString(key.Type()) + `]`
+}
+
+// coerceScalar returns the result of a lossless conversion of the given value to the kind of value represented
+// by the given type, or nil when no such conversion exists.
+func coerceScalar(t dgo.Type, v dgo.Value) dgo.Value {
+	switch t.TypeIdentifier() {
+	case dgo.TiInteger, dgo.TiIntegerRange, dgo.TiIntegerExact:
+		return toInteger(v)
+	case dgo.TiFloat, dgo.TiFloatRange, dgo.TiFloatExact:
+		return toFloat(v)
+	case dgo.TiBoolean, dgo.TiBooleanExact:
+		if s, ok := v.(dgo.String); ok {
+			switch s.GoString() {
+			case `true`:
+				return internal.True
+			case `false`:
+				return internal.False
+			}
+		}
+	case dgo.TiString, dgo.TiStringSized, dgo.TiStringExact, dgo.TiStringPattern, dgo.TiCiString, dgo.TiDgoString:
+		switch v.(type) {
+		case dgo.Integer, dgo.Float, dgo.Boolean:
+			return internal.String(v.String())
+		}
+	case dgo.TiTime, dgo.TiTimeExact:
+		if s, ok := v.(dgo.String); ok {
+			if ts, err := time.Parse(time.RFC3339Nano, s.GoString()); err == nil {
+				return internal.Time(ts)
+			}
+		}
+	case dgo.TiBinary, dgo.TiBinaryExact:
+		if s, ok := v.(dgo.String); ok {
+			if bs, err := base64.StdEncoding.Strict().DecodeString(s.GoString()); err == nil {
+				return internal.Binary(bs, true)
+			}
+		}
+	}
+	return nil
+}
+
+func toInteger(v dgo.Value) dgo.Value {
+	switch v := v.(type) {
+	case dgo.String:
+		if i, err := strconv.ParseInt(v.GoString(), 10, 64); err == nil {
+			return internal.Integer(i)
+		}
+	case dgo.Float:
+		f := v.GoFloat()
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return internal.Integer(int64(f))
+		}
+	}
+	return nil
+}
+
+func toFloat(v dgo.Value) dgo.Value {
+	switch v := v.(type) {
+	case dgo.String:
+		s := v.GoString()
+		// ParseFloat accepts special values like "Inf" and "NaN" which are not considered lossless input
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) && strings.IndexAny(s, `nN`) < 0 {
+			return internal.Float(f)
+		}
+	case dgo.Integer:
+		i := v.GoInt()
+		if f := float64(i); f >= math.MinInt64 && f < math.MaxInt64 && int64(f) == i {
+			return internal.Float(f)
+		}
+	}
+	return nil
+}
+
+func coerceArray(path string, t dgo.Type, v dgo.Value) dgo.Value {
+	a, ok := v.(dgo.Array)
+	if !ok {
+		a = internal.WrapSlice([]dgo.Value{v})
+	}
+	var elementType func(int) dgo.Type
+	if tt, ok := t.(dgo.TupleType); ok {
+		es := tt.ElementTypes()
+		n := es.Len()
+		if tt.Variadic() {
+			elementType = func(i int) dgo.Type {
+				if i >= n-1 {
+					return es.Get(n - 1).(dgo.Type)
+				}
+				return es.Get(i).(dgo.Type)
+			}
+		} else {
+			if a.Len() != n {
+				return nil
+			}
+			elementType = func(i int) dgo.Type { return es.Get(i).(dgo.Type) }
+		}
+	} else {
+		et := t.(dgo.ArrayType).ElementType()
+		elementType = func(int) dgo.Type { return et }
+	}
+	r := internal.ArrayWithCapacity(a.Len())
+	a.EachWithIndex(func(e dgo.Value, i int) {
+		r.Add(coerce(indexPath(path, i), elementType(i), e))
+	})
+	return r.FrozenCopy()
+}
+
+func coerceMap(path string, t dgo.Type, v dgo.Value) dgo.Value {
+	m, ok := v.(dgo.Map)
+	if !ok {
+		return nil
+	}
+	r := internal.MapWithCapacity(m.Len())
+	if st, ok := t.(dgo.StructMapType); ok {
+		m.EachEntry(func(e dgo.MapEntry) {
+			k, ev := e.Key(), e.Value()
+			if se := st.Get(k); se != nil {
+				ev = coerce(keyPath(path, k), se.Value().(dgo.Type), ev)
+			}
+			r.Put(k, ev)
+		})
+	} else {
+		mt := t.(dgo.MapType)
+		kt, vt := mt.KeyType(), mt.ValueType()
+		m.EachEntry(func(e dgo.MapEntry) {
+			k := e.Key()
+			r.Put(coerce(keyPath(path, k), kt, k), coerce(keyPath(path, k), vt, e.Value()))
+		})
+	}
+	return r.FrozenCopy()
+}
+
+// coerceTernary returns the first successful coercion to one of the operands of the given type that is an
+// instance of the type itself.
+func coerceTernary(path string, t dgo.Type, v dgo.Value) dgo.Value {
+	ops := t.(dgo.TernaryType).Operands()
+	for i, n := 0, ops.Len(); i < n; i++ {
+		var c dgo.Value
+		if util.Catch(func() { c = coerce(path, ops.Get(i).(dgo.Type), v) }) == nil && t.Instance(c) {
+			return c
+		}
+	}
+	return nil
+}
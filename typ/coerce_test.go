@@ -0,0 +1,106 @@
+package typ_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lyraproj/dgo/dgo"
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleCoerce() {
+	tp := tf.ParseType(`{port:1..65535,tls?:bool,hosts:[]string}`)
+	v, _ := typ.Coerce(tp, vf.Map(`port`, `8080`, `tls`, `true`, `hosts`, `example.com`))
+	fmt.Println(v)
+
+	_, err := typ.Coerce(tp, vf.Map(`port`, `80x`, `hosts`, vf.Strings()))
+	fmt.Println(err)
+
+	// Output:
+	// {"port":8080,"tls":true,"hosts":{"example.com"}}
+	// unable to coerce "80x" to 1..65535 at port
+}
+
+func TestCoerce_scalars(t *testing.T) {
+	tests := []struct {
+		t dgo.Type
+		v interface{}
+		r interface{}
+	}{
+		{typ.Integer, `-12`, -12},
+		{typ.Integer, 3.0, 3},
+		{typ.Float, `1.5`, 1.5},
+		{typ.Float, 3, 3.0},
+		{typ.Boolean, `false`, false},
+		{typ.String, 42, `42`},
+		{typ.String, true, `true`},
+		{tf.Enum(`1`, `2`), 2, `2`},
+		{typ.Time, `2019-10-06T07:15:00Z`, time.Date(2019, 10, 6, 7, 15, 0, 0, time.UTC)},
+		{typ.Binary, `AQID`, []byte{1, 2, 3}},
+		{tf.ParseType(`sensitive[int]`), `3`, vf.Sensitive(3)},
+		{tf.ParseType(`int|bool`), `true`, true},
+		{tf.ParseType(`1..10^5..15`), `12`, 12},
+		{typ.Any, `x`, `x`},
+	}
+	for _, tt := range tests {
+		v, err := typ.Coerce(tt.t, vf.Value(tt.v))
+		require.Ok(t, err)
+		require.Equal(t, tt.r, v)
+	}
+}
+
+func TestCoerce_scalarFailures(t *testing.T) {
+	tests := []struct {
+		t dgo.Type
+		v interface{}
+	}{
+		{typ.Integer, `1.5`},
+		{typ.Integer, 1.5},
+		{typ.Integer, ` 1`},
+		{tf.Integer(1, 10, true), `11`},
+		{typ.Float, `NaN`},
+		{typ.Float, `Inf`},
+		{typ.Boolean, `yes`},
+		{typ.Time, `2019-10-06`},
+		{typ.Binary, `%%`},
+		{typ.String, vf.Values()},
+		{tf.ParseType(`1..10^5..15`), `7`},
+	}
+	for _, tt := range tests {
+		_, err := typ.Coerce(tt.t, vf.Value(tt.v))
+		require.NotOk(t, `unable to coerce`, err)
+	}
+}
+
+func TestCoerce_collections(t *testing.T) {
+	v, err := typ.Coerce(tf.ParseType(`{int,bool,...float}`), vf.Strings(`1`, `true`, `2`, `3.5`))
+	require.Ok(t, err)
+	require.Equal(t, vf.Values(1, true, 2.0, 3.5), v)
+
+	v, err = typ.Coerce(tf.ParseType(`map[int]bool`), vf.Map(`1`, `true`, 2, `false`))
+	require.Ok(t, err)
+	require.Equal(t, vf.Map(1, true, 2, false), v)
+
+	v, err = typ.Coerce(tf.ParseType(`{a:int,...}`), vf.Map(`a`, `1`, `b`, `2`))
+	require.Ok(t, err)
+	require.Equal(t, vf.Map(`a`, 1, `b`, `2`), v)
+
+	_, err = typ.Coerce(tf.ParseType(`{int,bool}`), vf.Strings(`1`))
+	require.NotOk(t, `unable to coerce`, err)
+
+	_, err = typ.Coerce(tf.ParseType(`map[string]int`), vf.Strings(`1`))
+	require.NotOk(t, `unable to coerce`, err)
+}
+
+func TestCoerce_path(t *testing.T) {
+	tp := tf.ParseType(`{servers:[]{host:string,port:1..65535},"x-y"?:map[string][]int}`)
+	_, err := typ.Coerce(tp, vf.Map(`servers`, vf.Values(vf.Map(`host`, `a`, `port`, `1`), vf.Map(`host`, `b`, `port`, `0`))))
+	require.NotOk(t, `unable to coerce "0" to 1..65535 at servers\[1\]\.port`, err)
+
+	_, err = typ.Coerce(tp, vf.Map(`servers`, vf.Values(), `x-y`, vf.Map(`z`, vf.Strings(`1`, `a`))))
+	require.NotOk(t, `unable to coerce "a" to int at \["x-y"\]\.z\[1\]`, err)
+}
@@ -0,0 +1,279 @@
+package typ
+
+import (
+	"math"
+
+	"github.com/lyraproj/dgo/dgo"
+	"github.com/lyraproj/dgo/internal"
+)
+
+// InferOptions controls how aggressively Infer generalizes sample values into a type.
+type InferOptions struct {
+	// EnumLimit is the maximum number of distinct strings that will be inferred as an enum. Strings are
+	// only inferred as an enum when at least one of them occurs more than once. A value of zero disables
+	// enum inference so that all strings are inferred as string.
+	EnumLimit int
+
+	// IntegerRanges, when true, infers integers as a range spanning the smallest and the largest sample. When
+	// false, integers are inferred as int.
+	IntegerRanges bool
+
+	// FloatRanges, when true, infers floats as a range spanning the smallest and the largest sample. When
+	// false, floats are inferred as float.
+	FloatRanges bool
+
+	// ArraySizes, when true, constrains inferred array types to the smallest and the largest sample size.
+	ArraySizes bool
+
+	// StructMaps, when true, infers maps that only have string keys as struct map types. Keys that are
+	// missing in some of the samples become optional. When false, such maps are inferred as map types.
+	StructMaps bool
+
+	// AdditionalEntries, when true, allows instances of inferred struct map types to have additional entries.
+	AdditionalEntries bool
+}
+
+// DefaultInferOptions are the options used by Infer.
+var DefaultInferOptions = InferOptions{EnumLimit: 10, IntegerRanges: true, StructMaps: true}
+
+// sample kinds in the order that they appear in an inferred union
+const (
+	kindBool = iota
+	kindInt
+	kindFloat
+	kindString
+	kindArray
+	kindMap
+	kindOther
+	kindNil
+	kindCount
+)
+
+// Infer generalizes the given sample values into a type using the DefaultInferOptions. The returned type
+// will have all samples as instances.
+func Infer(values ...dgo.Value) dgo.Type {
+	return InferWithOptions(DefaultInferOptions, values...)
+}
+
+// InferWithOptions generalizes the given sample values into a type using the given options. The returned
+// type will have all samples as instances.
+//
+// Samples of different kinds result in a union, strings may become enums, integers and floats may become ranges,
+// the elements of all array samples are unified into one element type, and maps with string keys become struct
+// map types where keys that are missing in some samples are optional. Inferring from zero samples results in any.
+func InferWithOptions(options InferOptions, values ...dgo.Value) dgo.Type {
+	if len(values) == 0 {
+		return internal.DefaultAnyType
+	}
+	inf := &inferrer{options: &options}
+	return inf.infer(values)
+}
+
+type inferrer struct {
+	options *InferOptions
+}
+
+func (inf *inferrer) infer(values []dgo.Value) dgo.Type {
+	var kinds [kindCount][]dgo.Value
+	for _, v := range values {
+		k := sampleKind(v)
+		kinds[k] = append(kinds[k], v)
+	}
+	var types []interface{}
+	for k := range kinds {
+		if vs := kinds[k]; len(vs) > 0 {
+			types = append(types, inf.inferKind(k, vs)...)
+		}
+	}
+	return internal.AnyOfType(types)
+}
+
+func sampleKind(v dgo.Value) int {
+	switch v.(type) {
+	case dgo.Nil:
+		return kindNil
+	case dgo.Boolean:
+		return kindBool
+	case dgo.Integer:
+		return kindInt
+	case dgo.Float:
+		return kindFloat
+	case dgo.String:
+		return kindString
+	case dgo.Array:
+		return kindArray
+	case dgo.Map:
+		return kindMap
+	default:
+		return kindOther
+	}
+}
+
+func (inf *inferrer) inferKind(kind int, values []dgo.Value) []interface{} {
+	switch kind {
+	case kindNil:
+		return []interface{}{internal.DefaultNilType}
+	case kindBool:
+		return []interface{}{internal.DefaultBooleanType}
+	case kindInt:
+		return []interface{}{inf.inferInt(values)}
+	case kindFloat:
+		return []interface{}{inf.inferFloat(values)}
+	case kindString:
+		return []interface{}{inf.inferString(values)}
+	case kindArray:
+		return []interface{}{inf.inferArray(values)}
+	case kindMap:
+		return []interface{}{inf.inferMap(values)}
+	default:
+		return inferOther(values)
+	}
+}
+
+func (inf *inferrer) inferInt(values []dgo.Value) dgo.Type {
+	if !inf.options.IntegerRanges {
+		return internal.DefaultIntegerType
+	}
+	min := int64(math.MaxInt64)
+	max := int64(math.MinInt64)
+	for _, v := range values {
+		i := v.(dgo.Integer).GoInt()
+		if i < min {
+			min = i
+		}
+		if i > max {
+			max = i
+		}
+	}
+	return internal.IntegerType(min, max, true)
+}
+
+func (inf *inferrer) inferFloat(values []dgo.Value) dgo.Type {
+	if !inf.options.FloatRanges {
+		return internal.DefaultFloatType
+	}
+	min := math.MaxFloat64
+	max := -math.MaxFloat64
+	for _, v := range values {
+		f := v.(dgo.Float).GoFloat()
+		if f < min {
+			min = f
+		}
+		if f > max {
+			max = f
+		}
+	}
+	return internal.FloatType(min, max, true)
+}
+
+func (inf *inferrer) inferString(values []dgo.Value) dgo.Type {
+	limit := inf.options.EnumLimit
+	if limit <= 0 {
+		return internal.DefaultStringType
+	}
+	seen := make(map[string]bool, limit)
+	distinct := make([]string, 0, limit)
+	for _, v := range values {
+		s := v.(dgo.String).GoString()
+		if !seen[s] {
+			if len(distinct) == limit {
+				return internal.DefaultStringType
+			}
+			seen[s] = true
+			distinct = append(distinct, s)
+		}
+	}
+	if len(distinct) == len(values) {
+		// No repetition, so there's nothing that indicates that the strings are drawn from a fixed set
+		return internal.DefaultStringType
+	}
+	return internal.EnumType(distinct)
+}
+
+func (inf *inferrer) inferArray(values []dgo.Value) dgo.Type {
+	var elements []dgo.Value
+	min := math.MaxInt64
+	max := 0
+	for _, v := range values {
+		a := v.(dgo.Array)
+		l := a.Len()
+		if l < min {
+			min = l
+		}
+		if l > max {
+			max = l
+		}
+		elements = a.AppendToSlice(elements)
+	}
+	var et dgo.Type = internal.DefaultAnyType
+	if len(elements) > 0 {
+		et = inf.infer(elements)
+	}
+	if inf.options.ArraySizes {
+		return internal.ArrayType([]interface{}{et, min, max})
+	}
+	return internal.ArrayType([]interface{}{et})
+}
+
+func (inf *inferrer) inferMap(values []dgo.Value) dgo.Type {
+	if inf.options.StructMaps && allStringKeys(values) {
+		return inf.inferStruct(values)
+	}
+	var keys, vals []dgo.Value
+	for _, v := range values {
+		m := v.(dgo.Map)
+		keys = m.Keys().AppendToSlice(keys)
+		vals = m.Values().AppendToSlice(vals)
+	}
+	var kt, vt dgo.Type = internal.DefaultAnyType, internal.DefaultAnyType
+	if len(keys) > 0 {
+		kt = inf.infer(keys)
+		vt = inf.infer(vals)
+	}
+	return internal.MapType([]interface{}{kt, vt})
+}
+
+func allStringKeys(values []dgo.Value) bool {
+	for _, v := range values {
+		if !v.(dgo.Map).StringKeys() {
+			return false
+		}
+	}
+	return true
+}
+
+func (inf *inferrer) inferStruct(values []dgo.Value) dgo.Type {
+	var keys []string
+	samples := make(map[string][]dgo.Value)
+	for _, v := range values {
+		v.(dgo.Map).EachEntry(func(e dgo.MapEntry) {
+			k := e.Key().(dgo.String).GoString()
+			vs, ok := samples[k]
+			if !ok {
+				keys = append(keys, k)
+			}
+			samples[k] = append(vs, e.Value())
+		})
+	}
+	entries := make([]dgo.StructMapEntry, len(keys))
+	for i, k := range keys {
+		vs := samples[k]
+		entries[i] = internal.StructMapEntry(internal.String(k), inf.infer(vs), len(vs) == len(values))
+	}
+	return internal.StructMapType(inf.options.AdditionalEntries, entries)
+}
+
+func inferOther(values []dgo.Value) []interface{} {
+	var types []interface{}
+nextValue:
+	for _, v := range values {
+		t := internal.Generic(v.Type())
+		for _, et := range types {
+			if t.Equals(et) {
+				continue nextValue
+			}
+		}
+		types = append(types, t)
+	}
+	return types
+}
@@ -0,0 +1,85 @@
+package typ_test
+
+import (
+	"fmt"
+	"testing"
+
+	require "github.com/lyraproj/dgo/dgo_test"
+	"github.com/lyraproj/dgo/tf"
+	"github.com/lyraproj/dgo/typ"
+	"github.com/lyraproj/dgo/vf"
+)
+
+func ExampleInfer() {
+	fmt.Println(typ.Infer(
+		vf.Map(`host`, `a.example.com`, `port`, 8080, `mode`, `tcp`),
+		vf.Map(`host`, `b.example.com`, `port`, 443, `mode`, `udp`, `tls`, true),
+		vf.Map(`host`, `c.example.com`, `port`, 22, `mode`, `tcp`)))
+
+	// Output:
+	// {"host":string,"port":22..8080,"mode":"tcp"|"udp","tls"?:bool}
+}
+
+func ExampleInferWithOptions() {
+	opts := typ.InferOptions{ArraySizes: true, FloatRanges: true}
+	fmt.Println(typ.InferWithOptions(opts, vf.Values(1, 2.5), vf.Values(3, 4, 0.5, `x`)))
+
+	// Output:
+	// [2,4](int|0.5..2.5|string)
+}
+
+func TestInfer_none(t *testing.T) {
+	require.Equal(t, typ.Any, typ.Infer())
+}
+
+func TestInfer_union(t *testing.T) {
+	tp := typ.Infer(vf.Value(1), vf.Value(`a`), vf.Nil, vf.Value(3), vf.True)
+	require.Equal(t, tf.AnyOf(typ.Boolean, tf.Integer(1, 3, true), typ.String, typ.Nil), tp)
+}
+
+func TestInfer_enum(t *testing.T) {
+	require.Equal(t, tf.Enum(`a`, `b`), typ.Infer(vf.Value(`a`), vf.Value(`b`), vf.Value(`a`)))
+	require.Equal(t, typ.String, typ.Infer(vf.Value(`a`), vf.Value(`b`)))
+	require.Equal(t, typ.String, typ.InferWithOptions(typ.InferOptions{EnumLimit: 1}, vf.Value(`a`), vf.Value(`b`), vf.Value(`a`)))
+}
+
+func TestInfer_struct(t *testing.T) {
+	tp := typ.Infer(vf.Map(`a`, 1), vf.Map(`b`, `x`))
+	require.Equal(t, tf.StructMap(false,
+		tf.StructMapEntry(`a`, vf.Value(1).Type(), false),
+		tf.StructMapEntry(`b`, typ.String, false)), tp)
+	require.Instance(t, tp, vf.Map())
+	require.NotInstance(t, tp, vf.Map(`c`, 1))
+
+	tp = typ.InferWithOptions(typ.InferOptions{StructMaps: true, AdditionalEntries: true}, vf.Map(`a`, 1), vf.Map(`a`, 2))
+	require.Equal(t, tf.StructMap(true, tf.StructMapEntry(`a`, typ.Integer, true)), tp)
+}
+
+func TestInfer_map(t *testing.T) {
+	require.Equal(t, tf.Map(tf.Integer(1, 2, true), typ.String), typ.Infer(vf.Map(1, `a`), vf.Map(2, `b`)))
+	require.Equal(t, tf.Map(typ.String, typ.Integer), typ.InferWithOptions(typ.InferOptions{}, vf.Map(`a`, 1)))
+	require.Equal(t, typ.Map, typ.InferWithOptions(typ.InferOptions{}, vf.Map()))
+}
+
+func TestInfer_array(t *testing.T) {
+	require.Equal(t, typ.Array, typ.Infer(vf.Values()))
+	require.Equal(t, tf.Array(tf.Integer(1, 3, true)), typ.Infer(vf.Values(1, 2), vf.Values(3)))
+}
+
+func TestInfer_other(t *testing.T) {
+	require.Equal(t, typ.Binary, typ.Infer(vf.Binary([]byte{1}, true), vf.Binary([]byte{2}, true)))
+}
+
+func TestInfer_samplesAreInstances(t *testing.T) {
+	samples := []interface{}{
+		vf.Map(`a`, vf.Values(1, `x`), `b`, vf.Map(`c`, 3.2)),
+		vf.Map(`a`, vf.Values(), `b`, vf.Map(`c`, 1.1, `d`, nil)),
+		vf.Map(`a`, vf.Values(nil)),
+	}
+	for _, o := range []typ.InferOptions{{}, typ.DefaultInferOptions, {EnumLimit: 3, IntegerRanges: true, FloatRanges: true, ArraySizes: true}} {
+		tp := typ.InferWithOptions(o, vf.Values(samples...).GoSlice()...)
+		for _, s := range samples {
+			require.Instance(t, tp, s)
+		}
+	}
+}
@@ -23,6 +23,20 @@ func TestReader_Next(t *testing.T) {
 	require.Equal(t, 5, v.Pos())
 }
 
+func TestReader_LineAndColumn(t *testing.T) {
+	v := util.NewStringReader("ab\ncd")
+	require.Equal(t, 1, v.Line())
+	require.Equal(t, 1, v.Column())
+	v.Next()
+	v.Next()
+	require.Equal(t, 3, v.Column())
+	v.Next()
+	require.Equal(t, 2, v.Line())
+	require.Equal(t, 1, v.Column())
+	v.Next()
+	require.Equal(t, 2, v.Column())
+}
+
 func TestReader_Rewind(t *testing.T) {
 	v := util.NewStringReader(`röd`)
 	require.Equal(t, 'r', v.Next())